@@ -19,6 +19,7 @@ import (
 	"github.com/vhvplatform/go-shared/logger"
 	"github.com/vhvplatform/go-shared/mongodb"
 	"github.com/vhvplatform/go-user-service/docs"
+	"github.com/vhvplatform/go-user-service/internal/auth"
 	"github.com/vhvplatform/go-user-service/internal/grpc"
 	"github.com/vhvplatform/go-user-service/internal/handler"
 	"github.com/vhvplatform/go-user-service/internal/middleware"
@@ -82,26 +83,90 @@ func main() {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(mongoClient.Database())
+	tokenRepo := repository.NewTokenRepository(mongoClient.Database())
+	roleRepo := repository.NewRoleRepository(mongoClient.Database())
+	prefRepo := repository.NewPreferencesRepository(mongoClient.Database())
+	schemaRepo := repository.NewSettingsSchemaRepository(mongoClient.Database())
+	segmentRepo := repository.NewSegmentRepository(mongoClient.Database())
+	policyRepo := repository.NewPolicyRepository(mongoClient.Database())
+	auditRepo := repository.NewAuditRepository(mongoClient.Database())
+
+	// Derive search fields for memberships stored before they existed
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		updated, err := userRepo.BackfillSearchFields(ctx)
+		if err != nil {
+			log.Error("Failed to backfill member search fields", zap.Error(err))
+			return
+		}
+		if updated > 0 {
+			log.Info("Backfilled member search fields", zap.Int64("memberships", updated))
+		}
+	}()
+
+	// Give memberships stored before lifecycle statuses existed their status
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		updated, err := userRepo.BackfillMembershipStatus(ctx, time.Now().UTC())
+		if err != nil {
+			log.Error("Failed to backfill membership status", zap.Error(err))
+			return
+		}
+		if updated > 0 {
+			log.Info("Backfilled membership status", zap.Int64("memberships", updated))
+		}
+	}()
 
 	// Initialize services
-	userService := service.NewUserService(userRepo, log)
+	userService := service.NewUserService(userRepo, roleRepo, log)
+	tokenService := service.NewTokenService(tokenRepo, userRepo, log)
+	roleService := service.NewRoleService(roleRepo, userRepo, log)
+	prefService := service.NewPreferencesService(prefRepo, schemaRepo, userRepo, roleRepo, log)
+	segmentService := service.NewSegmentService(segmentRepo, userRepo, roleRepo, log)
+	events := service.NewLogEventPublisher(log)
+	inactivity := service.NewInactivityJob(userRepo, policyRepo, events, service.InactivityConfigFromEnv(), log)
+	policyService := service.NewPolicyService(policyRepo, userRepo, roleRepo, inactivity, log)
+	exportService := service.NewExportService(userRepo, prefRepo, auditRepo, log)
+
+	// Purge memberships removed for longer than their tenant's retention window
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	purger := service.NewMembershipPurger(userRepo, prefRepo, policyRepo, auditRepo, service.PurgerConfigFromEnv(), log)
+	go purger.Run(jobsCtx)
+
+	// Deactivate memberships past their expires_at
+	expiry := service.NewExpiryScheduler(userRepo, events, service.ExpiryConfigFromEnv(), log)
+	go expiry.Run(jobsCtx)
+
+	// Warn and suspend members inactive for longer than their tenant allows
+	go inactivity.Run(jobsCtx)
+
+	// Initialize authentication (JWT_SECRET, JWT_JWKS_URL, opaque tokens)
+	authCfg := auth.ConfigFromEnv()
+	authCfg.OpaqueLookup = tokenService
+	authenticator, err := auth.NewAuthenticator(authCfg)
+	if err != nil {
+		log.Fatal("Failed to initialize authentication", zap.Error(err))
+	}
 
 	// Start gRPC server
 	grpcPort := os.Getenv("USER_SERVICE_PORT")
 	if grpcPort == "" {
 		grpcPort = "50052"
 	}
-	go startGRPCServer(userService, log, grpcPort)
+	go startGRPCServer(userService, tokenService, roleService, prefService, segmentService, policyService, exportService, authenticator, log, grpcPort)
 
 	// Start HTTP server
 	httpPort := os.Getenv("USER_SERVICE_HTTP_PORT")
 	if httpPort == "" {
 		httpPort = "8082"
 	}
-	startHTTPServer(userService, log, httpPort)
+	startHTTPServer(userService, roleService, prefService, segmentService, policyService, exportService, authenticator, log, httpPort)
 }
 
-func startGRPCServer(userService *service.UserService, log *logger.Logger, port string) {
+func startGRPCServer(userService *service.UserService, tokenService *service.TokenService, roleService *service.RoleService, prefService *service.PreferencesService, segmentService *service.SegmentService, policyService *service.PolicyService, exportService *service.ExportService, authenticator *auth.Authenticator, log *logger.Logger, port string) {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
 	if err != nil {
 		log.Fatal("Failed to listen", zap.Error(err))
@@ -162,8 +227,14 @@ func startGRPCServer(userService *service.UserService, log *logger.Logger, port
 		log.Info("gRPC Server TLS enabled")
 	}
 
+	// Every RPC requires a bearer token except VerifyToken, which other services
+	// call to validate tokens on behalf of their own callers
+	opts = append(opts, grpcServer.UnaryInterceptor(
+		grpc.AuthUnaryInterceptor(authenticator, pb.UserService_VerifyToken_FullMethodName),
+	))
+
 	grpcSrv := grpcServer.NewServer(opts...)
-	userGrpcServer := grpc.NewUserServiceServer(userService, log)
+	userGrpcServer := grpc.NewUserServiceServer(userService, tokenService, roleService, prefService, segmentService, policyService, exportService, log)
 	pb.RegisterUserServiceServer(grpcSrv, userGrpcServer)
 
 	// Register health check service
@@ -177,7 +248,7 @@ func startGRPCServer(userService *service.UserService, log *logger.Logger, port
 	}
 }
 
-func startHTTPServer(userService *service.UserService, log *logger.Logger, port string) {
+func startHTTPServer(userService *service.UserService, roleService *service.RoleService, prefService *service.PreferencesService, segmentService *service.SegmentService, policyService *service.PolicyService, exportService *service.ExportService, authenticator *auth.Authenticator, log *logger.Logger, port string) {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 	router.Use(gin.Recovery())
@@ -193,6 +264,11 @@ func startHTTPServer(userService *service.UserService, log *logger.Logger, port
 
 	// Initialize handlers
 	userHandler := handler.NewUserHandler(userService, log)
+	roleHandler := handler.NewRoleHandler(roleService, log)
+	prefHandler := handler.NewPreferencesHandler(prefService, log)
+	segmentHandler := handler.NewSegmentHandler(segmentService, log)
+	policyHandler := handler.NewPolicyHandler(policyService, log)
+	exportHandler := handler.NewExportHandler(exportService, log)
 
 	// Swagger endpoint
 	router.GET("/api/v1/users/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -211,14 +287,86 @@ func startHTTPServer(userService *service.UserService, log *logger.Logger, port
 	{
 		// Tenant-aware user routes
 		users := v1.Group("/users")
-		users.Use(middleware.TenancyMiddleware())
+		users.Use(middleware.TenancyMiddleware(), middleware.AuthMiddleware(authenticator))
 		{
 			users.POST("", userHandler.CreateUser)
 			users.GET("", userHandler.ListUsers)
 			users.GET("/search", userHandler.SearchUsers)
+			users.GET("/autocomplete", userHandler.AutocompleteUsers)
+			users.GET("/expiring", userHandler.ListExpiringUsers)
+
+			// Self-service endpoints for the authenticated user
+			users.GET("/me", userHandler.GetMe)
+			users.PATCH("/me", userHandler.UpdateMe)
+			users.GET("/me/tenants", userHandler.GetMyTenants)
+			users.GET("/me/preferences", prefHandler.GetPreferences)
+			users.PUT("/me/preferences", prefHandler.ReplacePreferences)
+			users.PATCH("/me/preferences", prefHandler.PatchPreferences)
+			users.GET("/me/preferences/effective", prefHandler.GetEffectivePreferences)
+			users.GET("/me/export", exportHandler.ExportMe)
+
+			// Tenant role catalog
+			users.GET("/roles", roleHandler.ListRoles)
+			users.POST("/roles", roleHandler.CreateRole)
+			users.GET("/roles/:key", roleHandler.GetRole)
+			users.PUT("/roles/:key", roleHandler.UpdateRole)
+			users.DELETE("/roles/:key", roleHandler.DeleteRole)
+
+			// Tenant membership tags
+			users.GET("/tags", userHandler.ListTags)
+			users.POST("/tags/bulk", userHandler.BulkTagUsers)
+
+			// Saved member segments
+			users.GET("/segments", segmentHandler.ListSegments)
+			users.POST("/segments", segmentHandler.CreateSegment)
+			users.GET("/segments/:key", segmentHandler.GetSegment)
+			users.PUT("/segments/:key", segmentHandler.UpdateSegment)
+			users.DELETE("/segments/:key", segmentHandler.DeleteSegment)
+			users.GET("/segments/:key/members", segmentHandler.EvaluateSegment)
+			users.GET("/segments/:key/members/:user_id", segmentHandler.CheckSegmentMembership)
+			users.GET("/segments/:key/count", segmentHandler.CountSegment)
+
+			// Tenant preference defaults and locks
+			users.GET("/tenant-preferences", prefHandler.GetTenantDefaults)
+			users.PUT("/tenant-preferences", prefHandler.ReplaceTenantDefaults)
+
+			// JSON Schemas for preference settings namespaces
+			users.GET("/settings-schemas", prefHandler.ListSettingsSchemas)
+			users.GET("/settings-schemas/:namespace", prefHandler.GetSettingsSchema)
+			users.PUT("/settings-schemas/:namespace", prefHandler.PutSettingsSchema)
+			users.DELETE("/settings-schemas/:namespace", prefHandler.DeleteSettingsSchema)
+
+			// Tenant membership policy (retention of removed members, inactivity)
+			users.GET("/membership-policy", policyHandler.GetMembershipPolicy)
+			users.PUT("/membership-policy", policyHandler.SetMembershipPolicy)
+			users.POST("/membership-policy/inactivity/apply", policyHandler.ApplyInactivityPolicy)
+
 			users.GET("/:id", userHandler.GetUser)
 			users.PUT("/:id", userHandler.UpdateUser)
 			users.DELETE("/:id", userHandler.DeleteUser)
+
+			// Membership lifecycle in the current tenant
+			users.PUT("/:id/status", userHandler.ChangeMembershipStatus)
+			users.POST("/:id/suspend", userHandler.SuspendUser)
+			users.POST("/:id/reactivate", userHandler.ReactivateUser)
+			users.POST("/:id/restore", userHandler.RestoreUser)
+			users.POST("/:id/touch", userHandler.TouchMembership)
+
+			// Tenant memberships of a global user
+			users.GET("/:id/tenants", userHandler.GetUserTenants)
+			users.POST("/:id/tenants", userHandler.AddUserToTenant)
+			users.PUT("/:id/tenants/:tenant_id", userHandler.UpdateUserTenant)
+			users.DELETE("/:id/tenants/:tenant_id", userHandler.RemoveUserFromTenant)
+
+			// Tags on the user's membership in the current tenant
+			users.POST("/:id/tags", userHandler.AddUserTags)
+			users.DELETE("/:id/tags/:tag", userHandler.RemoveUserTag)
+
+			// Per-tenant preferences of a user
+			users.GET("/:id/preferences", prefHandler.GetPreferences)
+			users.PUT("/:id/preferences", prefHandler.ReplacePreferences)
+			users.PATCH("/:id/preferences", prefHandler.PatchPreferences)
+			users.GET("/:id/preferences/effective", prefHandler.GetEffectivePreferences)
 		}
 	}
 
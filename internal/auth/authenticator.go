@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+var (
+	// ErrMissingToken is returned when no bearer token was supplied
+	ErrMissingToken = errors.New("missing bearer token")
+
+	// ErrInvalidToken is returned when a token fails verification
+	ErrInvalidToken = errors.New("invalid or expired token")
+
+	// ErrTenantNotAllowed is returned when a valid token does not grant the requested tenant
+	ErrTenantNotAllowed = errors.New("token is not valid for this tenant")
+)
+
+// OpaqueVerifier verifies opaque tokens issued by the token store
+// service.TokenService implements it
+type OpaqueVerifier interface {
+	VerifyToken(ctx context.Context, raw, tenantID string) (*domain.TokenVerification, error)
+}
+
+// Config configures which token types the Authenticator accepts
+type Config struct {
+	JWTSecret    string        // Enables HS256 tokens
+	JWKSURL      string        // Enables RS256 tokens verified against a JWKS endpoint
+	Issuer       string        // Optional expected "iss" claim
+	Audience     string        // Optional expected "aud" claim
+	AllowOpaque  bool          // Accept opaque tokens via the OpaqueVerifier
+	JWKSRefresh  time.Duration // How often JWKS keys are refreshed
+	ClockLeeway  time.Duration // Allowed clock skew for exp/nbf/iat
+	HTTPTimeout  time.Duration // Timeout for JWKS requests
+	OpaqueLookup OpaqueVerifier
+}
+
+// ConfigFromEnv builds a Config from JWT_SECRET, JWT_JWKS_URL, JWT_ISSUER,
+// JWT_AUDIENCE and AUTH_OPAQUE_TOKENS_ENABLED (default true)
+func ConfigFromEnv() Config {
+	allowOpaque := true
+	if v := os.Getenv("AUTH_OPAQUE_TOKENS_ENABLED"); v != "" {
+		allowOpaque, _ = strconv.ParseBool(v)
+	}
+
+	return Config{
+		JWTSecret:   os.Getenv("JWT_SECRET"),
+		JWKSURL:     os.Getenv("JWT_JWKS_URL"),
+		Issuer:      os.Getenv("JWT_ISSUER"),
+		Audience:    os.Getenv("JWT_AUDIENCE"),
+		AllowOpaque: allowOpaque,
+	}
+}
+
+// Authenticator validates bearer tokens and resolves the caller identity
+type Authenticator struct {
+	cfg    Config
+	jwks   *jwksCache
+	parser *jwt.Parser
+}
+
+// NewAuthenticator creates a new authenticator
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	if cfg.JWTSecret == "" && cfg.JWKSURL == "" && !(cfg.AllowOpaque && cfg.OpaqueLookup != nil) {
+		return nil, fmt.Errorf("no token verification configured: set JWT_SECRET, JWT_JWKS_URL or enable opaque tokens")
+	}
+	if cfg.JWKSRefresh <= 0 {
+		cfg.JWKSRefresh = 15 * time.Minute
+	}
+	if cfg.ClockLeeway <= 0 {
+		cfg.ClockLeeway = 30 * time.Second
+	}
+	if cfg.HTTPTimeout <= 0 {
+		cfg.HTTPTimeout = 5 * time.Second
+	}
+
+	var methods []string
+	if cfg.JWTSecret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.JWKSURL != "" {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.ClockLeeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	a := &Authenticator{
+		cfg:    cfg,
+		parser: jwt.NewParser(opts...),
+	}
+	if cfg.JWKSURL != "" {
+		a.jwks = newJWKSCache(cfg.JWKSURL, cfg.JWKSRefresh, cfg.HTTPTimeout)
+	}
+	return a, nil
+}
+
+// Authenticate verifies a raw bearer token. When tenantID is set the token
+// must grant access to that tenant, otherwise ErrTenantNotAllowed is returned.
+func (a *Authenticator) Authenticate(ctx context.Context, rawToken, tenantID string) (*Identity, error) {
+	if rawToken == "" {
+		return nil, ErrMissingToken
+	}
+
+	var identity *Identity
+	var err error
+	if looksLikeJWT(rawToken) {
+		identity, err = a.authenticateJWT(ctx, rawToken)
+	} else {
+		identity, err = a.authenticateOpaque(ctx, rawToken, tenantID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tenantID != "" && !identity.HasTenant(tenantID) {
+		return nil, ErrTenantNotAllowed
+	}
+	return identity, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" value
+func BearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+// tokenClaims are the JWT claims understood by the service
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	TenantID  string   `json:"tenant_id,omitempty"`
+	TenantIDs []string `json:"tenant_ids,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+}
+
+func (a *Authenticator) authenticateJWT(ctx context.Context, rawToken string) (*Identity, error) {
+	claims := &tokenClaims{}
+	_, err := a.parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.Alg() {
+		case jwt.SigningMethodHS256.Alg():
+			return []byte(a.cfg.JWTSecret), nil
+		case jwt.SigningMethodRS256.Alg():
+			kid, _ := token.Header["kid"].(string)
+			return a.jwks.key(ctx, kid)
+		}
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	})
+	if err != nil || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	identity := &Identity{
+		UserID:    claims.Subject,
+		TenantIDs: claims.TenantIDs,
+		Roles:     claims.Roles,
+		TokenType: TokenTypeJWT,
+	}
+	if claims.TenantID != "" {
+		identity.TenantIDs = append(identity.TenantIDs, claims.TenantID)
+	}
+	if claims.Role != "" {
+		identity.Roles = append(identity.Roles, claims.Role)
+	}
+	return identity, nil
+}
+
+func (a *Authenticator) authenticateOpaque(ctx context.Context, rawToken, tenantID string) (*Identity, error) {
+	if !a.cfg.AllowOpaque || a.cfg.OpaqueLookup == nil {
+		return nil, ErrInvalidToken
+	}
+
+	result, err := a.cfg.OpaqueLookup.VerifyToken(ctx, rawToken, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid {
+		// The store reports both unknown tokens and foreign tenants as invalid
+		return nil, ErrInvalidToken
+	}
+
+	identity := &Identity{
+		UserID:    result.UserID,
+		TenantIDs: result.TenantIDs,
+		TokenType: TokenTypeOpaque,
+		Claims:    result.Claims,
+	}
+	if roles := result.Claims["roles"]; roles != "" {
+		identity.Roles = strings.Split(roles, ",")
+	} else if result.Role != "" {
+		identity.Roles = []string{result.Role}
+	}
+	return identity, nil
+}
+
+// looksLikeJWT reports whether a token has the three dot-separated JWS segments
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
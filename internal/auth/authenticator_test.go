@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+const testSecret = "test-secret"
+
+func signHS256(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+type fakeOpaqueVerifier map[string]*domain.TokenVerification
+
+func (f fakeOpaqueVerifier) VerifyToken(ctx context.Context, raw, tenantID string) (*domain.TokenVerification, error) {
+	if result, ok := f[raw]; ok {
+		return result, nil
+	}
+	return &domain.TokenVerification{Valid: false}, nil
+}
+
+func TestAuthenticateHS256(t *testing.T) {
+	authn, err := NewAuthenticator(Config{JWTSecret: testSecret})
+	require.NoError(t, err)
+	ctx := context.Background()
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name     string
+		token    string
+		tenantID string
+		wantErr  error
+	}{
+		{
+			name:     "valid token for tenant",
+			token:    signHS256(t, jwt.MapClaims{"sub": "user-1", "exp": exp, "tenant_ids": []string{"tenant-a"}, "roles": []string{"admin"}}),
+			tenantID: "tenant-a",
+		},
+		{
+			name:     "single tenant_id claim",
+			token:    signHS256(t, jwt.MapClaims{"sub": "user-1", "exp": exp, "tenant_id": "tenant-a"}),
+			tenantID: "tenant-a",
+		},
+		{
+			name:     "wildcard tenant",
+			token:    signHS256(t, jwt.MapClaims{"sub": "svc", "exp": exp, "tenant_ids": []string{AllTenants}}),
+			tenantID: "tenant-z",
+		},
+		{
+			name:     "tenant not in claims",
+			token:    signHS256(t, jwt.MapClaims{"sub": "user-1", "exp": exp, "tenant_ids": []string{"tenant-a"}}),
+			tenantID: "tenant-b",
+			wantErr:  ErrTenantNotAllowed,
+		},
+		{
+			name:     "no tenant claims",
+			token:    signHS256(t, jwt.MapClaims{"sub": "user-1", "exp": exp}),
+			tenantID: "tenant-a",
+			wantErr:  ErrTenantNotAllowed,
+		},
+		{
+			name:     "expired",
+			token:    signHS256(t, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(-time.Hour).Unix(), "tenant_id": "tenant-a"}),
+			tenantID: "tenant-a",
+			wantErr:  ErrInvalidToken,
+		},
+		{
+			name:     "missing exp",
+			token:    signHS256(t, jwt.MapClaims{"sub": "user-1", "tenant_id": "tenant-a"}),
+			tenantID: "tenant-a",
+			wantErr:  ErrInvalidToken,
+		},
+		{
+			name:     "missing subject",
+			token:    signHS256(t, jwt.MapClaims{"exp": exp, "tenant_id": "tenant-a"}),
+			tenantID: "tenant-a",
+			wantErr:  ErrInvalidToken,
+		},
+		{
+			name:    "missing token",
+			token:   "",
+			wantErr: ErrMissingToken,
+		},
+		{
+			name:    "opaque token when opaque is disabled",
+			token:   "opaque-token",
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := authn.Authenticate(ctx, tt.token, tt.tenantID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, identity.UserID)
+			assert.Equal(t, TokenTypeJWT, identity.TokenType)
+		})
+	}
+}
+
+func TestAuthenticateRejectsOtherAlgorithms(t *testing.T) {
+	authn, err := NewAuthenticator(Config{JWTSecret: testSecret})
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix(), "tenant_id": "tenant-a"}
+	hs512, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	for _, token := range []string{hs512, none} {
+		_, err := authn.Authenticate(context.Background(), token, "tenant-a")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	}
+}
+
+func TestAuthenticateRS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	authn, err := NewAuthenticator(Config{JWKSURL: server.URL, Issuer: "https://auth.example.com"})
+	require.NoError(t, err)
+
+	sign := func(kid, issuer string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"sub": "user-1", "iss": issuer, "exp": time.Now().Add(time.Hour).Unix(), "tenant_ids": []string{"tenant-a"},
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	identity, err := authn.Authenticate(context.Background(), sign("key-1", "https://auth.example.com"), "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", identity.UserID)
+
+	_, err = authn.Authenticate(context.Background(), sign("key-2", "https://auth.example.com"), "tenant-a")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = authn.Authenticate(context.Background(), sign("key-1", "https://evil.example.com"), "tenant-a")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// HS256 is not accepted when only JWKS is configured
+	_, err = authn.Authenticate(context.Background(),
+		signHS256(t, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix(), "tenant_id": "tenant-a"}), "tenant-a")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthenticateOpaque(t *testing.T) {
+	authn, err := NewAuthenticator(Config{
+		AllowOpaque: true,
+		OpaqueLookup: fakeOpaqueVerifier{
+			"good": {Valid: true, UserID: "user-1", TenantIDs: []string{"tenant-a"}, Role: "admin", Claims: map[string]string{"roles": "admin,user"}},
+		},
+	})
+	require.NoError(t, err)
+
+	identity, err := authn.Authenticate(context.Background(), "good", "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, TokenTypeOpaque, identity.TokenType)
+	assert.Equal(t, []string{"admin", "user"}, identity.Roles)
+
+	_, err = authn.Authenticate(context.Background(), "bad", "tenant-a")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNewAuthenticatorRequiresAVerifier(t *testing.T) {
+	_, err := NewAuthenticator(Config{AllowOpaque: true})
+	assert.Error(t, err)
+}
+
+func TestBearerToken(t *testing.T) {
+	assert.Equal(t, "abc", BearerToken("Bearer abc"))
+	assert.Equal(t, "abc", BearerToken("bearer abc"))
+	assert.Equal(t, "", BearerToken("Basic abc"))
+	assert.Equal(t, "", BearerToken("Bearer"))
+}
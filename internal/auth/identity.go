@@ -0,0 +1,72 @@
+package auth
+
+import "context"
+
+// AllTenants is the tenant claim value granting access to every tenant,
+// used by platform administrators and service-to-service tokens
+const AllTenants = "*"
+
+// Token types an Identity can be authenticated from
+const (
+	TokenTypeJWT    = "jwt"
+	TokenTypeOpaque = "opaque"
+)
+
+// RoleCredentialsReader is carried by the platform service that verifies
+// passwords; it is the only caller that receives password hashes
+const RoleCredentialsReader = "credentials:read"
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	UserID    string
+	TenantIDs []string // Tenants the token grants access to, may contain AllTenants
+	Roles     []string // Roles carried by the token, if any
+	TokenType string
+	Claims    map[string]string
+}
+
+// HasTenant reports whether the identity may act in the given tenant
+func (i *Identity) HasTenant(tenantID string) bool {
+	for _, id := range i.TenantIDs {
+		if id == tenantID || id == AllTenants {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPlatform reports whether the identity is scoped to every tenant
+func (i *Identity) IsPlatform() bool {
+	for _, id := range i.TenantIDs {
+		if id == AllTenants {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the token carries the given role
+func (i *Identity) HasRole(role string) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type identityKey struct{}
+
+// WithIdentity stores the caller identity in a context
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext retrieves the caller identity from a context
+// Returns nil if the request was not authenticated
+func IdentityFromContext(ctx context.Context) *Identity {
+	if identity, ok := ctx.Value(identityKey{}).(*Identity); ok {
+		return identity
+	}
+	return nil
+}
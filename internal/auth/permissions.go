@@ -0,0 +1,99 @@
+package auth
+
+// Permission is an action a tenant member may be allowed to perform
+type Permission string
+
+// Permissions understood by the service
+const (
+	PermUsersCreate       Permission = "users:create"
+	PermUsersUpdateSelf   Permission = "users:update:self"
+	PermUsersUpdateAny    Permission = "users:update:any"
+	PermUsersDelete       Permission = "users:delete"
+	PermRolesManage       Permission = "roles:manage"
+	PermPreferencesManage Permission = "preferences:manage"
+	PermSegmentsManage    Permission = "segments:manage"
+	PermTagsManage        Permission = "tags:manage"
+	PermPoliciesManage    Permission = "policies:manage"
+)
+
+// Built-in tenant roles
+const (
+	RoleOwner   = "owner"
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleUser    = "user"
+)
+
+// AllPermissions lists every permission in a stable order
+var AllPermissions = []Permission{
+	PermUsersCreate,
+	PermUsersUpdateSelf,
+	PermUsersUpdateAny,
+	PermUsersDelete,
+	PermRolesManage,
+	PermPreferencesManage,
+	PermSegmentsManage,
+	PermTagsManage,
+	PermPoliciesManage,
+}
+
+// BuiltinRoles maps each built-in role to the permissions it grants
+var BuiltinRoles = map[string][]Permission{
+	RoleOwner:   AllPermissions,
+	RoleAdmin:   AllPermissions,
+	RoleManager: {PermUsersCreate, PermUsersUpdateSelf, PermUsersUpdateAny},
+	RoleUser:    {PermUsersUpdateSelf},
+}
+
+// IsBuiltinRole reports whether role is one of the built-in roles
+func IsBuiltinRole(role string) bool {
+	_, ok := BuiltinRoles[role]
+	return ok
+}
+
+// IsValidPermission reports whether p is a known permission
+func IsValidPermission(p Permission) bool {
+	for _, known := range AllPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleCatalog maps a tenant's custom role keys to the permissions they grant
+type RoleCatalog map[string][]Permission
+
+// PermissionsForRoles returns the union of permissions granted by roles
+// Built-in roles take precedence over the catalog; unknown roles grant nothing
+func PermissionsForRoles(roles []string, catalog RoleCatalog) map[Permission]bool {
+	granted := make(map[Permission]bool)
+	for _, role := range roles {
+		perms, ok := BuiltinRoles[role]
+		if !ok {
+			perms = catalog[role]
+		}
+		for _, p := range perms {
+			granted[p] = true
+		}
+	}
+	return granted
+}
+
+// RolesAllow reports whether any of the roles grants the permission
+func RolesAllow(roles []string, catalog RoleCatalog, p Permission) bool {
+	return PermissionsForRoles(roles, catalog)[p]
+}
+
+// CanGrantRoles reports whether a member holding callerRoles may assign
+// roles to someone else: every permission the new roles grant must already
+// be held by the caller, so roles can never be used to escalate privileges
+func CanGrantRoles(callerRoles, roles []string, catalog RoleCatalog) bool {
+	held := PermissionsForRoles(callerRoles, catalog)
+	for p := range PermissionsForRoles(roles, catalog) {
+		if !held[p] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit actions
+const (
+	// AuditMembershipPurged records the hard delete of a removed membership
+	AuditMembershipPurged = "membership.purged"
+	// AuditUserPurged records the hard delete of a global user left without memberships
+	AuditUserPurged = "user.purged"
+	// AuditUserExported records an export of everything stored about a user
+	AuditUserExported = "user.exported"
+)
+
+// AuditActorPurger is the actor recorded for changes made by the membership purger
+const AuditActorPurger = "system:membership-purger"
+
+// AuditEntry records an action taken on a user. Entries outlive the user and
+// membership they describe, so they only hold IDs and plain details
+type AuditEntry struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	// TenantID is empty for actions on the global user
+	TenantID string `bson:"tenantId,omitempty" json:"tenant_id,omitempty"`
+	// UserID is the user the action was taken on
+	UserID string `bson:"userId" json:"user_id"`
+	Action string `bson:"action" json:"action"`
+	// ActorID is the user or service that took the action
+	ActorID   string            `bson:"actorId" json:"actor_id"`
+	Details   map[string]string `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt time.Time         `bson:"createdAt" json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Membership event types
+const (
+	// EventMembershipExpired is published when an expired membership is deactivated
+	EventMembershipExpired = "membership.expired"
+	// EventInactivityWarning is published when an inactive member is warned
+	// that their membership will be suspended
+	EventInactivityWarning = "membership.inactivity_warning"
+	// EventInactivitySuspended is published when an inactive member is suspended
+	EventInactivitySuspended = "membership.inactivity_suspended"
+)
+
+// Actors recorded as the author of changes the service makes on its own
+const (
+	ActorExpiryScheduler = "system:membership-expiry"
+	ActorInactivityJob   = "system:inactivity-policy"
+)
+
+// MembershipEvent reports a change the service made to a membership on its
+// own, for other services to react to
+type MembershipEvent struct {
+	Type     string    `json:"type"`
+	TenantID string    `json:"tenant_id"`
+	UserID   string    `json:"user_id"`
+	At       time.Time `json:"at"`
+	// Details holds event specific values, e.g. the expiry of an expired membership
+	Details map[string]string `json:"details,omitempty"`
+}
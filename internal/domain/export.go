@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// Export bundle formats
+const (
+	// ExportFormatJSON is a single JSON document holding the manifest and every section
+	ExportFormatJSON = "json"
+	// ExportFormatZIP is a ZIP archive with manifest.json and a JSON file per section
+	ExportFormatZIP = "zip"
+)
+
+// ExportVersion is the version of the export bundle layout, raised whenever a
+// section changes shape
+const ExportVersion = 1
+
+// Export sections, in bundle order
+const (
+	ExportSectionUser        = "user"
+	ExportSectionMemberships = "memberships"
+	ExportSectionPreferences = "preferences"
+	ExportSectionConsents    = "consents"
+	ExportSectionAuditLog    = "audit_log"
+	ExportSectionTags        = "tags"
+)
+
+// UserExport holds everything the service stores about a user, for the
+// GDPR rights of access and data portability
+type UserExport struct {
+	Manifest    ExportManifest     `json:"manifest"`
+	User        *User              `json:"user"`
+	Memberships []*UserTenant      `json:"memberships"`
+	Preferences []*UserPreferences `json:"preferences"`
+	// Consents is always empty: the service does not record consents yet
+	Consents []interface{}  `json:"consents"`
+	AuditLog []*AuditEntry  `json:"audit_log"`
+	Tags     []ExportedTags `json:"tags"`
+}
+
+// ExportManifest describes an export bundle and its sections
+type ExportManifest struct {
+	Version     int             `json:"version"`
+	Format      string          `json:"format"`
+	UserID      string          `json:"user_id"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	RequestedBy string          `json:"requested_by"`
+	Sections    []ExportSection `json:"sections"`
+}
+
+// ExportSection describes one section of an export bundle
+type ExportSection struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Records     int    `json:"records"`
+	// File and SHA256 locate and checksum the section in a ZIP bundle
+	File   string `json:"file,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// ExportedTags lists the tags on the user's membership in a tenant
+type ExportedTags struct {
+	TenantID string   `json:"tenant_id"`
+	Tags     []string `json:"tags"`
+}
+
+// ExportBundle is an encoded export, ready to be downloaded
+type ExportBundle struct {
+	Format      string
+	ContentType string
+	FileName    string
+	Data        []byte
+}
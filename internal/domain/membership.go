@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MembershipStatus is where a tenant membership is in its lifecycle
+type MembershipStatus string
+
+// Membership statuses. Only active members can sign in to the tenant or act in it
+const (
+	// MembershipInvited has been invited and not accepted yet
+	MembershipInvited MembershipStatus = "invited"
+	// MembershipPending asked to join and awaits approval
+	MembershipPending MembershipStatus = "pending"
+	MembershipActive  MembershipStatus = "active"
+	// MembershipSuspended is temporarily blocked, e.g. during an investigation
+	MembershipSuspended MembershipStatus = "suspended"
+	// MembershipDeactivated has left or been switched off but is still listed
+	MembershipDeactivated MembershipStatus = "deactivated"
+	// MembershipRemoved is soft deleted: hidden from listings until restored
+	MembershipRemoved MembershipStatus = "removed"
+)
+
+// MaxMembershipTransitions is how many status changes a membership keeps
+const MaxMembershipTransitions = 20
+
+// Bounds of a tenant's retention window for removed memberships, in days
+const (
+	DefaultRetentionDays = 30
+	MaxRetentionDays     = 3650
+)
+
+// MaxExpiringDays is the furthest ahead expiring memberships can be listed, in days
+const MaxExpiringDays = 365
+
+// MaxInactivityDays bounds a tenant's inactivity thresholds, in days
+const MaxInactivityDays = 3650
+
+// LastSeenResolution is how often token verification records that a member
+// was seen; explicit touches are always recorded
+const LastSeenResolution = 5 * time.Minute
+
+// MembershipPolicy holds a tenant's rules for the lifecycle of its memberships
+type MembershipPolicy struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID string             `bson:"tenantId" json:"tenant_id"`
+	// RetentionDays is how long removed memberships can be restored before
+	// they are purged for good. Zero uses the service default
+	RetentionDays int `bson:"retentionDays,omitempty" json:"retention_days,omitempty"`
+	// InactivityWarnDays and InactivitySuspendDays are how long active members
+	// can go unseen before they are warned and suspended. Zero disables the step
+	InactivityWarnDays    int `bson:"inactivityWarnDays,omitempty" json:"inactivity_warn_days,omitempty"`
+	InactivitySuspendDays int `bson:"inactivitySuspendDays,omitempty" json:"inactivity_suspend_days,omitempty"`
+	// InactivityEnabledAt is when the tenant turned its inactivity thresholds on
+	InactivityEnabledAt *time.Time `bson:"inactivityEnabledAt,omitempty" json:"inactivity_enabled_at,omitempty"`
+	UpdatedAt           time.Time  `bson:"updatedAt" json:"updated_at"`
+}
+
+// EnforcesInactivity reports whether the policy warns or suspends inactive members
+func (p *MembershipPolicy) EnforcesInactivity() bool {
+	return p.InactivityWarnDays > 0 || p.InactivitySuspendDays > 0
+}
+
+// InactivityTrackedSince returns when the tenant started tracking inactivity,
+// falling back to the last policy change for policies saved before that was recorded
+func (p *MembershipPolicy) InactivityTrackedSince() time.Time {
+	if p.InactivityEnabledAt != nil {
+		return *p.InactivityEnabledAt
+	}
+	return p.UpdatedAt
+}
+
+// membershipTransitions lists the statuses each status may move to
+var membershipTransitions = map[MembershipStatus][]MembershipStatus{
+	MembershipInvited:     {MembershipPending, MembershipActive, MembershipRemoved},
+	MembershipPending:     {MembershipActive, MembershipRemoved},
+	MembershipActive:      {MembershipSuspended, MembershipDeactivated, MembershipRemoved},
+	MembershipSuspended:   {MembershipActive, MembershipDeactivated, MembershipRemoved},
+	MembershipDeactivated: {MembershipActive, MembershipRemoved},
+	MembershipRemoved:     {MembershipActive},
+}
+
+// Valid reports whether s is a known status
+func (s MembershipStatus) Valid() bool {
+	_, ok := membershipTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a membership in status s may move to status to
+func (s MembershipStatus) CanTransitionTo(to MembershipStatus) bool {
+	for _, allowed := range membershipTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// MembershipTransition records one status change of a membership
+type MembershipTransition struct {
+	From   MembershipStatus `bson:"from,omitempty" json:"from,omitempty"`
+	To     MembershipStatus `bson:"to" json:"to"`
+	Reason string           `bson:"reason,omitempty" json:"reason,omitempty"`
+	// By is the ID of the user or service that made the change
+	By string    `bson:"by,omitempty" json:"by,omitempty"`
+	At time.Time `bson:"at" json:"at"`
+}
+
+// CurrentStatus returns the membership's status. Memberships stored before
+// statuses existed only have IsActive, which was cleared by soft deletes
+func (ut *UserTenant) CurrentStatus() MembershipStatus {
+	if ut.Status != "" {
+		return ut.Status
+	}
+	if ut.IsActive {
+		return MembershipActive
+	}
+	return MembershipRemoved
+}
+
+// Active reports whether the membership is active and has not expired. An
+// expired membership stays active until the expiry scheduler deactivates it
+func (ut *UserTenant) Active() bool {
+	return ut.CurrentStatus() == MembershipActive && !ut.Expired(time.Now())
+}
+
+// InactiveSince returns when the member was last seen or, if later or never
+// seen, when the membership last changed status. Members never seen count from
+// no earlier than trackedSince, since being unseen before then says nothing
+func (ut *UserTenant) InactiveSince(trackedSince time.Time) time.Time {
+	since := ut.StatusChangedAt
+	if since.IsZero() {
+		since = ut.JoinedAt
+	}
+	if ut.LastSeenAt == nil {
+		if trackedSince.After(since) {
+			since = trackedSince
+		}
+	} else if ut.LastSeenAt.After(since) {
+		since = *ut.LastSeenAt
+	}
+	return since
+}
+
+// Expired reports whether the membership has an expiry at or before now
+func (ut *UserTenant) Expired(now time.Time) bool {
+	return ut.ExpiresAt != nil && !ut.ExpiresAt.After(now)
+}
+
+// ApplyTransition moves the membership to t.To, keeping IsActive in step and
+// the most recent transitions. It does not check that the move is allowed
+func (ut *UserTenant) ApplyTransition(t MembershipTransition) {
+	ut.Status = t.To
+	ut.IsActive = t.To == MembershipActive
+	ut.StatusChangedAt = t.At
+	ut.Transitions = append(ut.Transitions, t)
+	if n := len(ut.Transitions); n > MaxMembershipTransitions {
+		ut.Transitions = ut.Transitions[n-MaxMembershipTransitions:]
+	}
+}
+
+// InactivityReport lists what applying a tenant's inactivity policy changed,
+// or would have changed on a dry run
+type InactivityReport struct {
+	TenantID    string           `json:"tenant_id"`
+	DryRun      bool             `json:"dry_run"`
+	RanAt       time.Time        `json:"ran_at"`
+	WarnDays    int              `json:"warn_days"`
+	SuspendDays int              `json:"suspend_days"`
+	Warned      []InactiveMember `json:"warned"`
+	Suspended   []InactiveMember `json:"suspended"`
+}
+
+// Changed reports whether any member was warned or suspended
+func (r *InactivityReport) Changed() bool {
+	return len(r.Warned) > 0 || len(r.Suspended) > 0
+}
+
+// InactiveMember is a member warned or suspended for inactivity
+type InactiveMember struct {
+	UserID string `json:"user_id"`
+	// InactiveSince is when the member was last seen or, if later, last changed status
+	InactiveSince time.Time  `json:"inactive_since"`
+	LastSeenAt    *time.Time `json:"last_seen_at,omitempty"`
+}
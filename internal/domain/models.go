@@ -8,14 +8,16 @@ import (
 
 // User represents a global user identity
 type User struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Email        string             `bson:"email" json:"email"`
-	PasswordHash string             `bson:"passwordHash,omitempty" json:"-"`
-	Phone        string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	AvatarURL    string             `bson:"avatarUrl,omitempty" json:"avatar_url,omitempty"`
-	IsActive     bool               `bson:"isActive" json:"is_active"`
-	CreatedAt    time.Time          `bson:"createdAt" json:"created_at"`
-	UpdatedAt    time.Time          `bson:"updatedAt" json:"updated_at"`
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email          string             `bson:"email" json:"email"`
+	Username       string             `bson:"username,omitempty" json:"username,omitempty"`
+	DocumentNumber string             `bson:"documentNumber,omitempty" json:"document_number,omitempty"`
+	PasswordHash   string             `bson:"passwordHash,omitempty" json:"-"`
+	Phone          string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	AvatarURL      string             `bson:"avatarUrl,omitempty" json:"avatar_url,omitempty"`
+	IsActive       bool               `bson:"isActive" json:"is_active"`
+	CreatedAt      time.Time          `bson:"createdAt" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updatedAt" json:"updated_at"`
 }
 
 // UserTenant represents the association between a user and a tenant
@@ -26,8 +28,56 @@ type UserTenant struct {
 	Roles     []string           `bson:"roles" json:"roles"`
 	FirstName string             `bson:"firstName,omitempty" json:"first_name"`
 	LastName  string             `bson:"lastName,omitempty" json:"last_name"`
-	IsActive  bool               `bson:"isActive" json:"is_active"`
-	JoinedAt  time.Time          `bson:"joinedAt" json:"joined_at"`
+	// IsActive mirrors Status == MembershipActive so that readers of the flag
+	// keep working; change it only through status transitions
+	IsActive bool      `bson:"isActive" json:"is_active"`
+	JoinedAt time.Time `bson:"joinedAt" json:"joined_at"`
+	// Status is the membership's lifecycle state. Memberships stored before it
+	// existed have none; see CurrentStatus
+	Status          MembershipStatus `bson:"status,omitempty" json:"status"`
+	StatusChangedAt time.Time        `bson:"statusChangedAt,omitempty" json:"status_changed_at"`
+	// Transitions are the most recent status changes, oldest first
+	Transitions []MembershipTransition `bson:"transitions,omitempty" json:"transitions,omitempty"`
+	// ExpiresAt is when the membership is deactivated automatically; nil never expires
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty" json:"expires_at,omitempty"`
+	// LastSeenAt is when the member last used the tenant; nil if never seen
+	LastSeenAt *time.Time `bson:"lastSeenAt,omitempty" json:"last_seen_at,omitempty"`
+	// InactivityWarnedAt is when the member was last warned about inactivity
+	InactivityWarnedAt *time.Time `bson:"inactivityWarnedAt,omitempty" json:"inactivity_warned_at,omitempty"`
+	// Tags are free-form labels managed by the tenant's admins, e.g. "vip" or "team:backend"
+	Tags []string `bson:"tags,omitempty" json:"tags,omitempty"`
+	// SearchName and SearchTokens hold the names folded for diacritic-insensitive
+	// search; the repository derives them on every write
+	SearchName   string   `bson:"searchName,omitempty" json:"-"`
+	SearchTokens []string `bson:"searchTokens,omitempty" json:"-"`
+}
+
+// Role represents a custom role defined by a tenant in its role catalog
+// Members reference it by Key in UserTenant.Roles
+type Role struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID    string             `bson:"tenantId" json:"tenant_id"`
+	Key         string             `bson:"key" json:"key"`
+	DisplayName string             `bson:"displayName" json:"display_name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Permissions []string           `bson:"permissions" json:"permissions"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updated_at"`
+}
+
+// Segment is a tenant's named, saved member filter. Members are evaluated
+// whenever the segment is read, so it always reflects current memberships
+type Segment struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID    string             `bson:"tenantId" json:"tenant_id"`
+	Key         string             `bson:"key" json:"key"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	// Filter uses the ListUsers filter syntax, e.g. "isActive:false,role:contractor"
+	// An empty filter selects every member
+	Filter    string    `bson:"filter" json:"filter"`
+	CreatedAt time.Time `bson:"createdAt" json:"created_at"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updated_at"`
 }
 
 // UserProfile represents the combined view of a user and their tenant context
@@ -36,13 +86,198 @@ type UserProfile struct {
 	UserTenant *UserTenant
 }
 
+// Fields ListUsers can sort by
+const (
+	UserSortName      = "name"
+	UserSortEmail     = "email"
+	UserSortJoinedAt  = "joinedAt"
+	UserSortCreatedAt = "createdAt"
+)
+
+// UserSort orders a user listing by one field; ties are broken by membership ID
+type UserSort struct {
+	Field string
+	Desc  bool
+}
+
+// UserSortRelevance orders search results by how well names match the query,
+// then newest first. It is chosen by search and cannot be requested
+const UserSortRelevance = "relevance"
+
+// UserSortExpiresAt orders memberships by when they expire. It is chosen by
+// the expiring members listing and cannot be requested
+const UserSortExpiresAt = "expiresAt"
+
+var (
+	// DefaultUserSort lists the newest members first
+	DefaultUserSort = UserSort{Field: UserSortJoinedAt, Desc: true}
+	// RelevanceUserSort lists the best search matches first
+	RelevanceUserSort = UserSort{Field: UserSortRelevance, Desc: true}
+)
+
+// String returns the sort in query syntax, e.g. "-joinedAt"
+func (s UserSort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// TimeRange bounds a timestamp; nil bounds are open
+type TimeRange struct {
+	Gt  *time.Time
+	Gte *time.Time
+	Lt  *time.Time
+	Lte *time.Time
+}
+
+// IsZero reports whether the range has no bounds
+func (r TimeRange) IsZero() bool {
+	return r.Gt == nil && r.Gte == nil && r.Lt == nil && r.Lte == nil
+}
+
+// Contains reports whether t lies within the range
+func (r TimeRange) Contains(t time.Time) bool {
+	return (r.Gt == nil || t.After(*r.Gt)) &&
+		(r.Gte == nil || !t.Before(*r.Gte)) &&
+		(r.Lt == nil || t.Before(*r.Lt)) &&
+		(r.Lte == nil || !t.After(*r.Lte))
+}
+
+// UserFilter restricts a user listing; zero fields do not filter
+type UserFilter struct {
+	// Status matches memberships in the status; when empty, removed memberships
+	// are left out
+	Status MembershipStatus
+	// IsActive matches the membership's active flag
+	IsActive *bool
+	// Role matches members holding the role
+	Role string
+	// Tag matches members carrying the tag
+	Tag       string
+	JoinedAt  TimeRange
+	CreatedAt TimeRange
+	// ExpiresAt never matches memberships without an expiry
+	ExpiresAt TimeRange
+	// EmailDomain matches emails ending in "@" + EmailDomain, lower case
+	EmailDomain string
+	HasPhone    *bool
+}
+
+// HasUserFields reports whether the filter reads fields of the global user
+// rather than of the membership
+func (f UserFilter) HasUserFields() bool {
+	return !f.CreatedAt.IsZero() || f.EmailDomain != "" || f.HasPhone != nil
+}
+
+// Facets ListUsers and SearchUsers can count members by
+const (
+	UserFacetRole        = "role"
+	UserFacetIsActive    = "isActive"
+	UserFacetStatus      = "status"
+	UserFacetJoinedMonth = "joinedMonth"
+	UserFacetEmailDomain = "emailDomain"
+	UserFacetTag         = "tag"
+)
+
+// FacetCount is the number of members sharing a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// UserFacets holds the counts of each requested facet. Role, isActive, tag
+// and emailDomain values are ordered by count, largest first; joinedMonth values
+// (YYYY-MM, UTC) newest first
+type UserFacets map[string][]FacetCount
+
+// TagCount is the number of a tenant's memberships carrying a tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// UserPage is one page of a tenant's members fetched with a cursor
+type UserPage struct {
+	Users []*UserProfile
+	// NextCursor continues after the last user; empty on the last page
+	NextCursor string
+	// Total counts every matching member; nil unless it was requested
+	Total *int64
+}
+
 // UserPreferences represents user preferences
+// Stored documents only hold the values a user has set; empty fields fall back to defaults
 type UserPreferences struct {
-	ID       primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
-	UserID   string                 `bson:"userId" json:"user_id"`
-	TenantID string                 `bson:"tenantId" json:"tenant_id"`
-	Language string                 `bson:"language" json:"language"`
-	Timezone string                 `bson:"timezone" json:"timezone"`
-	Theme    string                 `bson:"theme" json:"theme"`
-	Settings map[string]interface{} `bson:"settings,omitempty" json:"settings,omitempty"`
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	UserID    string                 `bson:"userId" json:"user_id"`
+	TenantID  string                 `bson:"tenantId" json:"tenant_id"`
+	Language  string                 `bson:"language" json:"language"`
+	Timezone  string                 `bson:"timezone" json:"timezone"`
+	Theme     string                 `bson:"theme" json:"theme"`
+	Settings  map[string]interface{} `bson:"settings,omitempty" json:"settings,omitempty"`
+	UpdatedAt time.Time              `bson:"updatedAt" json:"updated_at"`
+}
+
+// TenantPreferences holds a tenant's preference defaults for its members
+// Locked lists preference paths ("language", "settings.notifications") that
+// members cannot override
+type TenantPreferences struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	TenantID  string                 `bson:"tenantId" json:"tenant_id"`
+	Language  string                 `bson:"language" json:"language"`
+	Timezone  string                 `bson:"timezone" json:"timezone"`
+	Theme     string                 `bson:"theme" json:"theme"`
+	Settings  map[string]interface{} `bson:"settings,omitempty" json:"settings,omitempty"`
+	Locked    []string               `bson:"locked,omitempty" json:"locked,omitempty"`
+	UpdatedAt time.Time              `bson:"updatedAt" json:"updated_at"`
+}
+
+// Sources of an effective preference value, from lowest to highest precedence
+const (
+	PreferenceSourcePlatform = "platform"
+	PreferenceSourceTenant   = "tenant"
+	PreferenceSourceUser     = "user"
+)
+
+// EffectivePreferences are a user's resolved preferences in a tenant
+// Sources maps each preference path to the layer its value came from
+type EffectivePreferences struct {
+	Preferences *UserPreferences
+	Sources     map[string]string
+	Locked      []string
+}
+
+// Levels at which a settings schema can be registered
+const (
+	SettingsSchemaLevelPlatform = "platform"
+	SettingsSchemaLevelTenant   = "tenant"
+)
+
+// SettingsSchema is a JSON Schema for one top-level namespace of preference settings
+// TenantID is empty for platform schemas; a tenant schema replaces the platform
+// schema of the same namespace within that tenant
+type SettingsSchema struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID  string             `bson:"tenantId" json:"tenant_id,omitempty"`
+	Namespace string             `bson:"namespace" json:"namespace"`
+	// Schema is the raw JSON document, stored as text because schema keywords
+	// such as "$id" are not valid MongoDB field names
+	Schema    string    `bson:"schema" json:"-"`
+	CreatedAt time.Time `bson:"createdAt" json:"created_at"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updated_at"`
+}
+
+// AccessToken represents an opaque access token issued to a user
+// Only the SHA-256 hash of the raw token is stored
+type AccessToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TokenHash string             `bson:"tokenHash" json:"-"`
+	UserID    primitive.ObjectID `bson:"userId" json:"user_id"`
+	TenantIDs []string           `bson:"tenantIds,omitempty" json:"tenant_ids,omitempty"` // Empty means every active membership
+	Role      string             `bson:"role,omitempty" json:"role,omitempty"`
+	Claims    map[string]string  `bson:"claims,omitempty" json:"claims,omitempty"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expires_at"`
+	RevokedAt *time.Time         `bson:"revokedAt,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt" json:"created_at"`
 }
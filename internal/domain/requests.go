@@ -1,27 +1,165 @@
 package domain
 
+import "time"
+
 // CreateUserRequest represents a user creation request
 type CreateUserRequest struct {
-	Email     string `json:"email" binding:"required,email"`
-	TenantID  string `json:"tenant_id"` // Injected from X-Tenant-ID header by middleware
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Phone     string `json:"phone"`
+	Email          string   `json:"email" binding:"required,email"`
+	TenantID       string   `json:"tenant_id"` // Injected from X-Tenant-ID header by middleware
+	Username       string   `json:"username"`
+	DocumentNumber string   `json:"document_number"`
+	Password       string   `json:"password"`
+	FirstName      string   `json:"first_name"`
+	LastName       string   `json:"last_name"`
+	Phone          string   `json:"phone"`
+	Roles          []string `json:"roles"` // Initial roles in the tenant, defaults to ["user"]
+	// Status is the initial membership status: invited, pending or active (the default)
+	Status MembershipStatus `json:"status"`
+	// ExpiresAt is when the membership is deactivated (RFC 3339); empty never expires
+	ExpiresAt string `json:"expires_at"`
 }
 
 // UpdateUserRequest represents a user update request
 type UpdateUserRequest struct {
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Phone     string   `json:"phone"`
+	AvatarURL string   `json:"avatar_url"`
+	Roles     []string `json:"roles"` // Replaces the member's roles when set
+	// ExpiresAt sets when the membership is deactivated (RFC 3339) when set;
+	// an empty string removes the expiry
+	ExpiresAt *string `json:"expires_at"`
+}
+
+// MembershipStatusRequest represents a membership status change
+type MembershipStatusRequest struct {
+	Status MembershipStatus `json:"status" binding:"required"`
+	Reason string           `json:"reason"`
+}
+
+// StatusReasonRequest carries the optional reason of a suspend, reactivate or restore
+type StatusReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// MembershipPolicyRequest represents a membership policy write
+// The request replaces the tenant's policy; zero fields use the service defaults
+type MembershipPolicyRequest struct {
+	RetentionDays         int `json:"retention_days"`
+	InactivityWarnDays    int `json:"inactivity_warn_days"`
+	InactivitySuspendDays int `json:"inactivity_suspend_days"`
+}
+
+// MembershipPolicyResponse represents a tenant's effective membership policy
+type MembershipPolicyResponse struct {
+	TenantID              string `json:"tenant_id"`
+	RetentionDays         int    `json:"retention_days"`
+	InactivityWarnDays    int    `json:"inactivity_warn_days"`
+	InactivitySuspendDays int    `json:"inactivity_suspend_days"`
+	UpdatedAt             string `json:"updated_at,omitempty"`
+}
+
+// UpdateMeRequest represents a self-service profile update
+// Only fields the user owns are accepted; empty fields are left unchanged
+type UpdateMeRequest struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Phone     string `json:"phone"`
 	AvatarURL string `json:"avatar_url"`
 }
 
+// PreferencesRequest represents a preferences write
+// For PUT, nil fields are reset to their defaults; for PATCH, nil fields are left
+// unchanged and an empty string resets a field. PATCH merges Settings as a JSON
+// merge patch, where null removes a key
+type PreferencesRequest struct {
+	Language *string                `json:"language"`
+	Timezone *string                `json:"timezone"`
+	Theme    *string                `json:"theme"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// PreferencesResponse represents a user's effective preferences in API responses
+type PreferencesResponse struct {
+	UserID    string                 `json:"user_id"`
+	TenantID  string                 `json:"tenant_id"`
+	Language  string                 `json:"language"`
+	Timezone  string                 `json:"timezone"`
+	Theme     string                 `json:"theme"`
+	Settings  map[string]interface{} `json:"settings"`
+	UpdatedAt string                 `json:"updated_at,omitempty"`
+}
+
+// EffectivePreferencesResponse represents resolved preferences with the source of each value
+type EffectivePreferencesResponse struct {
+	PreferencesResponse
+	Sources map[string]string `json:"sources"`
+	Locked  []string          `json:"locked"`
+}
+
+// TenantPreferencesRequest represents a tenant preference defaults write
+// The request replaces the tenant's defaults; empty fields have no tenant default
+type TenantPreferencesRequest struct {
+	Language string                 `json:"language"`
+	Timezone string                 `json:"timezone"`
+	Theme    string                 `json:"theme"`
+	Settings map[string]interface{} `json:"settings"`
+	Locked   []string               `json:"locked"`
+}
+
+// TenantPreferencesResponse represents a tenant's preference defaults in API responses
+type TenantPreferencesResponse struct {
+	TenantID  string                 `json:"tenant_id"`
+	Language  string                 `json:"language,omitempty"`
+	Timezone  string                 `json:"timezone,omitempty"`
+	Theme     string                 `json:"theme,omitempty"`
+	Settings  map[string]interface{} `json:"settings"`
+	Locked    []string               `json:"locked"`
+	UpdatedAt string                 `json:"updated_at,omitempty"`
+}
+
+// SettingsSchemaRequest represents a settings schema registration
+type SettingsSchemaRequest struct {
+	Schema map[string]interface{} `json:"schema" binding:"required"`
+}
+
+// SettingsSchemaResponse represents a settings schema in API responses
+type SettingsSchemaResponse struct {
+	Namespace string                 `json:"namespace"`
+	Level     string                 `json:"level"` // platform or tenant
+	TenantID  string                 `json:"tenant_id,omitempty"`
+	Schema    map[string]interface{} `json:"schema"`
+	CreatedAt string                 `json:"created_at"`
+	UpdatedAt string                 `json:"updated_at"`
+}
+
+// AddUserToTenantRequest represents a request to add an existing user to a tenant
+type AddUserToTenantRequest struct {
+	TenantID string   `json:"tenant_id"` // Defaults to the X-Tenant-ID header
+	Roles    []string `json:"roles"`     // Defaults to ["user"]
+	// ExpiresAt is when the membership is deactivated (RFC 3339); empty never expires
+	ExpiresAt string `json:"expires_at"`
+}
+
+// UpdateUserTenantRequest represents a request to change a member's roles
+type UpdateUserTenantRequest struct {
+	Roles []string `json:"roles" binding:"required"`
+}
+
 // ListUsersRequest represents a list users request
 type ListUsersRequest struct {
 	TenantID string `form:"tenant_id"`
 	Page     int    `form:"page"`
 	PageSize int    `form:"itemsPerPage"`
+	// Filter holds comma separated clauses, e.g. "isActive:true,role:admin,tag:vip,joinedAt>=2024-01-01"
+	Filter string `form:"filter"`
+	// Sort is name, email, joinedAt or createdAt, prefixed with "-" for descending order
+	Sort string `form:"sort"`
+	// Cursor continues a cursor listing; used by ListUsersAfter only
+	Cursor       string `form:"cursor"`
+	IncludeTotal bool   `form:"include_total"`
+	// Facets lists facets to count, e.g. "role,isActive,tag,joinedMonth,emailDomain"
+	Facets string `form:"facets"`
 }
 
 // SearchUsersRequest represents a search users request
@@ -34,16 +172,106 @@ type SearchUsersRequest struct {
 
 // UserResponse represents a user in API responses
 type UserResponse struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	TenantID  string `json:"tenant_id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Phone     string `json:"phone,omitempty"`
-	AvatarURL string `json:"avatar_url,omitempty"`
-	IsActive  bool   `json:"is_active"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID             string `json:"id"`
+	Email          string `json:"email"`
+	Username       string `json:"username,omitempty"`
+	DocumentNumber string `json:"document_number,omitempty"`
+	TenantID       string `json:"tenant_id"`
+	FirstName      string `json:"first_name"`
+	LastName       string `json:"last_name"`
+	Phone          string `json:"phone,omitempty"`
+	AvatarURL      string `json:"avatar_url,omitempty"`
+	IsActive       bool   `json:"is_active"`
+	Status         string `json:"status"`
+	ExpiresAt      string `json:"expires_at,omitempty"`
+	LastSeenAt     string `json:"last_seen_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// CreateRoleRequest represents a custom role creation request
+type CreateRoleRequest struct {
+	Key         string   `json:"key" binding:"required"`
+	DisplayName string   `json:"display_name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest represents a custom role update request
+// Nil fields are left unchanged
+type UpdateRoleRequest struct {
+	DisplayName *string  `json:"display_name"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// CreateSegmentRequest represents a segment creation request
+type CreateSegmentRequest struct {
+	Key         string `json:"key" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Filter      string `json:"filter"`
+}
+
+// UpdateSegmentRequest represents a segment update request
+// Nil fields are left unchanged
+type UpdateSegmentRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Filter      *string `json:"filter"`
+}
+
+// TagsRequest lists tags to add to a membership
+type TagsRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// BulkTagRequest adds and removes tags on several memberships at once
+type BulkTagRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required"`
+	Add     []string `json:"add"`
+	Remove  []string `json:"remove"`
+}
+
+// BulkTagResult reports how many of the requested memberships were tagged
+type BulkTagResult struct {
+	Matched int64 `json:"matched"`
+}
+
+// SegmentMembership reports whether a user belongs to a segment
+type SegmentMembership struct {
+	SegmentKey string `json:"segment_key"`
+	UserID     string `json:"user_id"`
+	Member     bool   `json:"member"`
+}
+
+// RoleResponse represents a role in API responses
+type RoleResponse struct {
+	Key         string   `json:"key"`
+	DisplayName string   `json:"display_name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+	Builtin     bool     `json:"builtin"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+}
+
+// UserTenantResponse represents a tenant membership in API responses
+type UserTenantResponse struct {
+	UserID    string   `json:"user_id"`
+	TenantID  string   `json:"tenant_id"`
+	Roles     []string `json:"roles"`
+	FirstName string   `json:"first_name,omitempty"`
+	LastName  string   `json:"last_name,omitempty"`
+	IsActive  bool     `json:"is_active"`
+	JoinedAt  string   `json:"joined_at"`
+	Tags      []string `json:"tags,omitempty"`
+
+	Status          string                 `json:"status"`
+	StatusChangedAt string                 `json:"status_changed_at,omitempty"`
+	Transitions     []MembershipTransition `json:"transitions,omitempty"`
+	ExpiresAt       string                 `json:"expires_at,omitempty"`
+	LastSeenAt      string                 `json:"last_seen_at,omitempty"`
 }
 
 // ListUsersResponse represents a paginated list of users
@@ -52,4 +280,46 @@ type ListUsersResponse struct {
 	Total    int64          `json:"total"`
 	Page     int            `json:"page"`
 	PageSize int            `json:"itemsPerPage"`
+	Facets   UserFacets     `json:"facets,omitempty"`
+}
+
+// CursorUsersResponse represents a page of users fetched with a cursor
+type CursorUsersResponse struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor string         `json:"next_cursor"`
+	Total      *int64         `json:"total,omitempty"`
+	PageSize   int            `json:"itemsPerPage"`
+	Facets     UserFacets     `json:"facets,omitempty"`
+}
+
+// MemberSuggestion is the compact view of a member returned by autocomplete
+type MemberSuggestion struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	Email       string `json:"email"`
+}
+
+// IssueTokenRequest represents an opaque token issue request
+type IssueTokenRequest struct {
+	UserID    string
+	TenantIDs []string // Empty means every active membership
+	Role      string
+	Claims    map[string]string
+	TTL       time.Duration // Zero means the default TTL
+}
+
+// TokenVerification represents the outcome of verifying an opaque token
+type TokenVerification struct {
+	Valid     bool
+	UserID    string
+	TenantIDs []string // Active memberships the token may be used for
+	Role      string
+	Claims    map[string]string
+}
+
+// PermissionCheck is the result of checking a user's permission in a tenant
+type PermissionCheck struct {
+	Allowed bool
+	Roles   []string // The user's active roles in the tenant
 }
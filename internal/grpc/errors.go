@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-user-service/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatusError converts a service error into a gRPC status error
+// so that callers can branch on codes.NotFound, codes.AlreadyExists, etc.
+func toStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	appErr := errors.FromError(err)
+	st := status.New(httpStatusToCode(appErr.StatusCode), appErr.Message)
+
+	// Report settings schema violations per path
+	var settingsErr *service.SettingsValidationError
+	if stderrors.As(err, &settingsErr) {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range settingsErr.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Path,
+				Description: v.Message,
+			})
+		}
+		if withDetails, detailErr := st.WithDetails(badRequest); detailErr == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
+
+func httpStatusToCode(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
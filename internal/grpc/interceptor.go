@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys read by AuthUnaryInterceptor
+const (
+	authorizationMetadata = "authorization"
+	tenantIDMetadata      = "x-tenant-id"
+)
+
+// tenantScoped is implemented by generated request messages with a tenant_id field
+type tenantScoped interface {
+	GetTenantId() string
+}
+
+// AuthUnaryInterceptor authenticates unary calls with the bearer token in the
+// "authorization" metadata, mirroring AuthMiddleware for HTTP. The tenant is taken
+// from the request's tenant_id field, or the "x-tenant-id" metadata when the request
+// has none; both must agree when both are set. Methods listed in publicMethods
+// (full names, e.g. "/user.UserService/VerifyToken") skip authentication.
+func AuthUnaryInterceptor(authenticator *auth.Authenticator, publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, m := range publicMethods {
+		public[m] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if public[info.FullMethod] || strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		token := auth.BearerToken(firstMetadata(md, authorizationMetadata))
+
+		tenantID := firstMetadata(md, tenantIDMetadata)
+		if scoped, ok := req.(tenantScoped); ok && scoped.GetTenantId() != "" {
+			if tenantID != "" && tenantID != scoped.GetTenantId() {
+				return nil, status.Error(codes.InvalidArgument, "x-tenant-id metadata does not match tenant_id")
+			}
+			tenantID = scoped.GetTenantId()
+		}
+
+		identity, err := authenticator.Authenticate(ctx, token, tenantID)
+		if err != nil {
+			switch {
+			case errors.Is(err, auth.ErrTenantNotAllowed):
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			case errors.Is(err, auth.ErrMissingToken), errors.Is(err, auth.ErrInvalidToken):
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+			return nil, status.Error(codes.Unavailable, "token could not be verified")
+		}
+
+		return handler(auth.WithIdentity(ctx, identity), req)
+	}
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
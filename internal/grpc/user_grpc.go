@@ -2,39 +2,54 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
 	"time"
 
 	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
 	"github.com/vhvplatform/go-user-service/internal/domain"
 	"github.com/vhvplatform/go-user-service/internal/service"
 	pb "github.com/vhvplatform/go-user-service/proto"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // UserServiceServer implements the gRPC user service
 type UserServiceServer struct {
 	pb.UnimplementedUserServiceServer
-	userService *service.UserService
-	logger      *logger.Logger
+	userService    *service.UserService
+	tokenService   *service.TokenService
+	roleService    *service.RoleService
+	prefService    *service.PreferencesService
+	segmentService *service.SegmentService
+	policyService  *service.PolicyService
+	exportService  *service.ExportService
+	logger         *logger.Logger
 }
 
 // NewUserServiceServer creates a new gRPC user service server
-func NewUserServiceServer(userService *service.UserService, log *logger.Logger) *UserServiceServer {
+func NewUserServiceServer(userService *service.UserService, tokenService *service.TokenService, roleService *service.RoleService, prefService *service.PreferencesService, segmentService *service.SegmentService, policyService *service.PolicyService, exportService *service.ExportService, log *logger.Logger) *UserServiceServer {
 	return &UserServiceServer{
-		userService: userService,
-		logger:      log,
+		userService:    userService,
+		tokenService:   tokenService,
+		roleService:    roleService,
+		prefService:    prefService,
+		segmentService: segmentService,
+		policyService:  policyService,
+		exportService:  exportService,
+		logger:         log,
 	}
 }
 
-// Note: gRPC methods are commented out until protobuf code is generated
-// Run `make proto` to generate the protobuf code, then uncomment the methods below
-
 // GetUser retrieves a user by ID
 func (s *UserServiceServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
 	userProfile, err := s.userService.GetUser(ctx, req.UserId, req.TenantId)
 	if err != nil {
 		s.logger.Error("Failed to get user", zap.Error(err))
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &pb.GetUserResponse{
@@ -42,15 +57,135 @@ func (s *UserServiceServer) GetUser(ctx context.Context, req *pb.GetUserRequest)
 	}, nil
 }
 
+// GetUserByIdentifier retrieves a user by email, username, phone or document number
+// The password hash is only included for the credential-checking platform caller
+func (s *UserServiceServer) GetUserByIdentifier(ctx context.Context, req *pb.GetUserByIdentifierRequest) (*pb.GetUserByIdentifierResponse, error) {
+	user, tenants, err := s.userService.GetUserByIdentifier(ctx, req.Identifier, req.IdentifierType, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to get user by identifier", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoUser := s.toProtoGlobalUser(user)
+	protoUser.PasswordHash = user.PasswordHash
+	if req.TenantId != "" && len(tenants) > 0 {
+		protoUser.TenantId = tenants[0].TenantID
+		protoUser.FirstName = tenants[0].FirstName
+		protoUser.LastName = tenants[0].LastName
+	}
+
+	return &pb.GetUserByIdentifierResponse{
+		User:    protoUser,
+		Tenants: s.toProtoUserTenants(tenants),
+	}, nil
+}
+
+// GetUserTenants lists every tenant a user belongs to
+func (s *UserServiceServer) GetUserTenants(ctx context.Context, req *pb.GetUserTenantsRequest) (*pb.GetUserTenantsResponse, error) {
+	tenants, err := s.userService.GetUserTenants(ctx, req.UserId)
+	if err != nil {
+		s.logger.Error("Failed to get user tenants", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetUserTenantsResponse{
+		Tenants: s.toProtoUserTenants(tenants),
+	}, nil
+}
+
+// AddUserToTenant links an existing user to a tenant
+func (s *UserServiceServer) AddUserToTenant(ctx context.Context, req *pb.AddUserToTenantRequest) (*pb.AddUserToTenantResponse, error) {
+	userTenant, err := s.userService.AddUserToTenant(ctx, req.UserId, req.TenantId, req.Roles, req.ExpiresAt)
+	if err != nil {
+		s.logger.Error("Failed to add user to tenant", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.AddUserToTenantResponse{
+		Success:    true,
+		UserTenant: s.toProtoUserTenant(userTenant),
+	}, nil
+}
+
+// RemoveUserFromTenant removes a user from a tenant (soft delete)
+func (s *UserServiceServer) RemoveUserFromTenant(ctx context.Context, req *pb.RemoveUserFromTenantRequest) (*pb.RemoveUserFromTenantResponse, error) {
+	err := s.userService.RemoveUserFromTenant(ctx, req.UserId, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to remove user from tenant", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.RemoveUserFromTenantResponse{
+		Success: true,
+	}, nil
+}
+
+// CreateUser creates a user, or links an existing user, in a tenant
+func (s *UserServiceServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	createReq := &domain.CreateUserRequest{
+		Email:          req.Email,
+		TenantID:       req.TenantId,
+		Username:       req.Username,
+		DocumentNumber: req.DocumentNumber,
+		Password:       req.Password,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Phone:          req.Phone,
+		Roles:          req.Roles,
+		Status:         domain.MembershipStatus(req.Status),
+		ExpiresAt:      req.ExpiresAt,
+	}
+
+	userProfile, err := s.userService.CreateUser(ctx, createReq)
+	if err != nil {
+		s.logger.Error("Failed to create user", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.CreateUserResponse{
+		User: s.toProtoUser(userProfile),
+	}, nil
+}
+
 // ListUsers lists users for a tenant
 func (s *UserServiceServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
-	page := int(req.Page)
-	pageSize := int(req.PageSize)
+	listReq := &domain.ListUsersRequest{
+		TenantID: req.TenantId,
+		Page:     int(req.Page),
+		PageSize: int(req.PageSize),
+		Filter:   req.Filter,
+		Sort:     req.Sort,
+		Facets:   req.Facets,
+	}
+
+	facets, err := s.userService.CountUserFacets(ctx, listReq, "")
+	if err != nil {
+		s.logger.Error("Failed to count user facets", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	if req.PageToken != nil {
+		listReq.Cursor = req.GetPageToken()
+		listReq.IncludeTotal = req.IncludeTotal
+		result, err := s.userService.ListUsersAfter(ctx, listReq)
+		if err != nil {
+			s.logger.Error("Failed to list users", zap.Error(err))
+			return nil, toStatusError(err)
+		}
+		users, total := s.toProtoUserPage(result)
+		return &pb.ListUsersResponse{
+			Users:         users,
+			Total:         total,
+			PageSize:      req.PageSize,
+			NextPageToken: result.NextCursor,
+			Facets:        toProtoFacets(facets),
+		}, nil
+	}
 
-	profiles, total, err := s.userService.ListUsers(ctx, req.TenantId, page, pageSize)
+	profiles, total, err := s.userService.ListUsers(ctx, listReq)
 	if err != nil {
 		s.logger.Error("Failed to list users", zap.Error(err))
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	protoUsers := make([]*pb.User, len(profiles))
@@ -63,6 +198,7 @@ func (s *UserServiceServer) ListUsers(ctx context.Context, req *pb.ListUsersRequ
 		Total:    int32(total),
 		Page:     req.Page,
 		PageSize: req.PageSize,
+		Facets:   toProtoFacets(facets),
 	}, nil
 }
 
@@ -74,11 +210,18 @@ func (s *UserServiceServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRe
 		Phone:     req.Phone,
 		AvatarURL: req.AvatarUrl,
 	}
+	if len(req.Roles) > 0 {
+		updateReq.Roles = req.Roles
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.GetExpiresAt()
+		updateReq.ExpiresAt = &expiresAt
+	}
 
 	userProfile, err := s.userService.UpdateUser(ctx, req.UserId, req.TenantId, updateReq)
 	if err != nil {
 		s.logger.Error("Failed to update user", zap.Error(err))
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &pb.UpdateUserResponse{
@@ -91,7 +234,7 @@ func (s *UserServiceServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRe
 	err := s.userService.DeleteUser(ctx, req.UserId, req.TenantId)
 	if err != nil {
 		s.logger.Error("Failed to delete user", zap.Error(err))
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &pb.DeleteUserResponse{
@@ -99,15 +242,120 @@ func (s *UserServiceServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRe
 	}, nil
 }
 
+// ListExpiringUsers lists members whose membership expires within req.Days
+func (s *UserServiceServer) ListExpiringUsers(ctx context.Context, req *pb.ListExpiringUsersRequest) (*pb.ListExpiringUsersResponse, error) {
+	profiles, total, err := s.userService.ListExpiringUsers(ctx, req.TenantId, int(req.Days), int(req.Page), int(req.PageSize))
+	if err != nil {
+		s.logger.Error("Failed to list expiring users", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoUsers := make([]*pb.User, len(profiles))
+	for i, p := range profiles {
+		protoUsers[i] = s.toProtoUser(p)
+	}
+
+	return &pb.ListExpiringUsersResponse{
+		Users:    protoUsers,
+		Total:    int32(total),
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+// TouchMembership records that a member was seen in the tenant
+func (s *UserServiceServer) TouchMembership(ctx context.Context, req *pb.TouchMembershipRequest) (*pb.TouchMembershipResponse, error) {
+	userTenant, err := s.userService.TouchMembership(ctx, req.UserId, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to touch membership", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.TouchMembershipResponse{
+		UserTenant: s.toProtoUserTenant(userTenant),
+	}, nil
+}
+
+// ApplyInactivityPolicy warns and suspends the tenant's inactive members now
+func (s *UserServiceServer) ApplyInactivityPolicy(ctx context.Context, req *pb.ApplyInactivityPolicyRequest) (*pb.ApplyInactivityPolicyResponse, error) {
+	report, err := s.policyService.ApplyInactivityPolicy(ctx, req.TenantId, req.DryRun)
+	if err != nil {
+		s.logger.Error("Failed to apply inactivity policy", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ApplyInactivityPolicyResponse{
+		TenantId:    report.TenantID,
+		DryRun:      report.DryRun,
+		RanAt:       report.RanAt.Format(time.RFC3339),
+		WarnDays:    int32(report.WarnDays),
+		SuspendDays: int32(report.SuspendDays),
+		Warned:      toProtoInactiveMembers(report.Warned),
+		Suspended:   toProtoInactiveMembers(report.Suspended),
+	}, nil
+}
+
+// toProtoInactiveMembers converts the members of an inactivity report
+func toProtoInactiveMembers(members []domain.InactiveMember) []*pb.InactiveMember {
+	protoMembers := make([]*pb.InactiveMember, len(members))
+	for i, m := range members {
+		protoMembers[i] = &pb.InactiveMember{
+			UserId:        m.UserID,
+			InactiveSince: m.InactiveSince.Format(time.RFC3339),
+		}
+		if m.LastSeenAt != nil {
+			protoMembers[i].LastSeenAt = m.LastSeenAt.Format(time.RFC3339)
+		}
+	}
+	return protoMembers
+}
+
+// ExportUserData exports everything stored about a user, for platform administrators
+func (s *UserServiceServer) ExportUserData(ctx context.Context, req *pb.ExportUserDataRequest) (*pb.ExportUserDataResponse, error) {
+	bundle, err := s.exportService.ExportUser(ctx, req.UserId, req.Format)
+	if err != nil {
+		s.logger.Error("Failed to export user data", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ExportUserDataResponse{
+		Format:      bundle.Format,
+		ContentType: bundle.ContentType,
+		FileName:    bundle.FileName,
+		Data:        bundle.Data,
+	}, nil
+}
+
 // SearchUsers searches users by query
 func (s *UserServiceServer) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
 	page := int(req.Page)
 	pageSize := int(req.PageSize)
 
-	profiles, total, err := s.userService.SearchUsers(ctx, req.TenantId, req.Query, page, pageSize)
+	facets, err := s.userService.CountUserFacets(ctx, &domain.ListUsersRequest{TenantID: req.TenantId, Filter: req.Filter, Facets: req.Facets}, req.Query)
+	if err != nil {
+		s.logger.Error("Failed to count user facets", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	if req.PageToken != nil {
+		result, err := s.userService.SearchUsersAfter(ctx, req.TenantId, req.Query, req.Filter, req.GetPageToken(), pageSize, req.IncludeTotal)
+		if err != nil {
+			s.logger.Error("Failed to search users", zap.Error(err))
+			return nil, toStatusError(err)
+		}
+		users, total := s.toProtoUserPage(result)
+		return &pb.SearchUsersResponse{
+			Users:         users,
+			Total:         total,
+			NextPageToken: result.NextCursor,
+			Facets:        toProtoFacets(facets),
+		}, nil
+	}
+
+	profiles, total, err := s.userService.SearchUsers(ctx, req.TenantId, req.Query, req.Filter, page, pageSize)
 	if err != nil {
 		s.logger.Error("Failed to search users", zap.Error(err))
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	protoUsers := make([]*pb.User, len(profiles))
@@ -116,38 +364,695 @@ func (s *UserServiceServer) SearchUsers(ctx context.Context, req *pb.SearchUsers
 	}
 
 	return &pb.SearchUsersResponse{
-		Users: protoUsers,
-		Total: int32(total),
+		Users:  protoUsers,
+		Total:  int32(total),
+		Facets: toProtoFacets(facets),
 	}, nil
 }
 
-// VerifyToken verifies an opaque token (Mock implementation for now)
+// AutocompleteUsers suggests active members for pickers
+func (s *UserServiceServer) AutocompleteUsers(ctx context.Context, req *pb.AutocompleteUsersRequest) (*pb.AutocompleteUsersResponse, error) {
+	suggestions, err := s.userService.AutocompleteUsers(ctx, req.TenantId, req.Query, int(req.Limit))
+	if err != nil {
+		s.logger.Error("Failed to autocomplete users", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.AutocompleteUsersResponse{Suggestions: make([]*pb.MemberSuggestion, len(suggestions))}
+	for i, sg := range suggestions {
+		resp.Suggestions[i] = &pb.MemberSuggestion{
+			Id:          sg.ID,
+			DisplayName: sg.DisplayName,
+			AvatarUrl:   sg.AvatarURL,
+			Email:       sg.Email,
+		}
+	}
+	return resp, nil
+}
+
+// VerifyToken verifies an opaque token against the token store and the user's memberships
 func (s *UserServiceServer) VerifyToken(ctx context.Context, req *pb.VerifyTokenRequest) (*pb.VerifyTokenResponse, error) {
-	// TODO: Implement actual opaque token verification logic (check DB/Redis)
-	// For now, we assume if the token is "valid-token", it's valid.
-	if req.Token == "valid-token" {
-		return &pb.VerifyTokenResponse{
-			Valid:     true,
-			UserId:    "mock-user-id",
-			TenantIds: []string{req.TenantId},
-			Role:      "user",
-			Claims:    map[string]string{"scope": "read"},
-		}, nil
+	result, err := s.tokenService.VerifyToken(ctx, req.Token, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to verify token", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+	if !result.Valid {
+		return &pb.VerifyTokenResponse{Valid: false}, nil
+	}
+
+	return &pb.VerifyTokenResponse{
+		Valid:     true,
+		UserId:    result.UserID,
+		TenantIds: result.TenantIDs,
+		Role:      result.Role,
+		Claims:    result.Claims,
+	}, nil
+}
+
+// IssueToken issues a new opaque token for a user
+func (s *UserServiceServer) IssueToken(ctx context.Context, req *pb.IssueTokenRequest) (*pb.IssueTokenResponse, error) {
+	raw, token, err := s.tokenService.IssueToken(ctx, &domain.IssueTokenRequest{
+		UserID:    req.UserId,
+		TenantIDs: req.TenantIds,
+		Role:      req.Role,
+		Claims:    req.Claims,
+		TTL:       time.Duration(req.TtlSeconds) * time.Second,
+	})
+	if err != nil {
+		s.logger.Error("Failed to issue token", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.IssueTokenResponse{
+		Token:     raw,
+		ExpiresAt: token.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// RevokeToken revokes an opaque token
+func (s *UserServiceServer) RevokeToken(ctx context.Context, req *pb.RevokeTokenRequest) (*pb.RevokeTokenResponse, error) {
+	if err := s.tokenService.RevokeToken(ctx, req.Token); err != nil {
+		s.logger.Error("Failed to revoke token", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.RevokeTokenResponse{
+		Success: true,
+	}, nil
+}
+
+// CheckPermission reports whether a user may perform an action in a tenant
+func (s *UserServiceServer) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	result, err := s.userService.CheckPermission(ctx, req.UserId, req.TenantId, req.Permission)
+	if err != nil {
+		s.logger.Error("Failed to check permission", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.CheckPermissionResponse{
+		Allowed: result.Allowed,
+		Roles:   result.Roles,
+	}, nil
+}
+
+// GetMe retrieves the caller's own profile
+func (s *UserServiceServer) GetMe(ctx context.Context, req *pb.GetMeRequest) (*pb.GetMeResponse, error) {
+	userProfile, err := s.userService.GetMe(ctx, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to get own profile", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetMeResponse{
+		User: s.toProtoUser(userProfile),
+	}, nil
+}
+
+// UpdateMe updates the caller's own profile
+func (s *UserServiceServer) UpdateMe(ctx context.Context, req *pb.UpdateMeRequest) (*pb.UpdateMeResponse, error) {
+	userProfile, err := s.userService.UpdateMe(ctx, req.TenantId, &domain.UpdateMeRequest{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Phone:     req.Phone,
+		AvatarURL: req.AvatarUrl,
+	})
+	if err != nil {
+		s.logger.Error("Failed to update own profile", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.UpdateMeResponse{
+		User: s.toProtoUser(userProfile),
+	}, nil
+}
+
+// GetMyTenants lists the caller's tenant memberships
+func (s *UserServiceServer) GetMyTenants(ctx context.Context, req *pb.GetMyTenantsRequest) (*pb.GetMyTenantsResponse, error) {
+	tenants, err := s.userService.GetMyTenants(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get own tenants", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetMyTenantsResponse{
+		Tenants: s.toProtoUserTenants(tenants),
+	}, nil
+}
+
+// ListRoles lists the built-in roles and the tenant's custom roles
+func (s *UserServiceServer) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	roles, err := s.roleService.ListRoles(ctx, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to list roles", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoRoles := make([]*pb.Role, len(roles))
+	for i, r := range roles {
+		protoRoles[i] = s.toProtoRole(r)
+	}
+
+	return &pb.ListRolesResponse{
+		Roles: protoRoles,
+	}, nil
+}
+
+// GetRole retrieves a role by key
+func (s *UserServiceServer) GetRole(ctx context.Context, req *pb.GetRoleRequest) (*pb.GetRoleResponse, error) {
+	role, err := s.roleService.GetRole(ctx, req.TenantId, req.Key)
+	if err != nil {
+		s.logger.Error("Failed to get role", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetRoleResponse{
+		Role: s.toProtoRole(role),
+	}, nil
+}
+
+// CreateRole adds a custom role to a tenant's catalog
+func (s *UserServiceServer) CreateRole(ctx context.Context, req *pb.CreateRoleRequest) (*pb.CreateRoleResponse, error) {
+	role, err := s.roleService.CreateRole(ctx, req.TenantId, &domain.CreateRoleRequest{
+		Key:         req.Key,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		s.logger.Error("Failed to create role", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.CreateRoleResponse{
+		Role: s.toProtoRole(role),
+	}, nil
+}
+
+// UpdateRole updates a custom role
+func (s *UserServiceServer) UpdateRole(ctx context.Context, req *pb.UpdateRoleRequest) (*pb.UpdateRoleResponse, error) {
+	updateReq := &domain.UpdateRoleRequest{
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+	}
+	if len(req.Permissions) > 0 || req.ReplacePermissions {
+		updateReq.Permissions = append([]string{}, req.Permissions...)
+	}
+
+	role, err := s.roleService.UpdateRole(ctx, req.TenantId, req.Key, updateReq)
+	if err != nil {
+		s.logger.Error("Failed to update role", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.UpdateRoleResponse{
+		Role: s.toProtoRole(role),
+	}, nil
+}
+
+// DeleteRole deletes a custom role that is no longer assigned
+func (s *UserServiceServer) DeleteRole(ctx context.Context, req *pb.DeleteRoleRequest) (*pb.DeleteRoleResponse, error) {
+	if err := s.roleService.DeleteRole(ctx, req.TenantId, req.Key); err != nil {
+		s.logger.Error("Failed to delete role", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.DeleteRoleResponse{
+		Success: true,
+	}, nil
+}
+
+// ChangeMembershipStatus moves a tenant membership to another lifecycle status
+func (s *UserServiceServer) ChangeMembershipStatus(ctx context.Context, req *pb.ChangeMembershipStatusRequest) (*pb.ChangeMembershipStatusResponse, error) {
+	userTenant, err := s.userService.ChangeMembershipStatus(ctx, req.UserId, req.TenantId, domain.MembershipStatus(req.Status), req.Reason)
+	if err != nil {
+		s.logger.Error("Failed to change membership status", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ChangeMembershipStatusResponse{
+		UserTenant: s.toProtoUserTenant(userTenant),
+	}, nil
+}
+
+// TagUsers adds and removes tags on the tenant memberships of several users
+func (s *UserServiceServer) TagUsers(ctx context.Context, req *pb.TagUsersRequest) (*pb.TagUsersResponse, error) {
+	result, err := s.userService.BulkTagUsers(ctx, req.TenantId, &domain.BulkTagRequest{
+		UserIDs: req.UserIds,
+		Add:     req.Add,
+		Remove:  req.Remove,
+	})
+	if err != nil {
+		s.logger.Error("Failed to tag users", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.TagUsersResponse{
+		Matched: result.Matched,
+	}, nil
+}
+
+// ListTags returns the tenant's tag inventory
+func (s *UserServiceServer) ListTags(ctx context.Context, req *pb.ListTagsRequest) (*pb.ListTagsResponse, error) {
+	counts, err := s.userService.ListTags(ctx, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to list tags", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.ListTagsResponse{Tags: make([]*pb.TagCount, len(counts))}
+	for i, c := range counts {
+		resp.Tags[i] = &pb.TagCount{Tag: c.Tag, Count: c.Count}
+	}
+	return resp, nil
+}
+
+// EvaluateSegment lists a page of the members currently matching a segment
+func (s *UserServiceServer) EvaluateSegment(ctx context.Context, req *pb.EvaluateSegmentRequest) (*pb.EvaluateSegmentResponse, error) {
+	result, err := s.segmentService.EvaluateSegment(ctx, req.TenantId, req.Key, req.PageToken, int(req.PageSize), req.IncludeTotal)
+	if err != nil {
+		s.logger.Error("Failed to evaluate segment", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	users, total := s.toProtoUserPage(result)
+	return &pb.EvaluateSegmentResponse{
+		Users:         users,
+		Total:         total,
+		NextPageToken: result.NextCursor,
+	}, nil
+}
+
+// CountSegment counts the members currently matching a segment
+func (s *UserServiceServer) CountSegment(ctx context.Context, req *pb.CountSegmentRequest) (*pb.CountSegmentResponse, error) {
+	count, err := s.segmentService.CountSegment(ctx, req.TenantId, req.Key)
+	if err != nil {
+		s.logger.Error("Failed to count segment", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.CountSegmentResponse{
+		Count: count,
+	}, nil
+}
+
+// CheckSegmentMembership reports whether a user currently matches a segment
+func (s *UserServiceServer) CheckSegmentMembership(ctx context.Context, req *pb.CheckSegmentMembershipRequest) (*pb.CheckSegmentMembershipResponse, error) {
+	result, err := s.segmentService.CheckSegmentMembership(ctx, req.TenantId, req.Key, req.UserId)
+	if err != nil {
+		s.logger.Error("Failed to check segment membership", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.CheckSegmentMembershipResponse{
+		Member: result.Member,
+	}, nil
+}
+
+// GetPreferences retrieves a user's effective preferences in a tenant
+func (s *UserServiceServer) GetPreferences(ctx context.Context, req *pb.GetPreferencesRequest) (*pb.GetPreferencesResponse, error) {
+	prefs, err := s.prefService.GetPreferences(ctx, req.UserId, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to get preferences", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoPrefs, err := s.toProtoPreferences(prefs)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetPreferencesResponse{
+		Preferences: protoPrefs,
+	}, nil
+}
+
+// SetPreferences replaces a user's preferences in a tenant
+func (s *UserServiceServer) SetPreferences(ctx context.Context, req *pb.SetPreferencesRequest) (*pb.SetPreferencesResponse, error) {
+	setReq := &domain.PreferencesRequest{
+		Language: &req.Language,
+		Timezone: &req.Timezone,
+		Theme:    &req.Theme,
+	}
+	if req.Settings != nil {
+		setReq.Settings = req.Settings.AsMap()
+	}
+
+	prefs, err := s.prefService.ReplacePreferences(ctx, req.UserId, req.TenantId, setReq)
+	if err != nil {
+		s.logger.Error("Failed to set preferences", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoPrefs, err := s.toProtoPreferences(prefs)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetPreferencesResponse{
+		Preferences: protoPrefs,
+	}, nil
+}
+
+// PatchPreferences partially updates a user's preferences in a tenant
+func (s *UserServiceServer) PatchPreferences(ctx context.Context, req *pb.PatchPreferencesRequest) (*pb.PatchPreferencesResponse, error) {
+	patchReq := &domain.PreferencesRequest{
+		Language: req.Language,
+		Timezone: req.Timezone,
+		Theme:    req.Theme,
+	}
+	if req.Settings != nil {
+		patchReq.Settings = req.Settings.AsMap()
+	}
+
+	prefs, err := s.prefService.PatchPreferences(ctx, req.UserId, req.TenantId, patchReq)
+	if err != nil {
+		s.logger.Error("Failed to patch preferences", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoPrefs, err := s.toProtoPreferences(prefs)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PatchPreferencesResponse{
+		Preferences: protoPrefs,
+	}, nil
+}
+
+// GetEffectivePreferences retrieves a user's resolved preferences with the source of every value
+func (s *UserServiceServer) GetEffectivePreferences(ctx context.Context, req *pb.GetEffectivePreferencesRequest) (*pb.GetEffectivePreferencesResponse, error) {
+	effective, err := s.prefService.GetEffectivePreferences(ctx, req.UserId, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to get effective preferences", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoPrefs, err := s.toProtoPreferences(effective.Preferences)
+	if err != nil {
+		return nil, err
 	}
-	return &pb.VerifyTokenResponse{Valid: false}, nil
+	return &pb.GetEffectivePreferencesResponse{
+		Preferences: protoPrefs,
+		Sources:     effective.Sources,
+		Locked:      effective.Locked,
+	}, nil
+}
+
+// GetTenantPreferences retrieves a tenant's preference defaults and locks
+func (s *UserServiceServer) GetTenantPreferences(ctx context.Context, req *pb.GetTenantPreferencesRequest) (*pb.GetTenantPreferencesResponse, error) {
+	prefs, err := s.prefService.GetTenantDefaults(ctx, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to get tenant preferences", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoPrefs, err := s.toProtoTenantPreferences(prefs)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetTenantPreferencesResponse{
+		Preferences: protoPrefs,
+	}, nil
+}
+
+// SetTenantPreferences replaces a tenant's preference defaults and locks
+func (s *UserServiceServer) SetTenantPreferences(ctx context.Context, req *pb.SetTenantPreferencesRequest) (*pb.SetTenantPreferencesResponse, error) {
+	setReq := &domain.TenantPreferencesRequest{
+		Language: req.Language,
+		Timezone: req.Timezone,
+		Theme:    req.Theme,
+		Locked:   req.Locked,
+	}
+	if req.Settings != nil {
+		setReq.Settings = req.Settings.AsMap()
+	}
+
+	prefs, err := s.prefService.ReplaceTenantDefaults(ctx, req.TenantId, setReq)
+	if err != nil {
+		s.logger.Error("Failed to set tenant preferences", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoPrefs, err := s.toProtoTenantPreferences(prefs)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetTenantPreferencesResponse{
+		Preferences: protoPrefs,
+	}, nil
+}
+
+// ListSettingsSchemas lists the platform and tenant settings schemas
+func (s *UserServiceServer) ListSettingsSchemas(ctx context.Context, req *pb.ListSettingsSchemasRequest) (*pb.ListSettingsSchemasResponse, error) {
+	schemas, err := s.prefService.ListSettingsSchemas(ctx, req.TenantId)
+	if err != nil {
+		s.logger.Error("Failed to list settings schemas", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoSchemas := make([]*pb.SettingsSchema, len(schemas))
+	for i, schema := range schemas {
+		if protoSchemas[i], err = s.toProtoSettingsSchema(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	return &pb.ListSettingsSchemasResponse{
+		Schemas: protoSchemas,
+	}, nil
+}
+
+// GetSettingsSchema retrieves the schema that applies to a settings namespace
+func (s *UserServiceServer) GetSettingsSchema(ctx context.Context, req *pb.GetSettingsSchemaRequest) (*pb.GetSettingsSchemaResponse, error) {
+	schema, err := s.prefService.GetSettingsSchema(ctx, req.TenantId, req.Namespace)
+	if err != nil {
+		s.logger.Error("Failed to get settings schema", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoSchema, err := s.toProtoSettingsSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetSettingsSchemaResponse{
+		Schema: protoSchema,
+	}, nil
+}
+
+// PutSettingsSchema registers or replaces the schema of a settings namespace
+func (s *UserServiceServer) PutSettingsSchema(ctx context.Context, req *pb.PutSettingsSchemaRequest) (*pb.PutSettingsSchemaResponse, error) {
+	if req.Schema == nil {
+		return nil, status.Error(codes.InvalidArgument, "schema is required")
+	}
+
+	schema, err := s.prefService.PutSettingsSchema(ctx, req.TenantId, req.Level, req.Namespace, req.Schema.AsMap())
+	if err != nil {
+		s.logger.Error("Failed to put settings schema", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	protoSchema, err := s.toProtoSettingsSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PutSettingsSchemaResponse{
+		Schema: protoSchema,
+	}, nil
+}
+
+// DeleteSettingsSchema removes the schema of a settings namespace
+func (s *UserServiceServer) DeleteSettingsSchema(ctx context.Context, req *pb.DeleteSettingsSchemaRequest) (*pb.DeleteSettingsSchemaResponse, error) {
+	if err := s.prefService.DeleteSettingsSchema(ctx, req.TenantId, req.Level, req.Namespace); err != nil {
+		s.logger.Error("Failed to delete settings schema", zap.Error(err))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.DeleteSettingsSchemaResponse{
+		Success: true,
+	}, nil
+}
+
+// toProtoUserPage converts a cursor page of users; total is 0 unless it was counted
+func (s *UserServiceServer) toProtoUserPage(page *domain.UserPage) ([]*pb.User, int32) {
+	protoUsers := make([]*pb.User, len(page.Users))
+	for i, p := range page.Users {
+		protoUsers[i] = s.toProtoUser(p)
+	}
+	var total int32
+	if page.Total != nil {
+		total = int32(*page.Total)
+	}
+	return protoUsers, total
+}
+
+// toProtoFacets converts facet counts, ordered by facet name
+func toProtoFacets(facets domain.UserFacets) []*pb.Facet {
+	names := make([]string, 0, len(facets))
+	for name := range facets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	protoFacets := make([]*pb.Facet, len(names))
+	for i, name := range names {
+		values := make([]*pb.FacetValue, len(facets[name]))
+		for j, c := range facets[name] {
+			values[j] = &pb.FacetValue{Value: c.Value, Count: c.Count}
+		}
+		protoFacets[i] = &pb.Facet{Name: name, Values: values}
+	}
+	return protoFacets
 }
 
 func (s *UserServiceServer) toProtoUser(p *domain.UserProfile) *pb.User {
+	protoUser := &pb.User{
+		Id:             p.User.ID.Hex(),
+		Email:          p.User.Email,
+		Username:       p.User.Username,
+		DocumentNumber: p.User.DocumentNumber,
+		TenantId:       p.UserTenant.TenantID,
+		FirstName:      p.UserTenant.FirstName,
+		LastName:       p.UserTenant.LastName,
+		Phone:          p.User.Phone,
+		AvatarUrl:      p.User.AvatarURL,
+		IsActive:       p.User.IsActive && p.UserTenant.IsActive,
+		Status:         string(p.UserTenant.CurrentStatus()),
+		CreatedAt:      p.User.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      p.User.UpdatedAt.Format(time.RFC3339),
+	}
+	if p.UserTenant.ExpiresAt != nil {
+		protoUser.ExpiresAt = p.UserTenant.ExpiresAt.Format(time.RFC3339)
+	}
+	if p.UserTenant.LastSeenAt != nil {
+		protoUser.LastSeenAt = p.UserTenant.LastSeenAt.Format(time.RFC3339)
+	}
+	return protoUser
+}
+
+// toProtoGlobalUser converts a user without tenant context (no names, no tenant ID)
+func (s *UserServiceServer) toProtoGlobalUser(u *domain.User) *pb.User {
 	return &pb.User{
-		Id:        p.User.ID.Hex(),
-		Email:     p.User.Email,
-		TenantId:  p.UserTenant.TenantID,
-		FirstName: p.UserTenant.FirstName,
-		LastName:  p.UserTenant.LastName,
-		Phone:     p.User.Phone,
-		AvatarUrl: p.User.AvatarURL,
-		IsActive:  p.User.IsActive && p.UserTenant.IsActive,
-		CreatedAt: p.User.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: p.User.UpdatedAt.Format(time.RFC3339),
+		Id:             u.ID.Hex(),
+		Email:          u.Email,
+		Username:       u.Username,
+		DocumentNumber: u.DocumentNumber,
+		Phone:          u.Phone,
+		AvatarUrl:      u.AvatarURL,
+		IsActive:       u.IsActive,
+		CreatedAt:      u.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      u.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (s *UserServiceServer) toProtoUserTenant(ut *domain.UserTenant) *pb.UserTenant {
+	protoTenant := &pb.UserTenant{
+		UserId:   ut.UserID.Hex(),
+		TenantId: ut.TenantID,
+		Roles:    ut.Roles,
+		IsActive: ut.IsActive,
+		JoinedAt: ut.JoinedAt.Format(time.RFC3339),
+		Tags:     ut.Tags,
+		Status:   string(ut.CurrentStatus()),
+	}
+	if !ut.StatusChangedAt.IsZero() {
+		protoTenant.StatusChangedAt = ut.StatusChangedAt.Format(time.RFC3339)
+	}
+	if ut.ExpiresAt != nil {
+		protoTenant.ExpiresAt = ut.ExpiresAt.Format(time.RFC3339)
+	}
+	if ut.LastSeenAt != nil {
+		protoTenant.LastSeenAt = ut.LastSeenAt.Format(time.RFC3339)
+	}
+	return protoTenant
+}
+
+func (s *UserServiceServer) toProtoUserTenants(tenants []*domain.UserTenant) []*pb.UserTenant {
+	protoTenants := make([]*pb.UserTenant, len(tenants))
+	for i, ut := range tenants {
+		protoTenants[i] = s.toProtoUserTenant(ut)
+	}
+	return protoTenants
+}
+
+func (s *UserServiceServer) toProtoRole(r *domain.Role) *pb.Role {
+	role := &pb.Role{
+		Key:         r.Key,
+		TenantId:    r.TenantID,
+		DisplayName: r.DisplayName,
+		Description: r.Description,
+		Permissions: r.Permissions,
+		Builtin:     auth.IsBuiltinRole(r.Key),
+	}
+	if !r.CreatedAt.IsZero() {
+		role.CreatedAt = r.CreatedAt.Format(time.RFC3339)
+		role.UpdatedAt = r.UpdatedAt.Format(time.RFC3339)
 	}
+	return role
+}
+
+func (s *UserServiceServer) toProtoPreferences(p *domain.UserPreferences) (*pb.Preferences, error) {
+	settings, err := structpb.NewStruct(p.Settings)
+	if err != nil {
+		s.logger.Error("Failed to encode preference settings", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to encode preference settings")
+	}
+
+	prefs := &pb.Preferences{
+		UserId:   p.UserID,
+		TenantId: p.TenantID,
+		Language: p.Language,
+		Timezone: p.Timezone,
+		Theme:    p.Theme,
+		Settings: settings,
+	}
+	if !p.UpdatedAt.IsZero() {
+		prefs.UpdatedAt = p.UpdatedAt.Format(time.RFC3339)
+	}
+	return prefs, nil
+}
+
+func (s *UserServiceServer) toProtoTenantPreferences(p *domain.TenantPreferences) (*pb.TenantPreferences, error) {
+	settings, err := structpb.NewStruct(p.Settings)
+	if err != nil {
+		s.logger.Error("Failed to encode preference settings", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to encode preference settings")
+	}
+
+	prefs := &pb.TenantPreferences{
+		TenantId: p.TenantID,
+		Language: p.Language,
+		Timezone: p.Timezone,
+		Theme:    p.Theme,
+		Settings: settings,
+		Locked:   p.Locked,
+	}
+	if !p.UpdatedAt.IsZero() {
+		prefs.UpdatedAt = p.UpdatedAt.Format(time.RFC3339)
+	}
+	return prefs, nil
+}
+
+func (s *UserServiceServer) toProtoSettingsSchema(schema *domain.SettingsSchema) (*pb.SettingsSchema, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(schema.Schema), &doc); err != nil {
+		s.logger.Error("Stored settings schema is not valid JSON", zap.String("namespace", schema.Namespace), zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to read settings schema")
+	}
+	protoDoc, err := structpb.NewStruct(doc)
+	if err != nil {
+		s.logger.Error("Failed to encode settings schema", zap.String("namespace", schema.Namespace), zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to read settings schema")
+	}
+
+	level := domain.SettingsSchemaLevelTenant
+	if schema.TenantID == "" {
+		level = domain.SettingsSchemaLevelPlatform
+	}
+	return &pb.SettingsSchema{
+		Namespace: schema.Namespace,
+		Level:     level,
+		TenantId:  schema.TenantID,
+		Schema:    protoDoc,
+		CreatedAt: schema.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: schema.UpdatedAt.Format(time.RFC3339),
+	}, nil
 }
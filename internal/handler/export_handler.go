@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// ExportHandler handles HTTP requests for user data exports
+type ExportHandler struct {
+	exportService *service.ExportService
+	logger        *logger.Logger
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *service.ExportService, log *logger.Logger) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		logger:        log,
+	}
+}
+
+// ExportMe godoc
+// @Summary Export my data
+// @Description Download everything stored about the authenticated user across all tenants: the global user, memberships, preferences, consents, audit log entries and tags, with a manifest. format=zip returns a ZIP archive with a JSON file per section
+// @Tags me
+// @Produce json
+// @Produce application/zip
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param format query string false "json (default) or zip"
+// @Success 200 {file} file "Export bundle"
+// @Failure 400 {object} map[string]interface{} "Invalid format"
+// @Failure 401 {object} map[string]interface{} "Not authenticated"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/me/export [get]
+func (h *ExportHandler) ExportMe(c *gin.Context) {
+	bundle, err := h.exportService.ExportMe(c.Request.Context(), c.Query("format"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bundle.FileName))
+	c.Data(http.StatusOK, bundle.ContentType, bundle.Data)
+}
+
+// respondError responds with an error
+func (h *ExportHandler) respondError(c *gin.Context, err error) {
+	appErr := errors.FromError(err)
+	h.logger.Error("Request failed",
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method),
+		zap.String("error", appErr.Message),
+	)
+	c.JSON(appErr.StatusCode, gin.H{"error": appErr})
+}
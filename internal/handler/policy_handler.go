@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/middleware"
+	"github.com/vhvplatform/go-user-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// PolicyHandler handles HTTP requests for tenant membership policies
+type PolicyHandler struct {
+	policyService *service.PolicyService
+	logger        *logger.Logger
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(policyService *service.PolicyService, log *logger.Logger) *PolicyHandler {
+	return &PolicyHandler{
+		policyService: policyService,
+		logger:        log,
+	}
+}
+
+// GetMembershipPolicy godoc
+// @Summary Get membership policy
+// @Description Get the tenant's effective membership policy, with defaults for unset fields
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Success 200 {object} map[string]interface{} "Membership policy"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/membership-policy [get]
+func (h *PolicyHandler) GetMembershipPolicy(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	policy, err := h.policyService.GetMembershipPolicy(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toMembershipPolicyResponse(policy)})
+}
+
+// SetMembershipPolicy godoc
+// @Summary Replace membership policy
+// @Description Replace the tenant's membership policy. retention_days is how long removed members can be restored before they are purged; 0 uses the service default. inactivity_warn_days and inactivity_suspend_days are how long active members can go unseen before they are warned and suspended; 0 disables the step
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param policy body domain.MembershipPolicyRequest true "Membership policy"
+// @Success 200 {object} map[string]interface{} "Membership policy"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Missing policies:manage"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/membership-policy [put]
+func (h *PolicyHandler) SetMembershipPolicy(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	var req domain.MembershipPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	policy, err := h.policyService.SetMembershipPolicy(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toMembershipPolicyResponse(policy)})
+}
+
+// ApplyInactivityPolicy godoc
+// @Summary Apply inactivity policy
+// @Description Warn and suspend the tenant's inactive members now instead of waiting for the background job. With dry_run the members are only reported
+// @Tags policies
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param dry_run query bool false "Report without changing anything"
+// @Success 200 {object} map[string]interface{} "Inactivity report"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Missing policies:manage"
+// @Failure 409 {object} map[string]interface{} "No inactivity thresholds set"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/membership-policy/inactivity/apply [post]
+func (h *PolicyHandler) ApplyInactivityPolicy(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			h.respondError(c, errors.BadRequest("dry_run must be a boolean"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	report, err := h.policyService.ApplyInactivityPolicy(c.Request.Context(), tenantID, dryRun)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// toMembershipPolicyResponse converts a policy to its response
+func toMembershipPolicyResponse(policy *domain.MembershipPolicy) domain.MembershipPolicyResponse {
+	resp := domain.MembershipPolicyResponse{
+		TenantID:              policy.TenantID,
+		RetentionDays:         policy.RetentionDays,
+		InactivityWarnDays:    policy.InactivityWarnDays,
+		InactivitySuspendDays: policy.InactivitySuspendDays,
+	}
+	if !policy.UpdatedAt.IsZero() {
+		resp.UpdatedAt = policy.UpdatedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// respondError responds with an error
+func (h *PolicyHandler) respondError(c *gin.Context, err error) {
+	appErr := errors.FromError(err)
+	h.logger.Error("Request failed",
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method),
+		zap.String("error", appErr.Message),
+	)
+	c.JSON(appErr.StatusCode, gin.H{"error": appErr})
+}
@@ -0,0 +1,412 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/middleware"
+	"github.com/vhvplatform/go-user-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// PreferencesHandler handles HTTP requests for user preferences
+// Every route is served both as /me/preferences (the caller) and
+// /{id}/preferences (any user, subject to permissions)
+type PreferencesHandler struct {
+	prefService *service.PreferencesService
+	logger      *logger.Logger
+}
+
+// NewPreferencesHandler creates a new preferences handler
+func NewPreferencesHandler(prefService *service.PreferencesService, log *logger.Logger) *PreferencesHandler {
+	return &PreferencesHandler{
+		prefService: prefService,
+		logger:      log,
+	}
+}
+
+// GetPreferences godoc
+// @Summary Get preferences
+// @Description Get a user's effective preferences in the tenant; unset values are filled with defaults
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "Preferences"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/me/preferences [get]
+// @Router /api/v1/users/{id}/preferences [get]
+func (h *PreferencesHandler) GetPreferences(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	prefs, err := h.prefService.GetPreferences(c.Request.Context(), c.Param("id"), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toPreferencesResponse(prefs)})
+}
+
+// ReplacePreferences godoc
+// @Summary Replace preferences
+// @Description Replace a user's preferences in the tenant; omitted fields are reset to their defaults
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param preferences body domain.PreferencesRequest true "Preferences"
+// @Success 200 {object} map[string]interface{} "Preferences updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/me/preferences [put]
+// @Router /api/v1/users/{id}/preferences [put]
+func (h *PreferencesHandler) ReplacePreferences(c *gin.Context) {
+	var req domain.PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	prefs, err := h.prefService.ReplacePreferences(c.Request.Context(), c.Param("id"), tenantID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toPreferencesResponse(prefs)})
+}
+
+// PatchPreferences godoc
+// @Summary Update preferences
+// @Description Partially update a user's preferences in the tenant. An empty string resets a field; settings are merged as a JSON merge patch where null removes a key
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param preferences body domain.PreferencesRequest true "Preferences patch"
+// @Success 200 {object} map[string]interface{} "Preferences updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/me/preferences [patch]
+// @Router /api/v1/users/{id}/preferences [patch]
+func (h *PreferencesHandler) PatchPreferences(c *gin.Context) {
+	var req domain.PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	prefs, err := h.prefService.PatchPreferences(c.Request.Context(), c.Param("id"), tenantID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toPreferencesResponse(prefs)})
+}
+
+// GetEffectivePreferences godoc
+// @Summary Get effective preferences
+// @Description Get a user's resolved preferences with the source (platform, tenant or user) of every value and the tenant's locks
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "Effective preferences"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/me/preferences/effective [get]
+// @Router /api/v1/users/{id}/preferences/effective [get]
+func (h *PreferencesHandler) GetEffectivePreferences(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	effective, err := h.prefService.GetEffectivePreferences(c.Request.Context(), c.Param("id"), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": domain.EffectivePreferencesResponse{
+		PreferencesResponse: h.toPreferencesResponse(effective.Preferences),
+		Sources:             effective.Sources,
+		Locked:              effective.Locked,
+	}})
+}
+
+// GetTenantDefaults godoc
+// @Summary Get tenant preference defaults
+// @Description Get the tenant's preference defaults and the preferences members cannot override
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Success 200 {object} map[string]interface{} "Tenant preference defaults"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/tenant-preferences [get]
+func (h *PreferencesHandler) GetTenantDefaults(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	prefs, err := h.prefService.GetTenantDefaults(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toTenantPreferencesResponse(prefs)})
+}
+
+// ReplaceTenantDefaults godoc
+// @Summary Replace tenant preference defaults
+// @Description Replace the tenant's preference defaults and locks. Locked paths are language, timezone, theme or settings.<key>
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param preferences body domain.TenantPreferencesRequest true "Tenant preference defaults"
+// @Success 200 {object} map[string]interface{} "Tenant preference defaults updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/tenant-preferences [put]
+func (h *PreferencesHandler) ReplaceTenantDefaults(c *gin.Context) {
+	var req domain.TenantPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	prefs, err := h.prefService.ReplaceTenantDefaults(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toTenantPreferencesResponse(prefs)})
+}
+
+// ListSettingsSchemas godoc
+// @Summary List settings schemas
+// @Description List the platform settings schemas followed by the tenant's own schemas
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Success 200 {object} map[string]interface{} "List of settings schemas"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/settings-schemas [get]
+func (h *PreferencesHandler) ListSettingsSchemas(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	schemas, err := h.prefService.ListSettingsSchemas(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	responses := make([]domain.SettingsSchemaResponse, 0, len(schemas))
+	for _, schema := range schemas {
+		resp, err := h.toSettingsSchemaResponse(schema)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		responses = append(responses, resp)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// GetSettingsSchema godoc
+// @Summary Get settings schema
+// @Description Get the schema that applies to a settings namespace: the tenant's own schema, otherwise the platform schema
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param namespace path string true "Settings namespace"
+// @Success 200 {object} map[string]interface{} "Settings schema"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 404 {object} map[string]interface{} "Settings schema not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/settings-schemas/{namespace} [get]
+func (h *PreferencesHandler) GetSettingsSchema(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	schema, err := h.prefService.GetSettingsSchema(c.Request.Context(), tenantID, c.Param("namespace"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	resp, err := h.toSettingsSchemaResponse(schema)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// PutSettingsSchema godoc
+// @Summary Register settings schema
+// @Description Register or replace the JSON Schema of a settings namespace. Tenant schemas need preferences:manage; platform schemas can only be managed by platform administrators
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param namespace path string true "Settings namespace"
+// @Param level query string false "platform or tenant (default tenant)"
+// @Param schema body domain.SettingsSchemaRequest true "JSON Schema"
+// @Success 200 {object} map[string]interface{} "Settings schema registered successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid schema"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/settings-schemas/{namespace} [put]
+func (h *PreferencesHandler) PutSettingsSchema(c *gin.Context) {
+	var req domain.SettingsSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	schema, err := h.prefService.PutSettingsSchema(c.Request.Context(), tenantID, c.Query("level"), c.Param("namespace"), req.Schema)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	resp, err := h.toSettingsSchemaResponse(schema)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// DeleteSettingsSchema godoc
+// @Summary Delete settings schema
+// @Description Remove the JSON Schema of a settings namespace at the platform or tenant level
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param namespace path string true "Settings namespace"
+// @Param level query string false "platform or tenant (default tenant)"
+// @Success 200 {object} map[string]interface{} "Settings schema deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Permission denied"
+// @Failure 404 {object} map[string]interface{} "Settings schema not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/settings-schemas/{namespace} [delete]
+func (h *PreferencesHandler) DeleteSettingsSchema(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	if err := h.prefService.DeleteSettingsSchema(c.Request.Context(), tenantID, c.Query("level"), c.Param("namespace")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Settings schema deleted successfully"})
+}
+
+func (h *PreferencesHandler) toPreferencesResponse(prefs *domain.UserPreferences) domain.PreferencesResponse {
+	resp := domain.PreferencesResponse{
+		UserID:   prefs.UserID,
+		TenantID: prefs.TenantID,
+		Language: prefs.Language,
+		Timezone: prefs.Timezone,
+		Theme:    prefs.Theme,
+		Settings: prefs.Settings,
+	}
+	if !prefs.UpdatedAt.IsZero() {
+		resp.UpdatedAt = prefs.UpdatedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func (h *PreferencesHandler) toTenantPreferencesResponse(prefs *domain.TenantPreferences) domain.TenantPreferencesResponse {
+	resp := domain.TenantPreferencesResponse{
+		TenantID: prefs.TenantID,
+		Language: prefs.Language,
+		Timezone: prefs.Timezone,
+		Theme:    prefs.Theme,
+		Settings: prefs.Settings,
+		Locked:   prefs.Locked,
+	}
+	if resp.Settings == nil {
+		resp.Settings = map[string]interface{}{}
+	}
+	if resp.Locked == nil {
+		resp.Locked = []string{}
+	}
+	if !prefs.UpdatedAt.IsZero() {
+		resp.UpdatedAt = prefs.UpdatedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func (h *PreferencesHandler) toSettingsSchemaResponse(schema *domain.SettingsSchema) (domain.SettingsSchemaResponse, error) {
+	resp := domain.SettingsSchemaResponse{
+		Namespace: schema.Namespace,
+		Level:     domain.SettingsSchemaLevelTenant,
+		TenantID:  schema.TenantID,
+		CreatedAt: schema.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: schema.UpdatedAt.Format(time.RFC3339),
+	}
+	if schema.TenantID == "" {
+		resp.Level = domain.SettingsSchemaLevelPlatform
+	}
+	if err := json.Unmarshal([]byte(schema.Schema), &resp.Schema); err != nil {
+		h.logger.Error("Stored settings schema is not valid JSON", zap.String("namespace", schema.Namespace), zap.Error(err))
+		return resp, errors.Internal("Failed to read settings schema")
+	}
+	return resp, nil
+}
+
+// respondError responds with an error
+// Settings schema violations are listed per path under "details"
+func (h *PreferencesHandler) respondError(c *gin.Context, err error) {
+	appErr := errors.FromError(err)
+	h.logger.Error("Request failed",
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method),
+		zap.String("error", appErr.Message),
+	)
+
+	var settingsErr *service.SettingsValidationError
+	if stderrors.As(err, &settingsErr) {
+		c.JSON(appErr.StatusCode, gin.H{"error": gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+			"details": settingsErr.Violations,
+		}})
+		return
+	}
+	c.JSON(appErr.StatusCode, gin.H{"error": appErr})
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/middleware"
+	"github.com/vhvplatform/go-user-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// RoleHandler handles HTTP requests for the tenant role catalog
+type RoleHandler struct {
+	roleService *service.RoleService
+	logger      *logger.Logger
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService *service.RoleService, log *logger.Logger) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		logger:      log,
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the built-in roles and the tenant's custom roles
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Success 200 {object} map[string]interface{} "List of roles"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	roles, err := h.roleService.ListRoles(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	responses := make([]domain.RoleResponse, len(roles))
+	for i, r := range roles {
+		responses[i] = h.toRoleResponse(r)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// GetRole godoc
+// @Summary Get role by key
+// @Description Get a built-in or custom role by its key
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Role key"
+// @Success 200 {object} map[string]interface{} "Role details"
+// @Failure 404 {object} map[string]interface{} "Role not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/roles/{key} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	role, err := h.roleService.GetRole(c.Request.Context(), tenantID, c.Param("key"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toRoleResponse(role)})
+}
+
+// CreateRole godoc
+// @Summary Create a custom role
+// @Description Add a role with a display name, description and permissions to the tenant's catalog
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param role body domain.CreateRoleRequest true "Role creation request"
+// @Success 201 {object} map[string]interface{} "Role created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 409 {object} map[string]interface{} "Role already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req domain.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	role, err := h.roleService.CreateRole(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": h.toRoleResponse(role)})
+}
+
+// UpdateRole godoc
+// @Summary Update a custom role
+// @Description Update a custom role's display name, description or permissions
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Role key"
+// @Param role body domain.UpdateRoleRequest true "Role update request"
+// @Success 200 {object} map[string]interface{} "Role updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "Role not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/roles/{key} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req domain.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	role, err := h.roleService.UpdateRole(c.Request.Context(), tenantID, c.Param("key"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toRoleResponse(role)})
+}
+
+// DeleteRole godoc
+// @Summary Delete a custom role
+// @Description Delete a custom role that is no longer assigned to any member
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Role key"
+// @Success 200 {object} map[string]interface{} "Role deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "Role not found"
+// @Failure 409 {object} map[string]interface{} "Role is still assigned"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/roles/{key} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	if err := h.roleService.DeleteRole(c.Request.Context(), tenantID, c.Param("key")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// toRoleResponse converts a role domain model to a response
+func (h *RoleHandler) toRoleResponse(role *domain.Role) domain.RoleResponse {
+	resp := domain.RoleResponse{
+		Key:         role.Key,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		Builtin:     auth.IsBuiltinRole(role.Key),
+	}
+	if !role.CreatedAt.IsZero() {
+		resp.CreatedAt = role.CreatedAt.Format(time.RFC3339)
+		resp.UpdatedAt = role.UpdatedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// respondError responds with an error
+func (h *RoleHandler) respondError(c *gin.Context, err error) {
+	appErr := errors.FromError(err)
+	h.logger.Error("Request failed",
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method),
+		zap.String("error", appErr.Message),
+	)
+	c.JSON(appErr.StatusCode, gin.H{"error": appErr})
+}
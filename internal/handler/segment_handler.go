@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/middleware"
+	"github.com/vhvplatform/go-user-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// SegmentHandler handles HTTP requests for tenant member segments
+type SegmentHandler struct {
+	segmentService *service.SegmentService
+	logger         *logger.Logger
+}
+
+// NewSegmentHandler creates a new segment handler
+func NewSegmentHandler(segmentService *service.SegmentService, log *logger.Logger) *SegmentHandler {
+	return &SegmentHandler{
+		segmentService: segmentService,
+		logger:         log,
+	}
+}
+
+// ListSegments godoc
+// @Summary List segments
+// @Description List the tenant's saved member segments
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Success 200 {object} map[string]interface{} "List of segments"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/segments [get]
+func (h *SegmentHandler) ListSegments(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	segments, err := h.segmentService.ListSegments(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": segments})
+}
+
+// GetSegment godoc
+// @Summary Get segment by key
+// @Description Get a saved member segment by its key
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Segment key"
+// @Success 200 {object} map[string]interface{} "Segment details"
+// @Failure 404 {object} map[string]interface{} "Segment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/segments/{key} [get]
+func (h *SegmentHandler) GetSegment(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	segment, err := h.segmentService.GetSegment(c.Request.Context(), tenantID, c.Param("key"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": segment})
+}
+
+// CreateSegment godoc
+// @Summary Create a segment
+// @Description Save a named member filter, using the same filter syntax as the user listing
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param segment body domain.CreateSegmentRequest true "Segment creation request"
+// @Success 201 {object} map[string]interface{} "Segment created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or filter"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 409 {object} map[string]interface{} "Segment already exists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/segments [post]
+func (h *SegmentHandler) CreateSegment(c *gin.Context) {
+	var req domain.CreateSegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	segment, err := h.segmentService.CreateSegment(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": segment})
+}
+
+// UpdateSegment godoc
+// @Summary Update a segment
+// @Description Update a segment's name, description or filter
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Segment key"
+// @Param segment body domain.UpdateSegmentRequest true "Segment update request"
+// @Success 200 {object} map[string]interface{} "Segment updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request or filter"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "Segment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/segments/{key} [put]
+func (h *SegmentHandler) UpdateSegment(c *gin.Context) {
+	var req domain.UpdateSegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	segment, err := h.segmentService.UpdateSegment(c.Request.Context(), tenantID, c.Param("key"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": segment})
+}
+
+// DeleteSegment godoc
+// @Summary Delete a segment
+// @Description Delete a saved member segment
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Segment key"
+// @Success 200 {object} map[string]interface{} "Segment deleted successfully"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "Segment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/segments/{key} [delete]
+func (h *SegmentHandler) DeleteSegment(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	if err := h.segmentService.DeleteSegment(c.Request.Context(), tenantID, c.Param("key")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Segment deleted successfully"})
+}
+
+// EvaluateSegment godoc
+// @Summary List segment members
+// @Description List the members currently matching a segment, newest first, with cursor pagination
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Segment key"
+// @Param itemsPerPage query int false "Page size" default(20)
+// @Param cursor query string false "Cursor from next_cursor; empty for the first page"
+// @Param include_total query bool false "Count all members of the segment" default(false)
+// @Success 200 {object} map[string]interface{} "Segment members"
+// @Failure 400 {object} map[string]interface{} "Invalid cursor"
+// @Failure 404 {object} map[string]interface{} "Segment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/segments/{key}/members [get]
+func (h *SegmentHandler) EvaluateSegment(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("itemsPerPage", "20"))
+	includeTotal, _ := strconv.ParseBool(c.Query("include_total"))
+
+	result, err := h.segmentService.EvaluateSegment(c.Request.Context(), tenantID, c.Param("key"), c.Query("cursor"), pageSize, includeTotal)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toCursorUsersResponse(result, pageSize)})
+}
+
+// CountSegment godoc
+// @Summary Count segment members
+// @Description Count the members currently matching a segment
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Segment key"
+// @Success 200 {object} map[string]interface{} "Member count"
+// @Failure 404 {object} map[string]interface{} "Segment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/segments/{key}/count [get]
+func (h *SegmentHandler) CountSegment(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	key := c.Param("key")
+
+	count, err := h.segmentService.CountSegment(c.Request.Context(), tenantID, key)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"segment_key": key, "count": count}})
+}
+
+// CheckSegmentMembership godoc
+// @Summary Check segment membership
+// @Description Check whether a user currently matches a segment
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param key path string true "Segment key"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "Membership result"
+// @Failure 400 {object} map[string]interface{} "Invalid user ID"
+// @Failure 404 {object} map[string]interface{} "Segment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/segments/{key}/members/{user_id} [get]
+func (h *SegmentHandler) CheckSegmentMembership(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	result, err := h.segmentService.CheckSegmentMembership(c.Request.Context(), tenantID, c.Param("key"), c.Param("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// respondError responds with an error
+func (h *SegmentHandler) respondError(c *gin.Context, err error) {
+	appErr := errors.FromError(err)
+	h.logger.Error("Request failed",
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method),
+		zap.String("error", appErr.Message),
+	)
+	c.JSON(appErr.StatusCode, gin.H{"error": appErr})
+}
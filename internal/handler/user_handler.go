@@ -1,6 +1,11 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -38,6 +43,7 @@ func NewUserHandler(userService *service.UserService, log *logger.Logger) *UserH
 // @Success 201 {object} map[string]interface{} "User created successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request body"
 // @Failure 409 {object} map[string]interface{} "User already exists"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -56,7 +62,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"data": h.toUserResponse(userProfile)})
+	c.JSON(http.StatusCreated, gin.H{"data": toUserResponse(userProfile)})
 }
 
 // GetUser godoc
@@ -82,7 +88,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": h.toUserResponse(userProfile)})
+	c.JSON(http.StatusOK, gin.H{"data": toUserResponse(userProfile)})
 }
 
 // ListUsers godoc
@@ -94,6 +100,11 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // @Param X-Tenant-ID header string true "Tenant ID"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
+// @Param filter query string false "Comma separated clauses: isActive:, role:, tag:, joinedAt>=, joinedAt<, createdAt>=, createdAt<, emailDomain:, hasPhone:"
+// @Param sort query string false "name, email, joinedAt or createdAt; prefix with '-' for descending" default(-joinedAt)
+// @Param cursor query string false "Cursor from next_cursor; present (even empty) selects cursor pagination"
+// @Param include_total query bool false "Count all matching users in cursor mode" default(false)
+// @Param facets query string false "Facets to count over the filtered users: role, isActive, tag, joinedMonth, emailDomain"
 // @Success 200 {object} map[string]interface{} "List of users with pagination"
 // @Failure 400 {object} map[string]interface{} "Invalid parameters"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -104,7 +115,36 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("itemsPerPage", "20"))
 
-	profiles, total, err := h.userService.ListUsers(c.Request.Context(), tenantID, page, pageSize)
+	req := &domain.ListUsersRequest{
+		TenantID: tenantID,
+		Page:     page,
+		PageSize: pageSize,
+		Filter:   c.Query("filter"),
+		Sort:     c.Query("sort"),
+		Facets:   c.Query("facets"),
+	}
+
+	facets, err := h.userService.CountUserFacets(c.Request.Context(), req, "")
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		req.Cursor = cursor
+		req.IncludeTotal, _ = strconv.ParseBool(c.Query("include_total"))
+		result, err := h.userService.ListUsersAfter(c.Request.Context(), req)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		resp := toCursorUsersResponse(result, pageSize)
+		resp.Facets = facets
+		c.JSON(http.StatusOK, gin.H{"data": resp})
+		return
+	}
+
+	profiles, total, err := h.userService.ListUsers(c.Request.Context(), req)
 	if err != nil {
 		h.respondError(c, err)
 		return
@@ -112,7 +152,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 
 	userResponses := make([]domain.UserResponse, len(profiles))
 	for i, p := range profiles {
-		userResponses[i] = h.toUserResponse(p)
+		userResponses[i] = toUserResponse(p)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -121,6 +161,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 			Total:    total,
 			Page:     page,
 			PageSize: pageSize,
+			Facets:   facets,
 		},
 	})
 }
@@ -135,6 +176,10 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 // @Param q query string true "Search query"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
+// @Param cursor query string false "Cursor from next_cursor; present (even empty) selects cursor pagination"
+// @Param include_total query bool false "Count all matching users in cursor mode" default(false)
+// @Param filter query string false "Filter clauses as in List Users, e.g. tag:vip,isActive:true"
+// @Param facets query string false "Facets to count over the matching users: role, isActive, tag, joinedMonth, emailDomain"
 // @Success 200 {object} map[string]interface{} "Search results with pagination"
 // @Failure 400 {object} map[string]interface{} "Invalid parameters"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -142,6 +187,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 func (h *UserHandler) SearchUsers(c *gin.Context) {
 	tenantID := middleware.MustGetTenantID(c)
 	query := c.Query("q")
+	filter := c.Query("filter")
 
 	if query == "" {
 		h.respondError(c, errors.BadRequest("Query parameter 'q' is required"))
@@ -151,7 +197,72 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("itemsPerPage", "20"))
 
-	profiles, total, err := h.userService.SearchUsers(c.Request.Context(), tenantID, query, page, pageSize)
+	facets, err := h.userService.CountUserFacets(c.Request.Context(), &domain.ListUsersRequest{TenantID: tenantID, Filter: filter, Facets: c.Query("facets")}, query)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		includeTotal, _ := strconv.ParseBool(c.Query("include_total"))
+		result, err := h.userService.SearchUsersAfter(c.Request.Context(), tenantID, query, filter, cursor, pageSize, includeTotal)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		resp := toCursorUsersResponse(result, pageSize)
+		resp.Facets = facets
+		c.JSON(http.StatusOK, gin.H{"data": resp})
+		return
+	}
+
+	profiles, total, err := h.userService.SearchUsers(c.Request.Context(), tenantID, query, filter, page, pageSize)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	userResponses := make([]domain.UserResponse, len(profiles))
+	for i, p := range profiles {
+		userResponses[i] = toUserResponse(p)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": domain.ListUsersResponse{
+			Users:    userResponses,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+			Facets:   facets,
+		},
+	})
+}
+
+// ListExpiringUsers godoc
+// @Summary List expiring members
+// @Description List members whose membership expires within the next days, soonest first. Requires users:update:any
+// @Tags users
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param days query int false "Days ahead, 1 to 365" default(30)
+// @Param page query int false "Page number" default(1)
+// @Param itemsPerPage query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{} "Expiring members with pagination"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 403 {object} map[string]interface{} "Missing users:update:any"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/expiring [get]
+func (h *UserHandler) ListExpiringUsers(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		h.respondError(c, errors.BadRequest("Query parameter 'days' must be a number"))
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("itemsPerPage", "20"))
+
+	profiles, total, err := h.userService.ListExpiringUsers(c.Request.Context(), tenantID, days, page, pageSize)
 	if err != nil {
 		h.respondError(c, err)
 		return
@@ -159,7 +270,7 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 
 	userResponses := make([]domain.UserResponse, len(profiles))
 	for i, p := range profiles {
-		userResponses[i] = h.toUserResponse(p)
+		userResponses[i] = toUserResponse(p)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -172,9 +283,34 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 	})
 }
 
+// AutocompleteUsers godoc
+// @Summary Autocomplete users
+// @Description Suggest active members whose names or email start with the query, for member pickers
+// @Tags users
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param q query string true "Prefix typed so far; one character is enough"
+// @Param limit query int false "Maximum suggestions" default(8)
+// @Success 200 {object} map[string]interface{} "Suggestions"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/autocomplete [get]
+func (h *UserHandler) AutocompleteUsers(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	suggestions, err := h.userService.AutocompleteUsers(c.Request.Context(), tenantID, c.Query("q"), limit)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
+}
+
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update user information within a tenant
+// @Description Update user information within a tenant. expires_at sets when the membership expires; "" removes the expiry
 // @Tags users
 // @Accept json
 // @Produce json
@@ -184,6 +320,7 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 // @Success 200 {object} map[string]interface{} "User updated successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
 // @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
@@ -202,7 +339,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": h.toUserResponse(userProfile)})
+	c.JSON(http.StatusOK, gin.H{"data": toUserResponse(userProfile)})
 }
 
 // DeleteUser godoc
@@ -216,6 +353,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Success 200 {object} map[string]interface{} "User deleted successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid user ID"
 // @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
@@ -230,20 +368,565 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
+// selfEditableFields are the profile fields a user may change through PATCH /me
+var selfEditableFields = map[string]bool{
+	"first_name": true,
+	"last_name":  true,
+	"phone":      true,
+	"avatar_url": true,
+}
+
+// adminOnlyFields are profile fields only administrators may change
+var adminOnlyFields = map[string]bool{
+	"roles":           true,
+	"is_active":       true,
+	"email":           true,
+	"username":        true,
+	"document_number": true,
+	"tenant_id":       true,
+}
+
+// GetMe godoc
+// @Summary Get my profile
+// @Description Get the authenticated user's profile in the current tenant
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Success 200 {object} map[string]interface{} "User details"
+// @Failure 401 {object} map[string]interface{} "Not authenticated"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	userProfile, err := h.userService.GetMe(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toUserResponse(userProfile)})
+}
+
+// UpdateMe godoc
+// @Summary Update my profile
+// @Description Update the authenticated user's own names, phone and avatar. Admin-only fields such as roles and is_active are rejected
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param user body domain.UpdateMeRequest true "Profile update request"
+// @Success 200 {object} map[string]interface{} "Profile updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Field can only be changed by an administrator"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/me [patch]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var fields map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+	for name := range fields {
+		if adminOnlyFields[name] {
+			h.respondError(c, errors.Forbidden(fmt.Sprintf("Field %q can only be changed by an administrator", name)))
+			return
+		}
+		if !selfEditableFields[name] {
+			h.respondError(c, errors.BadRequest(fmt.Sprintf("Unknown field %q", name)))
+			return
+		}
+	}
+
+	var req domain.UpdateMeRequest
+	if err := remarshal(fields, &req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	userProfile, err := h.userService.UpdateMe(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toUserResponse(userProfile)})
+}
+
+// GetMyTenants godoc
+// @Summary List my tenant memberships
+// @Description List every tenant the authenticated user belongs to
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Success 200 {object} map[string]interface{} "List of memberships"
+// @Failure 401 {object} map[string]interface{} "Not authenticated"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/me/tenants [get]
+func (h *UserHandler) GetMyTenants(c *gin.Context) {
+	tenants, err := h.userService.GetMyTenants(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	responses := make([]domain.UserTenantResponse, len(tenants))
+	for i, ut := range tenants {
+		responses[i] = h.toUserTenantResponse(ut)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// GetUserTenants godoc
+// @Summary List a user's tenant memberships
+// @Description List every tenant membership of a user with roles, join date and status
+// @Tags memberships
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "List of memberships"
+// @Failure 400 {object} map[string]interface{} "Invalid user ID"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/tenants [get]
+func (h *UserHandler) GetUserTenants(c *gin.Context) {
+	tenants, err := h.userService.GetUserTenants(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	responses := make([]domain.UserTenantResponse, len(tenants))
+	for i, ut := range tenants {
+		responses[i] = h.toUserTenantResponse(ut)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// AddUserToTenant godoc
+// @Summary Add a user to a tenant
+// @Description Add an existing global user to a tenant with roles and an optional expires_at; a removed membership is reactivated
+// @Tags memberships
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param membership body domain.AddUserToTenantRequest true "Membership request"
+// @Success 201 {object} map[string]interface{} "Membership created"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 409 {object} map[string]interface{} "User already in tenant"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/tenants [post]
+func (h *UserHandler) AddUserToTenant(c *gin.Context) {
+	var req domain.AddUserToTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	// Without an explicit tenant the user is added to the caller's tenant
+	if req.TenantID == "" {
+		req.TenantID = middleware.MustGetTenantID(c)
+	}
+
+	userTenant, err := h.userService.AddUserToTenant(c.Request.Context(), c.Param("id"), req.TenantID, req.Roles, req.ExpiresAt)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": h.toUserTenantResponse(userTenant)})
+}
+
+// UpdateUserTenant godoc
+// @Summary Change a member's roles
+// @Description Replace a user's roles in a tenant
+// @Tags memberships
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param tenant_id path string true "Tenant ID of the membership"
+// @Param membership body domain.UpdateUserTenantRequest true "New roles"
+// @Success 200 {object} map[string]interface{} "Membership updated"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "Membership not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/tenants/{tenant_id} [put]
+func (h *UserHandler) UpdateUserTenant(c *gin.Context) {
+	var req domain.UpdateUserTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	userTenant, err := h.userService.UpdateUserTenantRoles(c.Request.Context(), c.Param("id"), c.Param("tenant_id"), req.Roles)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toUserTenantResponse(userTenant)})
+}
+
+// RemoveUserFromTenant godoc
+// @Summary Remove a user from a tenant
+// @Description Deactivate a user's membership in a tenant (soft delete)
+// @Tags memberships
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param tenant_id path string true "Tenant ID of the membership"
+// @Success 200 {object} map[string]interface{} "Membership removed"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "Membership not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/tenants/{tenant_id} [delete]
+func (h *UserHandler) RemoveUserFromTenant(c *gin.Context) {
+	if err := h.userService.RemoveUserFromTenant(c.Request.Context(), c.Param("id"), c.Param("tenant_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User removed from tenant successfully"})
+}
+
+// ChangeMembershipStatus godoc
+// @Summary Change a member's status
+// @Description Move a user's membership in the current tenant to another status (invited, pending, active, suspended, deactivated or removed), recording the reason
+// @Tags memberships
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param status body domain.MembershipStatusRequest true "New status and reason"
+// @Success 200 {object} map[string]interface{} "Membership with its new status"
+// @Failure 400 {object} map[string]interface{} "Invalid status"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "User not found in this tenant"
+// @Failure 409 {object} map[string]interface{} "Transition not allowed from the current status"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/status [put]
+func (h *UserHandler) ChangeMembershipStatus(c *gin.Context) {
+	var req domain.MembershipStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	userTenant, err := h.userService.ChangeMembershipStatus(c.Request.Context(), c.Param("id"), tenantID, req.Status, req.Reason)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toUserTenantResponse(userTenant)})
+}
+
+// SuspendUser godoc
+// @Summary Suspend a member
+// @Description Suspend an active user's membership in the current tenant. Suspended members cannot sign in to or act in the tenant
+// @Tags memberships
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param reason body domain.StatusReasonRequest false "Reason for the suspension"
+// @Success 200 {object} map[string]interface{} "Suspended membership"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "User not found in this tenant"
+// @Failure 409 {object} map[string]interface{} "Membership is not active"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/suspend [post]
+func (h *UserHandler) SuspendUser(c *gin.Context) {
+	h.changeStatus(c, h.userService.SuspendUser)
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate a member
+// @Description Make a suspended or deactivated user's membership in the current tenant active again
+// @Tags memberships
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param reason body domain.StatusReasonRequest false "Reason for the reactivation"
+// @Success 200 {object} map[string]interface{} "Reactivated membership"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "User not found in this tenant"
+// @Failure 409 {object} map[string]interface{} "Membership is not suspended or deactivated"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/reactivate [post]
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	h.changeStatus(c, h.userService.ReactivateUser)
+}
+
+// RestoreUser godoc
+// @Summary Restore a removed member
+// @Description Bring a removed user back into the current tenant with their previous roles and tags
+// @Tags memberships
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param reason body domain.StatusReasonRequest false "Reason for the restore"
+// @Success 200 {object} map[string]interface{} "Restored membership"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "User not found in this tenant"
+// @Failure 409 {object} map[string]interface{} "Membership is not removed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	h.changeStatus(c, h.userService.RestoreUser)
+}
+
+// TouchMembership godoc
+// @Summary Record member activity
+// @Description Record that the user was seen in the current tenant now, for the tenant's inactivity policy. Members can touch their own membership; anyone else needs a platform token
+// @Tags memberships
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "Touched membership"
+// @Failure 403 {object} map[string]interface{} "Not the user or a platform service"
+// @Failure 404 {object} map[string]interface{} "User not found in this tenant"
+// @Failure 409 {object} map[string]interface{} "Membership is not active"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/touch [post]
+func (h *UserHandler) TouchMembership(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	userTenant, err := h.userService.TouchMembership(c.Request.Context(), c.Param("id"), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toUserTenantResponse(userTenant)})
+}
+
+// changeStatus runs a status change that takes an optional reason in the body
+func (h *UserHandler) changeStatus(c *gin.Context, change func(ctx context.Context, userID, tenantID, reason string) (*domain.UserTenant, error)) {
+	var req domain.StatusReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !stderrors.Is(err, io.EOF) {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	userTenant, err := change(c.Request.Context(), c.Param("id"), tenantID, req.Reason)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toUserTenantResponse(userTenant)})
+}
+
+// AddUserTags godoc
+// @Summary Tag a member
+// @Description Add tags to a user's membership in the current tenant, keeping the tags it already carries
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param tags body domain.TagsRequest true "Tags to add"
+// @Success 200 {object} map[string]interface{} "Membership with its tags"
+// @Failure 400 {object} map[string]interface{} "Invalid tags"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "User not found in this tenant"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/tags [post]
+func (h *UserHandler) AddUserTags(c *gin.Context) {
+	var req domain.TagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	userTenant, err := h.userService.AddUserTags(c.Request.Context(), c.Param("id"), tenantID, req.Tags)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toUserTenantResponse(userTenant)})
+}
+
+// RemoveUserTag godoc
+// @Summary Untag a member
+// @Description Remove a tag from a user's membership in the current tenant
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param id path string true "User ID"
+// @Param tag path string true "Tag"
+// @Success 200 {object} map[string]interface{} "Membership with its tags"
+// @Failure 400 {object} map[string]interface{} "Invalid tag"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 404 {object} map[string]interface{} "User not found in this tenant"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/{id}/tags/{tag} [delete]
+func (h *UserHandler) RemoveUserTag(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	userTenant, err := h.userService.RemoveUserTag(c.Request.Context(), c.Param("id"), tenantID, c.Param("tag"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.toUserTenantResponse(userTenant)})
+}
+
+// BulkTagUsers godoc
+// @Summary Tag members in bulk
+// @Description Add and remove tags on the current tenant's memberships of up to 500 users
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Param request body domain.BulkTagRequest true "Users and tags to add or remove"
+// @Success 200 {object} map[string]interface{} "Number of memberships matched"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Missing permission"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/tags/bulk [post]
+func (h *UserHandler) BulkTagUsers(c *gin.Context) {
+	var req domain.BulkTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, errors.BadRequest("Invalid request body"))
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+
+	result, err := h.userService.BulkTagUsers(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// ListTags godoc
+// @Summary List tags
+// @Description List every tag in use in the tenant with the number of memberships carrying it, most used first
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "Tenant ID"
+// @Success 200 {object} map[string]interface{} "Tag inventory"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/users/tags [get]
+func (h *UserHandler) ListTags(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+
+	counts, err := h.userService.ListTags(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": counts})
+}
+
+// toUserTenantResponse converts a membership domain model to a response
+func (h *UserHandler) toUserTenantResponse(ut *domain.UserTenant) domain.UserTenantResponse {
+	resp := domain.UserTenantResponse{
+		UserID:    ut.UserID.Hex(),
+		TenantID:  ut.TenantID,
+		Roles:     ut.Roles,
+		FirstName: ut.FirstName,
+		LastName:  ut.LastName,
+		IsActive:  ut.IsActive,
+		JoinedAt:  ut.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Tags:      ut.Tags,
+
+		Status:      string(ut.CurrentStatus()),
+		Transitions: ut.Transitions,
+	}
+	if !ut.StatusChangedAt.IsZero() {
+		resp.StatusChangedAt = ut.StatusChangedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if ut.ExpiresAt != nil {
+		resp.ExpiresAt = ut.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if ut.LastSeenAt != nil {
+		resp.LastSeenAt = ut.LastSeenAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
 // toUserResponse converts a user domain model to a response
-func (h *UserHandler) toUserResponse(profile *domain.UserProfile) domain.UserResponse {
-	return domain.UserResponse{
-		ID:        profile.User.ID.Hex(),
-		Email:     profile.User.Email,
-		TenantID:  profile.UserTenant.TenantID,
-		FirstName: profile.UserTenant.FirstName,
-		LastName:  profile.UserTenant.LastName,
-		Phone:     profile.User.Phone,
-		AvatarURL: profile.User.AvatarURL,
-		IsActive:  profile.User.IsActive && profile.UserTenant.IsActive, // Both must be active? Or just Tenant?
-		CreatedAt: profile.User.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: profile.User.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+func toUserResponse(profile *domain.UserProfile) domain.UserResponse {
+	resp := domain.UserResponse{
+		ID:             profile.User.ID.Hex(),
+		Email:          profile.User.Email,
+		Username:       profile.User.Username,
+		DocumentNumber: profile.User.DocumentNumber,
+		TenantID:       profile.UserTenant.TenantID,
+		FirstName:      profile.UserTenant.FirstName,
+		LastName:       profile.UserTenant.LastName,
+		Phone:          profile.User.Phone,
+		AvatarURL:      profile.User.AvatarURL,
+		IsActive:       profile.User.IsActive && profile.UserTenant.IsActive, // Both must be active? Or just Tenant?
+		Status:         string(profile.UserTenant.CurrentStatus()),
+		CreatedAt:      profile.User.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:      profile.User.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if expiresAt := profile.UserTenant.ExpiresAt; expiresAt != nil {
+		resp.ExpiresAt = expiresAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if lastSeenAt := profile.UserTenant.LastSeenAt; lastSeenAt != nil {
+		resp.LastSeenAt = lastSeenAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+// toCursorUsersResponse converts a cursor page of users to a response
+func toCursorUsersResponse(page *domain.UserPage, pageSize int) domain.CursorUsersResponse {
+	userResponses := make([]domain.UserResponse, len(page.Users))
+	for i, p := range page.Users {
+		userResponses[i] = toUserResponse(p)
+	}
+	return domain.CursorUsersResponse{
+		Users:      userResponses,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+		PageSize:   pageSize,
+	}
+}
+
+// remarshal decodes already parsed JSON fields into a typed request
+func remarshal(fields map[string]json.RawMessage, v interface{}) error {
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return err
 	}
+	return json.Unmarshal(raw, v)
 }
 
 // respondError responds with an error
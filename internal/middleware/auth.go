@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+)
+
+const (
+	// IdentityKey is the Gin context key for storing the caller identity
+	IdentityKey ContextKey = "identity"
+
+	// AuthorizationHeader is the HTTP header carrying the bearer token
+	AuthorizationHeader = "Authorization"
+)
+
+// AuthMiddleware validates the bearer token and stores the caller identity
+// Must run after TenancyMiddleware so the token can be checked against X-Tenant-ID
+// Returns 401 Unauthorized for missing or invalid tokens and 403 Forbidden
+// when the token's tenant claims do not include the requested tenant
+func AuthMiddleware(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := auth.BearerToken(c.GetHeader(AuthorizationHeader))
+		tenantID := GetTenantID(c)
+
+		identity, err := authenticator.Authenticate(c.Request.Context(), token, tenantID)
+		if err != nil {
+			switch {
+			case errors.Is(err, auth.ErrTenantNotAllowed):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "Tenant access denied",
+					"message": "Token is not valid for the requested tenant",
+					"code":    "TENANT_ACCESS_DENIED",
+				})
+			case errors.Is(err, auth.ErrMissingToken):
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error":   "Missing credentials",
+					"message": "Authorization header with a Bearer token is required",
+					"code":    "AUTH_REQUIRED",
+				})
+			case errors.Is(err, auth.ErrInvalidToken):
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error":   "Invalid credentials",
+					"message": "Token is invalid or expired",
+					"code":    "INVALID_TOKEN",
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Authentication failed",
+					"message": "Token could not be verified",
+					"code":    "AUTH_UNAVAILABLE",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		// Store identity in Gin context
+		c.Set(string(IdentityKey), identity)
+
+		// Also store in request context for use in services
+		c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), identity))
+
+		// Log caller for audit trail
+		c.Set("audit_user_id", identity.UserID)
+
+		c.Next()
+	}
+}
+
+// GetIdentity retrieves the caller identity from Gin context
+// Returns nil if the request was not authenticated
+func GetIdentity(c *gin.Context) *auth.Identity {
+	if identity, exists := c.Get(string(IdentityKey)); exists {
+		if id, ok := identity.(*auth.Identity); ok {
+			return id
+		}
+	}
+	return nil
+}
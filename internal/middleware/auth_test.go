@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticator, err := auth.NewAuthenticator(auth.Config{JWTSecret: "test-secret"})
+	require.NoError(t, err)
+
+	sign := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+		return token
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "valid token for tenant",
+			authorization:  "Bearer " + sign(jwt.MapClaims{"sub": "user-1", "exp": exp, "tenant_ids": []string{"tenant-123"}}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing authorization header",
+			authorization:  "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid token",
+			authorization:  "Bearer not.a.token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "token for another tenant",
+			authorization:  "Bearer " + sign(jwt.MapClaims{"sub": "user-1", "exp": exp, "tenant_ids": []string{"tenant-456"}}),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(TenancyMiddleware(), AuthMiddleware(authenticator))
+			router.GET("/test", func(c *gin.Context) {
+				identity := GetIdentity(c)
+				fromCtx := auth.IdentityFromContext(c.Request.Context())
+				c.JSON(http.StatusOK, gin.H{"user_id": identity.UserID, "same": identity == fromCtx})
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set(TenantIDHeader, "tenant-123")
+			if tt.authorization != "" {
+				req.Header.Set(AuthorizationHeader, tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"user_id":"user-1"`)
+				assert.Contains(t, w.Body.String(), `"same":true`)
+			}
+		})
+	}
+}
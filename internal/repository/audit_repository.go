@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditQuery selects audit entries, newest first. Empty fields match every entry
+type AuditQuery struct {
+	TenantID string
+	UserID   string
+	// Limit caps the number of entries returned; zero returns them all
+	Limit int
+}
+
+// AuditStore persists the audit trail of actions taken on users
+// AuditRepository (MongoDB) and InMemoryAuditRepository both implement it
+// and must pass the conformance suite in audit_store_conformance_test.go
+type AuditStore interface {
+	// Record appends an entry, stamping it with the current time when unset
+	Record(ctx context.Context, entry *domain.AuditEntry) error
+	List(ctx context.Context, q AuditQuery) ([]*domain.AuditEntry, error)
+}
+
+var (
+	_ AuditStore = (*AuditRepository)(nil)
+	_ AuditStore = (*InMemoryAuditRepository)(nil)
+)
+
+// AuditRepository is the MongoDB implementation of AuditStore
+type AuditRepository struct {
+	entries *mongo.Collection
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *mongo.Database) *AuditRepository {
+	entries := db.Collection("audit_log")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	auditIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}
+	_, _ = entries.Indexes().CreateMany(ctx, auditIndexes)
+
+	return &AuditRepository{entries: entries}
+}
+
+// Record appends an entry to the audit log
+func (r *AuditRepository) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	res, err := r.entries.InsertOne(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	entry.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// List returns the entries matching q, newest first
+func (r *AuditRepository) List(ctx context.Context, q AuditQuery) ([]*domain.AuditEntry, error) {
+	filter := bson.M{}
+	if q.TenantID != "" {
+		filter["tenantId"] = q.TenantID
+	}
+	if q.UserID != "" {
+		filter["userId"] = q.UserID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit))
+	}
+
+	cursor, err := r.entries.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*domain.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode audit entries: %w", err)
+	}
+	return entries, nil
+}
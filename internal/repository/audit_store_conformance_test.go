@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// testAuditStore is the conformance suite every AuditStore implementation must pass
+func testAuditStore(t *testing.T, newStore func(t *testing.T) AuditStore) {
+	ctx := context.Background()
+
+	t.Run("record and list newest first", func(t *testing.T) {
+		store := newStore(t)
+		start := time.Now().UTC().Truncate(time.Millisecond)
+		for i, entry := range []*domain.AuditEntry{
+			{TenantID: "tenant-a", UserID: "user-1", Action: domain.AuditMembershipPurged, ActorID: "admin", Details: map[string]string{"retentionDays": "30"}},
+			{TenantID: "tenant-b", UserID: "user-1", Action: domain.AuditMembershipPurged, ActorID: "admin"},
+			{UserID: "user-1", Action: domain.AuditUserPurged, ActorID: "admin"},
+			{TenantID: "tenant-a", UserID: "user-2", Action: domain.AuditMembershipPurged, ActorID: "admin"},
+		} {
+			entry.CreatedAt = start.Add(time.Duration(i) * time.Second)
+			require.NoError(t, store.Record(ctx, entry))
+			assert.False(t, entry.ID.IsZero())
+		}
+
+		entries, err := store.List(ctx, AuditQuery{UserID: "user-1"})
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, domain.AuditUserPurged, entries[0].Action)
+		assert.Equal(t, "tenant-a", entries[2].TenantID)
+		assert.Equal(t, "30", entries[2].Details["retentionDays"])
+		assert.True(t, start.Equal(entries[2].CreatedAt))
+
+		entries, err = store.List(ctx, AuditQuery{TenantID: "tenant-a"})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "user-2", entries[0].UserID)
+
+		entries, err = store.List(ctx, AuditQuery{Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+
+	t.Run("record stamps the time", func(t *testing.T) {
+		store := newStore(t)
+		entry := &domain.AuditEntry{UserID: "user-1", Action: domain.AuditUserPurged, ActorID: "admin"}
+		require.NoError(t, store.Record(ctx, entry))
+		assert.False(t, entry.CreatedAt.IsZero())
+	})
+}
+
+func TestInMemoryAuditRepository(t *testing.T) {
+	testAuditStore(t, func(t *testing.T) AuditStore {
+		return NewInMemoryAuditRepository()
+	})
+}
+
+// TestAuditRepository runs the conformance suite against a real MongoDB.
+func TestAuditRepository(t *testing.T) {
+	testAuditStore(t, func(t *testing.T) AuditStore {
+		return NewAuditRepository(newTestDatabase(t))
+	})
+}
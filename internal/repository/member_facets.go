@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxFacetValues is the most values counted per facet; the rest are left out
+const maxFacetValues = 50
+
+// joinedMonthLayout formats joinedMonth facet values
+const joinedMonthLayout = "2006-01"
+
+// facetBucket is one value of a facet as counted by MongoDB
+type facetBucket struct {
+	Value interface{} `bson:"_id"`
+	Count int64       `bson:"count"`
+}
+
+// facetPipeline returns the $facet sub-pipeline counting members by facet
+func facetPipeline(facet string) mongo.Pipeline {
+	var pipeline mongo.Pipeline
+	var key interface{}
+	switch facet {
+	case domain.UserFacetRole:
+		pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: "$roles"}})
+		key = "$roles"
+	case domain.UserFacetTag:
+		pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: "$tags"}})
+		key = "$tags"
+	case domain.UserFacetIsActive:
+		key = "$isActive"
+	case domain.UserFacetStatus:
+		// Memberships written before statuses were recorded only have isActive
+		key = bson.M{"$ifNull": bson.A{"$status", bson.M{"$cond": bson.A{"$isActive", string(domain.MembershipActive), string(domain.MembershipRemoved)}}}}
+	case domain.UserFacetJoinedMonth:
+		key = bson.M{"$dateToString": bson.M{"format": "%Y-%m", "date": "$joinedAt"}}
+	case domain.UserFacetEmailDomain:
+		key = bson.M{"$toLower": bson.M{"$arrayElemAt": bson.A{bson.M{"$split": bson.A{"$user_docs.email", "@"}}, 1}}}
+	}
+
+	order := bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}
+	if facet == domain.UserFacetJoinedMonth {
+		order = bson.D{{Key: "_id", Value: -1}}
+	}
+	return append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{"_id": key, "count": bson.M{"$sum": 1}}}},
+		bson.D{{Key: "$sort", Value: order}},
+		bson.D{{Key: "$limit", Value: int64(maxFacetValues)}},
+	)
+}
+
+// toFacetCounts converts MongoDB facet buckets to counts
+func toFacetCounts(buckets []facetBucket) []domain.FacetCount {
+	counts := make([]domain.FacetCount, 0, len(buckets))
+	for _, b := range buckets {
+		var value string
+		switch v := b.Value.(type) {
+		case string:
+			value = v
+		case bool:
+			value = strconv.FormatBool(v)
+		}
+		counts = append(counts, domain.FacetCount{Value: value, Count: b.Count})
+	}
+	return counts
+}
+
+// countFacets counts members by each facet, ordered like facetPipeline
+func countFacets(members []*UserWithTenant, facets []string) domain.UserFacets {
+	result := make(domain.UserFacets, len(facets))
+	for _, facet := range facets {
+		counts := make(map[string]int64)
+		for _, m := range members {
+			for _, value := range facetValues(m, facet) {
+				counts[value]++
+			}
+		}
+
+		values := make([]domain.FacetCount, 0, len(counts))
+		for value, count := range counts {
+			values = append(values, domain.FacetCount{Value: value, Count: count})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			a, b := values[i], values[j]
+			if facet == domain.UserFacetJoinedMonth {
+				return a.Value > b.Value
+			}
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Value < b.Value
+		})
+		if len(values) > maxFacetValues {
+			values = values[:maxFacetValues]
+		}
+		result[facet] = values
+	}
+	return result
+}
+
+// facetValues returns the values a member counts towards in a facet
+func facetValues(m *UserWithTenant, facet string) []string {
+	switch facet {
+	case domain.UserFacetRole:
+		return m.UserTenant.Roles
+	case domain.UserFacetTag:
+		return m.UserTenant.Tags
+	case domain.UserFacetIsActive:
+		return []string{strconv.FormatBool(m.UserTenant.IsActive)}
+	case domain.UserFacetStatus:
+		return []string{string(m.UserTenant.CurrentStatus())}
+	case domain.UserFacetJoinedMonth:
+		return []string{m.UserTenant.JoinedAt.UTC().Format(joinedMonthLayout)}
+	case domain.UserFacetEmailDomain:
+		if at := strings.LastIndex(m.User.Email, "@"); at >= 0 {
+			return []string{strings.ToLower(m.User.Email[at+1:])}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,503 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Member search first detects what the query looks like (see
+// validation.DetectSearchKind) and matches the fields that kind can name:
+//
+//   - a user ID matches that user's membership
+//   - an email or its start matches emails
+//   - a phone number or its start matches phone numbers and document numbers;
+//     a national number with a leading 0 also matches it after the country code
+//   - free text matches names by word prefix, ignoring case and diacritics (see
+//     validation.NormalizeSearchText). A single word also matches usernames,
+//     document numbers and emails that start with it
+//
+// Identifiers only match from their start, as typed or in lower case, so the
+// users are found through their indexes before any membership is read.
+//
+// Relevance is the best identifier score plus the name score. Identifiers
+// score 1000 for an exact match and 500 when they start with the query.
+// Names add, for every query word, 3 when it
+// equals a name word and 1 when it only prefixes one; a query of several words
+// adds 4 more when the name starts with it and 2 when it appears at another
+// word boundary. Identifier matches therefore rank above name-only matches.
+const (
+	scoreExactIdentifier  = 1000
+	scorePrefixIdentifier = 500
+
+	scoreExactWord   = 3
+	scorePrefixWord  = 1
+	scorePhraseStart = 4
+	scorePhraseWord  = 2
+)
+
+// searchRule scores an identifier field of the user against a pattern
+type searchRule struct {
+	field   string // users document field
+	pattern string // case-insensitive regular expression, valid in Go and MongoDB
+	score   int
+	re      *regexp.Regexp
+}
+
+func newSearchRule(field, pattern string, score int) searchRule {
+	return searchRule{field: field, pattern: pattern, score: score, re: regexp.MustCompile("(?i)" + pattern)}
+}
+
+// searchLookup finds users whose identifier field matches an anchored,
+// case-sensitive pattern, which the field's index can bound
+type searchLookup struct {
+	field   string
+	pattern string
+	re      *regexp.Regexp
+}
+
+func newSearchLookup(field, pattern string) searchLookup {
+	return searchLookup{field: field, pattern: pattern, re: regexp.MustCompile(pattern)}
+}
+
+// searchPlan is how a search query is matched and scored
+type searchPlan struct {
+	// userID is set for ID queries
+	userID *primitive.ObjectID
+	// lookups find the users whose identifiers can match
+	lookups []searchLookup
+	// rules score the identifier fields of users found by lookups; the best
+	// matching rule counts
+	rules []searchRule
+	// terms and phrase are the folded query words, set when names are searched
+	terms  []string
+	phrase string
+}
+
+// planSearch detects the kind of query and plans how to match it
+func planSearch(text string) searchPlan {
+	text = strings.TrimSpace(text)
+	var plan searchPlan
+
+	switch validation.DetectSearchKind(text) {
+	case validation.SearchKindID:
+		id, _ := primitive.ObjectIDFromHex(text)
+		plan.userID = &id
+	case validation.SearchKindEmail:
+		plan.addIdentifier("email", text, true)
+	case validation.SearchKindPhone:
+		digits := validation.PhoneDigits(text)
+		plan.lookups = append(plan.lookups,
+			newSearchLookup("phone", `^\+`+digits),
+			newSearchLookup("phone", "^"+digits),
+		)
+		plan.rules = append(plan.rules,
+			newSearchRule("phone", `^\+?`+digits+`$`, scoreExactIdentifier),
+			newSearchRule("phone", `^\+?`+digits, scorePrefixIdentifier),
+		)
+		if national := strings.TrimPrefix(digits, "0"); national != digits && national != "" {
+			plan.lookups = append(plan.lookups, newSearchLookup("phone", `^\+[0-9]{1,3}`+national))
+			plan.rules = append(plan.rules,
+				newSearchRule("phone", `^\+[0-9]{1,3}`+national+`$`, scoreExactIdentifier),
+				newSearchRule("phone", `^\+[0-9]{1,3}`+national, scorePrefixIdentifier),
+			)
+		}
+		plan.addIdentifier("documentNumber", digits, true)
+	default:
+		plan.terms, plan.phrase = searchTerms(text)
+		if len(plan.terms) > 0 && !strings.ContainsAny(text, " \t") {
+			plan.addIdentifier("username", text, true)
+			plan.addIdentifier("documentNumber", text, true)
+			plan.addIdentifier("email", text, false)
+		}
+	}
+	return plan
+}
+
+// addIdentifier looks users up by a field starting with value, as typed or in
+// lower case, and scores prefix matches of it, and exact ones when exact is set
+func (p *searchPlan) addIdentifier(field, value string, exact bool) {
+	for _, v := range caseVariants(value) {
+		p.lookups = append(p.lookups, newSearchLookup(field, "^"+regexp.QuoteMeta(v)))
+	}
+	quoted := regexp.QuoteMeta(strings.ToLower(value))
+	if exact {
+		p.rules = append(p.rules, newSearchRule(field, "^"+quoted+"$", scoreExactIdentifier))
+	}
+	p.rules = append(p.rules, newSearchRule(field, "^"+quoted, scorePrefixIdentifier))
+}
+
+// caseVariants returns value as typed and, when different, in lower case
+func caseVariants(value string) []string {
+	if lower := strings.ToLower(value); lower != value {
+		return []string{value, lower}
+	}
+	return []string{value}
+}
+
+// empty reports whether the plan can match nothing, e.g. for a query of punctuation
+func (p searchPlan) empty() bool {
+	return p.userID == nil && len(p.rules) == 0 && len(p.terms) == 0
+}
+
+// score scores a member against the plan, 0 when it does not match
+func (p searchPlan) score(m *UserWithTenant) int {
+	if p.userID != nil {
+		if m.UserTenant.UserID == *p.userID {
+			return scoreExactIdentifier
+		}
+		return 0
+	}
+
+	best := 0
+	if p.found(&m.User) {
+		for _, rule := range p.rules {
+			if rule.score > best && rule.re.MatchString(userField(&m.User, rule.field)) {
+				best = rule.score
+			}
+		}
+	}
+	return best + searchScore(&m.UserTenant, p.terms, p.phrase)
+}
+
+// found reports whether the plan's lookups find the user
+func (p searchPlan) found(u *domain.User) bool {
+	for _, lookup := range p.lookups {
+		if lookup.re.MatchString(userField(u, lookup.field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// userMatch matches the users the plan's lookups find
+func (p searchPlan) userMatch() bson.M {
+	clauses := bson.A{}
+	for _, lookup := range p.lookups {
+		clauses = append(clauses, bson.M{lookup.field: primitive.Regex{Pattern: lookup.pattern}})
+	}
+	return bson.M{"$or": clauses}
+}
+
+// userField returns the value of a field searchRule can name
+func userField(u *domain.User, field string) string {
+	switch field {
+	case "email":
+		return u.Email
+	case "phone":
+		return u.Phone
+	case "username":
+		return u.Username
+	case "documentNumber":
+		return u.DocumentNumber
+	}
+	return ""
+}
+
+// readsUser reports whether scoring needs the joined user
+func (p searchPlan) readsUser() bool {
+	return len(p.rules) > 0
+}
+
+// match returns the membership predicates that narrow the candidates before the
+// join: the user for ID queries, and otherwise the members among found, the
+// users the lookups found, or whose names match. Each clause can use an index
+func (p searchPlan) match(tenantID string, found []primitive.ObjectID) bson.M {
+	match := bson.M{"tenantId": tenantID}
+	if p.userID != nil {
+		match["userId"] = *p.userID
+		return match
+	}
+
+	var clauses bson.A
+	if len(p.lookups) > 0 {
+		clauses = append(clauses, bson.M{"userId": bson.M{"$in": found}})
+	}
+	if len(p.terms) > 0 {
+		// Anchored regexes let the (tenantId, searchTokens) index bound the scan
+		prefixes := bson.A{}
+		for _, term := range p.terms {
+			prefixes = append(prefixes, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(term)})
+		}
+		clauses = append(clauses, bson.M{"searchTokens": bson.M{"$in": prefixes}})
+	}
+	if len(clauses) == 1 {
+		for k, v := range clauses[0].(bson.M) {
+			match[k] = v
+		}
+	} else {
+		match["$or"] = clauses
+	}
+	return match
+}
+
+// scoreExpr computes score in an aggregation pipeline over joined memberships,
+// where found are the users the lookups found
+func (p searchPlan) scoreExpr(found []primitive.ObjectID) bson.M {
+	if p.userID != nil {
+		return bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$userId", *p.userID}}, scoreExactIdentifier, 0}}
+	}
+
+	identifiers := bson.A{0}
+	for _, rule := range p.rules {
+		identifiers = append(identifiers, bson.M{"$cond": bson.A{
+			bson.M{"$regexMatch": bson.M{
+				"input":   bson.M{"$ifNull": bson.A{"$user_docs." + rule.field, ""}},
+				"regex":   rule.pattern,
+				"options": "i",
+			}},
+			rule.score,
+			0,
+		}})
+	}
+	best := bson.M{"$max": identifiers}
+	if len(p.rules) > 0 {
+		// Name matches score their identifiers only when the lookups found them too
+		best = bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$userId", found}}, best, 0}}
+	}
+	return bson.M{"$add": bson.A{best, nameScoreExpr(p.terms, p.phrase)}}
+}
+
+// suggestCandidates is how many name matches are ranked per suggestion
+// returned, bounding the work done for very short prefixes
+const suggestCandidates = 5
+
+// suggestPlan is how an autocomplete prefix is matched: every folded word must
+// start a name word, or the prefix, as typed or lower case, must start the email
+type suggestPlan struct {
+	terms  []string
+	phrase string
+	emails []string
+}
+
+// planSuggest plans how to match an autocomplete prefix. Prefixes containing
+// '@' only match emails, and prefixes with spaces only match names
+func planSuggest(prefix string) suggestPlan {
+	prefix = strings.TrimSpace(prefix)
+	var plan suggestPlan
+	if !strings.Contains(prefix, "@") {
+		plan.terms, plan.phrase = searchTerms(prefix)
+	}
+	if prefix != "" && !strings.ContainsAny(prefix, " \t") {
+		plan.emails = []string{prefix}
+		if lower := strings.ToLower(prefix); lower != prefix {
+			plan.emails = append(plan.emails, lower)
+		}
+	}
+	return plan
+}
+
+// matches reports whether a member matches the plan
+func (p suggestPlan) matches(m *UserWithTenant) bool {
+	for _, email := range p.emails {
+		if strings.HasPrefix(m.User.Email, email) {
+			return true
+		}
+	}
+	if len(p.terms) == 0 {
+		return false
+	}
+	for _, term := range p.terms {
+		prefixed := false
+		for _, word := range m.UserTenant.SearchTokens {
+			if strings.HasPrefix(word, term) {
+				prefixed = true
+				break
+			}
+		}
+		if !prefixed {
+			return false
+		}
+	}
+	return true
+}
+
+// nameMatch matches memberships with a name word starting with every term
+func (p suggestPlan) nameMatch() bson.M {
+	prefixes := bson.A{}
+	for _, term := range p.terms {
+		prefixes = append(prefixes, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(term)})
+	}
+	return bson.M{"searchTokens": bson.M{"$all": prefixes}}
+}
+
+// emailMatch matches users whose email starts with one of the plan's prefixes.
+// Anchored, case-sensitive regexes let the unique email index bound the scan
+func (p suggestPlan) emailMatch() bson.M {
+	prefixes := bson.A{}
+	for _, email := range p.emails {
+		prefixes = append(prefixes, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(email)})
+	}
+	return bson.M{"email": bson.M{"$in": prefixes}}
+}
+
+// lessSuggestion orders suggestions by name relevance, then name
+func lessSuggestion(a, b *UserWithTenant) bool {
+	switch {
+	case a.Score != b.Score:
+		return a.Score > b.Score
+	case a.UserTenant.FirstName != b.UserTenant.FirstName:
+		return a.UserTenant.FirstName < b.UserTenant.FirstName
+	case a.UserTenant.LastName != b.UserTenant.LastName:
+		return a.UserTenant.LastName < b.UserTenant.LastName
+	}
+	return a.UserTenant.ID.Hex() < b.UserTenant.ID.Hex()
+}
+
+// toSuggestion projects a member to its suggestion, named by email when it has no name
+func toSuggestion(m *UserWithTenant) *domain.MemberSuggestion {
+	name := strings.TrimSpace(m.UserTenant.FirstName + " " + m.UserTenant.LastName)
+	if name == "" {
+		name = m.User.Email
+	}
+	return &domain.MemberSuggestion{
+		ID:          m.UserTenant.UserID.Hex(),
+		DisplayName: name,
+		AvatarURL:   m.User.AvatarURL,
+		Email:       m.User.Email,
+	}
+}
+
+// backfillBatchSize is the number of memberships updated per bulk write
+const backfillBatchSize = 500
+
+// setSearchFields derives a membership's search fields from its names
+func setSearchFields(ut *domain.UserTenant) {
+	ut.SearchName = validation.NormalizeSearchText(ut.FirstName + " " + ut.LastName)
+	ut.SearchTokens = strings.Fields(ut.SearchName)
+}
+
+// searchTerms folds a query into its distinct words and the whole phrase
+func searchTerms(text string) ([]string, string) {
+	phrase := validation.NormalizeSearchText(text)
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range strings.Fields(phrase) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms, phrase
+}
+
+// searchScore scores a membership's names against the query words, 0 when they do not match
+func searchScore(ut *domain.UserTenant, terms []string, phrase string) int {
+	score := 0
+	for _, term := range terms {
+		best := 0
+		for _, word := range ut.SearchTokens {
+			if word == term {
+				best = scoreExactWord
+				break
+			}
+			if strings.HasPrefix(word, term) {
+				best = scorePrefixWord
+			}
+		}
+		score += best
+	}
+	if score == 0 || len(terms) < 2 {
+		return score
+	}
+
+	switch at := strings.Index(" "+ut.SearchName, " "+phrase); {
+	case at == 0:
+		score += scorePhraseStart
+	case at > 0:
+		score += scorePhraseWord
+	}
+	return score
+}
+
+// nameScoreExpr computes searchScore in an aggregation pipeline
+func nameScoreExpr(terms []string, phrase string) bson.M {
+	tokens := bson.M{"$ifNull": bson.A{"$searchTokens", bson.A{}}}
+	parts := bson.A{0}
+	for _, term := range terms {
+		prefixed := bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+			"input": tokens,
+			"as":    "word",
+			"in":    bson.M{"$eq": bson.A{bson.M{"$indexOfCP": bson.A{"$$word", term}}, 0}},
+		}}}}
+		parts = append(parts, bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{term, tokens}},
+			scoreExactWord,
+			bson.M{"$cond": bson.A{prefixed, scorePrefixWord, 0}},
+		}})
+	}
+	words := bson.M{"$add": parts}
+	if len(terms) < 2 {
+		return words
+	}
+
+	at := bson.M{"$indexOfCP": bson.A{bson.M{"$concat": bson.A{" ", bson.M{"$ifNull": bson.A{"$searchName", ""}}}}, " " + phrase}}
+	return bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{words, 0}},
+		0,
+		bson.M{"$add": bson.A{words, bson.M{"$switch": bson.M{
+			"branches": bson.A{
+				bson.M{"case": bson.M{"$eq": bson.A{at, 0}}, "then": scorePhraseStart},
+				bson.M{"case": bson.M{"$gt": bson.A{at, 0}}, "then": scorePhraseWord},
+			},
+			"default": 0,
+		}}}},
+	}}
+}
+
+// BackfillSearchFields derives the search fields of memberships written
+// before they existed. It is idempotent and safe to run while serving
+func (r *UserRepository) BackfillSearchFields(ctx context.Context) (int64, error) {
+	cursor, err := r.userTenants.Find(ctx,
+		bson.M{"searchName": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"firstName": 1, "lastName": 1}),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find memberships to backfill: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var updated int64
+	var batch []mongo.WriteModel
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		result, err := r.userTenants.BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return fmt.Errorf("failed to backfill search fields: %w", err)
+		}
+		updated += result.ModifiedCount
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var ut domain.UserTenant
+		if err := cursor.Decode(&ut); err != nil {
+			return updated, err
+		}
+		setSearchFields(&ut)
+		// Set both fields explicitly, even when empty, so the document is not picked up again
+		batch = append(batch, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": ut.ID, "searchName": bson.M{"$exists": false}}).
+			SetUpdate(bson.M{"$set": bson.M{"searchName": ut.SearchName, "searchTokens": append([]string{}, ut.SearchTokens...)}}))
+		if len(batch) == backfillBatchSize {
+			if err := flush(); err != nil {
+				return updated, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return updated, err
+	}
+	return updated, flush()
+}
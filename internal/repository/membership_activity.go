@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InactiveQuery selects a tenant's active memberships inactive since before
+// SeenBefore (see domain.UserTenant.InactiveSince), in _id order
+type InactiveQuery struct {
+	TenantID   string
+	SeenBefore time.Time
+	// TrackedSince is when the tenant started tracking inactivity; members
+	// never seen count from no earlier than this
+	TrackedSince time.Time
+	// After continues a previous query after this membership _id
+	After primitive.ObjectID
+	Limit int
+}
+
+// Touch records that the member was seen at at, keeping a later LastSeenAt.
+// It reports false when the membership does not exist or is not active
+func (r *UserRepository) Touch(ctx context.Context, userID primitive.ObjectID, tenantID string, at time.Time) (bool, error) {
+	filter := statusMatch(domain.MembershipActive)
+	filter["userId"] = userID
+	filter["tenantId"] = tenantID
+
+	res, err := r.userTenants.UpdateOne(ctx, filter, bson.M{"$max": bson.M{"lastSeenAt": at}})
+	if err != nil {
+		return false, fmt.Errorf("failed to touch membership: %w", err)
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// FindInactive returns the active memberships matching q. Memberships stored
+// before statuses existed count from when they joined
+func (r *UserRepository) FindInactive(ctx context.Context, q InactiveQuery) ([]*domain.UserTenant, error) {
+	seen := bson.A{bson.M{"lastSeenAt": bson.M{"$lt": q.SeenBefore}}}
+	if q.TrackedSince.Before(q.SeenBefore) {
+		seen = append(seen, bson.M{"lastSeenAt": bson.M{"$exists": false}})
+	}
+	filter := bson.M{
+		"tenantId": q.TenantID,
+		"$and": bson.A{
+			statusMatch(domain.MembershipActive),
+			bson.M{"$or": seen},
+			bson.M{"$or": bson.A{
+				bson.M{"statusChangedAt": bson.M{"$lt": q.SeenBefore}},
+				bson.M{"statusChangedAt": bson.M{"$exists": false}, "joinedAt": bson.M{"$lt": q.SeenBefore}},
+			}},
+		},
+	}
+	if !q.After.IsZero() {
+		filter["_id"] = bson.M{"$gt": q.After}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit))
+	}
+
+	cursor, err := r.userTenants.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find inactive memberships: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tenants []*domain.UserTenant
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return nil, fmt.Errorf("failed to decode inactive memberships: %w", err)
+	}
+	return tenants, nil
+}
+
+// MarkInactivityWarned records that the member was warned about inactivity at
+// at. It reports false when the membership does not exist or is not active
+func (r *UserRepository) MarkInactivityWarned(ctx context.Context, userID primitive.ObjectID, tenantID string, at time.Time) (bool, error) {
+	filter := statusMatch(domain.MembershipActive)
+	filter["userId"] = userID
+	filter["tenantId"] = tenantID
+
+	res, err := r.userTenants.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"inactivityWarnedAt": at}})
+	if err != nil {
+		return false, fmt.Errorf("failed to mark membership warned: %w", err)
+	}
+	return res.MatchedCount == 1, nil
+}
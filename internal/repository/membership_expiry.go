@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindExpired returns up to limit active or suspended memberships whose expiry
+// is at or before now, soonest expiry first
+func (r *UserRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*domain.UserTenant, error) {
+	filter := bson.M{
+		"expiresAt": bson.M{"$lte": now},
+		"$or":       bson.A{statusMatch(domain.MembershipActive), statusMatch(domain.MembershipSuspended)},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "expiresAt", Value: 1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.userTenants.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expired memberships: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tenants []*domain.UserTenant
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return nil, fmt.Errorf("failed to decode expired memberships: %w", err)
+	}
+	return tenants, nil
+}
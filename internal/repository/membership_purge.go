@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RemovedQuery selects removed memberships due for purging: those removed
+// before Before, oldest first
+type RemovedQuery struct {
+	// TenantID restricts the query to one tenant when set
+	TenantID string
+	// ExcludeTenantIDs leaves these tenants out, e.g. the ones with their own window
+	ExcludeTenantIDs []string
+	Before           time.Time
+	Limit            int
+}
+
+// PurgeHook records a purge, e.g. in the audit log, as part of it. Its writes
+// must use tx.Context() so they commit or roll back with the purge
+type PurgeHook func(tx *Tx, result *PurgeResult) error
+
+// PurgeResult reports what Purge deleted
+type PurgeResult struct {
+	// Membership is the purged membership, nil when nothing was purged
+	Membership *domain.UserTenant
+	// UserPurged is set when the global user had no memberships left and was deleted too
+	UserPurged bool
+}
+
+// FindRemoved returns the removed memberships matching q
+func (r *UserRepository) FindRemoved(ctx context.Context, q RemovedQuery) ([]*domain.UserTenant, error) {
+	filter := bson.M{
+		"status":          domain.MembershipRemoved,
+		"statusChangedAt": bson.M{"$lt": q.Before},
+	}
+	if q.TenantID != "" {
+		filter["tenantId"] = q.TenantID
+	} else if len(q.ExcludeTenantIDs) > 0 {
+		filter["tenantId"] = bson.M{"$nin": q.ExcludeTenantIDs}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "statusChangedAt", Value: 1}, {Key: "_id", Value: 1}})
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit))
+	}
+
+	cursor, err := r.userTenants.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find removed memberships: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tenants []*domain.UserTenant
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return nil, fmt.Errorf("failed to decode removed memberships: %w", err)
+	}
+	return tenants, nil
+}
+
+// Purge hard deletes the tenant membership of userID if it is still removed
+// and was removed before removedBefore, then deletes the global user once they
+// have no memberships left, and runs then. The deletes and then's writes share a
+// transaction when the deployment supports it; otherwise the deletes are
+// rolled back when then fails
+func (r *UserRepository) Purge(ctx context.Context, userID primitive.ObjectID, tenantID string, removedBefore time.Time, then PurgeHook) (*PurgeResult, error) {
+	result := &PurgeResult{}
+	err := r.tx.Run(ctx, func(tx *Tx) error {
+		*result = PurgeResult{}
+
+		var membership domain.UserTenant
+		err := r.userTenants.FindOneAndDelete(tx.Context(), bson.M{
+			"userId":          userID,
+			"tenantId":        tenantID,
+			"status":          domain.MembershipRemoved,
+			"statusChangedAt": bson.M{"$lt": removedBefore},
+		}).Decode(&membership)
+		if err == mongo.ErrNoDocuments {
+			// Restored or purged in the meantime
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to purge membership: %w", err)
+		}
+		result.Membership = &membership
+		tx.OnRollback(func(ctx context.Context) error {
+			_, err := r.userTenants.InsertOne(ctx, &membership)
+			return err
+		})
+
+		remaining, err := r.userTenants.CountDocuments(tx.Context(), bson.M{"userId": userID}, options.Count().SetLimit(1))
+		if err != nil {
+			return fmt.Errorf("failed to count memberships: %w", err)
+		}
+		if remaining == 0 {
+			var user domain.User
+			err = r.users.FindOneAndDelete(tx.Context(), bson.M{"_id": userID}).Decode(&user)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return fmt.Errorf("failed to purge user: %w", err)
+			}
+			if err == nil {
+				result.UserPurged = true
+				tx.OnRollback(func(ctx context.Context) error {
+					_, err := r.users.InsertOne(ctx, &user)
+					return err
+				})
+			}
+		}
+
+		if then != nil {
+			return then(tx, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// initStatus gives a new membership its status when the caller set none,
+// derived from IsActive, and stamps when it took effect
+func initStatus(ut *domain.UserTenant, now time.Time) {
+	if ut.Status == "" {
+		ut.Status = ut.CurrentStatus()
+	}
+	ut.IsActive = ut.Status == domain.MembershipActive
+	if ut.StatusChangedAt.IsZero() {
+		ut.StatusChangedAt = now
+	}
+}
+
+// statusMatch matches memberships in status, including memberships stored
+// before statuses existed that CurrentStatus reads as status
+func statusMatch(status domain.MembershipStatus) bson.M {
+	legacy := bson.M{"status": bson.M{"$exists": false}}
+	switch status {
+	case domain.MembershipActive:
+		legacy["isActive"] = true
+	case domain.MembershipRemoved:
+		legacy["isActive"] = false
+	default:
+		return bson.M{"status": status}
+	}
+	return bson.M{"$or": bson.A{bson.M{"status": status}, legacy}}
+}
+
+// transitionUpdate returns the update applying t to a membership, keeping the
+// most recent transitions like domain.UserTenant.ApplyTransition
+func transitionUpdate(t domain.MembershipTransition) bson.M {
+	return bson.M{
+		"$set": bson.M{
+			"status":          t.To,
+			"isActive":        t.To == domain.MembershipActive,
+			"statusChangedAt": t.At,
+		},
+		"$push": bson.M{"transitions": bson.M{
+			"$each":  bson.A{t},
+			"$slice": -domain.MaxMembershipTransitions,
+		}},
+	}
+}
+
+// SetStatus applies t to the tenant membership of userID if it is still in
+// status from
+func (r *UserRepository) SetStatus(ctx context.Context, userID primitive.ObjectID, tenantID string, from domain.MembershipStatus, t domain.MembershipTransition) (bool, error) {
+	filter := statusMatch(from)
+	filter["userId"] = userID
+	filter["tenantId"] = tenantID
+
+	res, err := r.userTenants.UpdateOne(ctx, filter, transitionUpdate(t))
+	if err != nil {
+		return false, fmt.Errorf("failed to set membership status: %w", err)
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// Restore applies t to the tenant membership of userID if it is still removed,
+// giving it roles and expiresAt in the same write so it is never active with
+// the roles it had before removal
+func (r *UserRepository) Restore(ctx context.Context, userID primitive.ObjectID, tenantID string, roles []string, expiresAt *time.Time, t domain.MembershipTransition) (bool, error) {
+	filter := statusMatch(domain.MembershipRemoved)
+	filter["userId"] = userID
+	filter["tenantId"] = tenantID
+
+	update := transitionUpdate(t)
+	set := update["$set"].(bson.M)
+	set["roles"] = roles
+	if expiresAt != nil {
+		set["expiresAt"] = expiresAt
+	} else {
+		update["$unset"] = bson.M{"expiresAt": ""}
+	}
+
+	res, err := r.userTenants.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore membership: %w", err)
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// BackfillMembershipStatus gives memberships stored before statuses existed
+// the status CurrentStatus derives for them: active, or removed once soft
+// deleted. It returns how many memberships were updated
+//
+// Soft deletes never recorded when they happened, so removed memberships are
+// stamped with now and start their full retention window instead of being
+// purged on the next run
+func (r *UserRepository) BackfillMembershipStatus(ctx context.Context, now time.Time) (int64, error) {
+	var updated int64
+	for _, status := range []domain.MembershipStatus{domain.MembershipActive, domain.MembershipRemoved} {
+		var changedAt interface{} = "$joinedAt"
+		if status == domain.MembershipRemoved {
+			changedAt = now
+		}
+		res, err := r.userTenants.UpdateMany(ctx,
+			bson.M{"status": bson.M{"$exists": false}, "isActive": status == domain.MembershipActive},
+			bson.A{bson.M{"$set": bson.M{"status": status, "statusChangedAt": changedAt}}},
+		)
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill membership status: %w", err)
+		}
+		updated += res.ModifiedCount
+	}
+	return updated, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemoryAuditRepository is a thread-safe, in-process AuditStore for tests and local tooling
+type InMemoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []*domain.AuditEntry // Oldest first
+}
+
+// NewInMemoryAuditRepository creates an empty in-memory audit repository
+func NewInMemoryAuditRepository() *InMemoryAuditRepository {
+	return &InMemoryAuditRepository{}
+}
+
+// Record appends an entry to the audit log
+func (r *InMemoryAuditRepository) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.ID = primitive.NewObjectID()
+	r.entries = append(r.entries, cloneAuditEntry(entry))
+	return nil
+}
+
+// List returns the entries matching q, newest first
+func (r *InMemoryAuditRepository) List(ctx context.Context, q AuditQuery) ([]*domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*domain.AuditEntry
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		e := r.entries[i]
+		if (q.TenantID != "" && e.TenantID != q.TenantID) || (q.UserID != "" && e.UserID != q.UserID) {
+			continue
+		}
+		entries = append(entries, cloneAuditEntry(e))
+		if q.Limit > 0 && len(entries) == q.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+func cloneAuditEntry(e *domain.AuditEntry) *domain.AuditEntry {
+	c := *e
+	if e.Details != nil {
+		c.Details = make(map[string]string, len(e.Details))
+		for k, v := range e.Details {
+			c.Details[k] = v
+		}
+	}
+	return &c
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemoryPolicyRepository is a thread-safe, in-process PolicyStore for tests and local tooling
+type InMemoryPolicyRepository struct {
+	mu       sync.RWMutex
+	policies map[string]*domain.MembershipPolicy
+}
+
+// NewInMemoryPolicyRepository creates an empty in-memory policy repository
+func NewInMemoryPolicyRepository() *InMemoryPolicyRepository {
+	return &InMemoryPolicyRepository{policies: make(map[string]*domain.MembershipPolicy)}
+}
+
+// Find returns a tenant's policy
+func (r *InMemoryPolicyRepository) Find(ctx context.Context, tenantID string) (*domain.MembershipPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if policy, ok := r.policies[tenantID]; ok {
+		c := *policy
+		return &c, nil
+	}
+	return nil, nil
+}
+
+// List returns every saved policy ordered by tenant
+func (r *InMemoryPolicyRepository) List(ctx context.Context) ([]*domain.MembershipPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policies := make([]*domain.MembershipPolicy, 0, len(r.policies))
+	for _, policy := range r.policies {
+		c := *policy
+		policies = append(policies, &c)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].TenantID < policies[j].TenantID })
+	return policies, nil
+}
+
+// Save replaces a tenant's policy
+func (r *InMemoryPolicyRepository) Save(ctx context.Context, policy *domain.MembershipPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.policies[policy.TenantID]; ok {
+		policy.ID = existing.ID
+	} else {
+		policy.ID = primitive.NewObjectID()
+	}
+	policy.UpdatedAt = time.Now()
+	c := *policy
+	r.policies[policy.TenantID] = &c
+	return nil
+}
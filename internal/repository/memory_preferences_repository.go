@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// preferencesKey identifies a preferences document, mirroring the unique (userId, tenantId) index
+type preferencesKey struct {
+	userID   string
+	tenantID string
+}
+
+// InMemoryPreferencesRepository is a thread-safe, in-process PreferencesStore for tests and local tooling
+type InMemoryPreferencesRepository struct {
+	mu                sync.RWMutex
+	preferences       map[preferencesKey]*domain.UserPreferences
+	tenantPreferences map[string]*domain.TenantPreferences
+}
+
+// NewInMemoryPreferencesRepository creates an empty in-memory preferences repository
+func NewInMemoryPreferencesRepository() *InMemoryPreferencesRepository {
+	return &InMemoryPreferencesRepository{
+		preferences:       make(map[preferencesKey]*domain.UserPreferences),
+		tenantPreferences: make(map[string]*domain.TenantPreferences),
+	}
+}
+
+// Find returns a user's stored preferences in a tenant
+func (r *InMemoryPreferencesRepository) Find(ctx context.Context, userID, tenantID string) (*domain.UserPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if prefs, ok := r.preferences[preferencesKey{userID, tenantID}]; ok {
+		return clonePreferences(prefs)
+	}
+	return nil, nil
+}
+
+// Save replaces a user's stored preferences in a tenant
+func (r *InMemoryPreferencesRepository) Save(ctx context.Context, prefs *domain.UserPreferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := preferencesKey{prefs.UserID, prefs.TenantID}
+	if existing, ok := r.preferences[k]; ok {
+		prefs.ID = existing.ID
+	} else {
+		prefs.ID = primitive.NewObjectID()
+	}
+	prefs.UpdatedAt = time.Now()
+
+	saved, err := clonePreferences(prefs)
+	if err != nil {
+		return err
+	}
+	r.preferences[k] = saved
+	return nil
+}
+
+// Delete removes a user's stored preferences in a tenant
+func (r *InMemoryPreferencesRepository) Delete(ctx context.Context, userID, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.preferences, preferencesKey{userID, tenantID})
+	return nil
+}
+
+// FindTenantDefaults returns a tenant's preference defaults
+func (r *InMemoryPreferencesRepository) FindTenantDefaults(ctx context.Context, tenantID string) (*domain.TenantPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if prefs, ok := r.tenantPreferences[tenantID]; ok {
+		return cloneTenantPreferences(prefs)
+	}
+	return nil, nil
+}
+
+// SaveTenantDefaults replaces a tenant's preference defaults
+func (r *InMemoryPreferencesRepository) SaveTenantDefaults(ctx context.Context, prefs *domain.TenantPreferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.tenantPreferences[prefs.TenantID]; ok {
+		prefs.ID = existing.ID
+	} else {
+		prefs.ID = primitive.NewObjectID()
+	}
+	prefs.UpdatedAt = time.Now()
+
+	saved, err := cloneTenantPreferences(prefs)
+	if err != nil {
+		return err
+	}
+	r.tenantPreferences[prefs.TenantID] = saved
+	return nil
+}
+
+// clonePreferences deep-copies preferences, including nested settings
+func clonePreferences(prefs *domain.UserPreferences) (*domain.UserPreferences, error) {
+	c := *prefs
+	settings, err := cloneSettings(prefs.Settings)
+	if err != nil {
+		return nil, err
+	}
+	c.Settings = settings
+	return &c, nil
+}
+
+// cloneTenantPreferences deep-copies tenant preferences, including nested settings
+func cloneTenantPreferences(prefs *domain.TenantPreferences) (*domain.TenantPreferences, error) {
+	c := *prefs
+	settings, err := cloneSettings(prefs.Settings)
+	if err != nil {
+		return nil, err
+	}
+	c.Settings = settings
+	c.Locked = append([]string(nil), prefs.Locked...)
+	return &c, nil
+}
+
+func cloneSettings(settings map[string]interface{}) (map[string]interface{}, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy settings: %w", err)
+	}
+	var c map[string]interface{}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("failed to copy settings: %w", err)
+	}
+	return c, nil
+}
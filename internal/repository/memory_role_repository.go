@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// roleKey identifies a role, mirroring the unique (tenantId, key) index
+type roleKey struct {
+	tenantID string
+	key      string
+}
+
+// InMemoryRoleRepository is a thread-safe, in-process RoleStore for tests and local tooling
+type InMemoryRoleRepository struct {
+	mu    sync.RWMutex
+	roles map[roleKey]*domain.Role
+}
+
+// NewInMemoryRoleRepository creates an empty in-memory role repository
+func NewInMemoryRoleRepository() *InMemoryRoleRepository {
+	return &InMemoryRoleRepository{roles: make(map[roleKey]*domain.Role)}
+}
+
+// Create stores a new custom role
+func (r *InMemoryRoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := roleKey{role.TenantID, role.Key}
+	if _, exists := r.roles[k]; exists {
+		return ErrRoleExists
+	}
+
+	now := time.Now()
+	role.ID = primitive.NewObjectID()
+	role.CreatedAt = now
+	role.UpdatedAt = now
+	r.roles[k] = cloneRole(role)
+	return nil
+}
+
+// FindByKey finds a tenant's role by key
+func (r *InMemoryRoleRepository) FindByKey(ctx context.Context, tenantID, key string) (*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if role, ok := r.roles[roleKey{tenantID, key}]; ok {
+		return cloneRole(role), nil
+	}
+	return nil, nil
+}
+
+// List returns every custom role of a tenant ordered by key
+func (r *InMemoryRoleRepository) List(ctx context.Context, tenantID string) ([]*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var roles []*domain.Role
+	for k, role := range r.roles {
+		if k.tenantID == tenantID {
+			roles = append(roles, cloneRole(role))
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Key < roles[j].Key })
+	return roles, nil
+}
+
+// Update saves a role's display name, description and permissions
+func (r *InMemoryRoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.roles[roleKey{role.TenantID, role.Key}]
+	if !ok {
+		return nil
+	}
+	role.UpdatedAt = time.Now()
+	existing.DisplayName = role.DisplayName
+	existing.Description = role.Description
+	existing.Permissions = append([]string(nil), role.Permissions...)
+	existing.UpdatedAt = role.UpdatedAt
+	return nil
+}
+
+// Delete removes a role from a tenant's catalog
+func (r *InMemoryRoleRepository) Delete(ctx context.Context, tenantID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.roles, roleKey{tenantID, key})
+	return nil
+}
+
+func cloneRole(role *domain.Role) *domain.Role {
+	c := *role
+	c.Permissions = append([]string(nil), role.Permissions...)
+	return &c
+}
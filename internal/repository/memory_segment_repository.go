@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// segmentKey identifies a segment, mirroring the unique (tenantId, key) index
+type segmentKey struct {
+	tenantID string
+	key      string
+}
+
+// InMemorySegmentRepository is a thread-safe, in-process SegmentStore for tests and local tooling
+type InMemorySegmentRepository struct {
+	mu       sync.RWMutex
+	segments map[segmentKey]*domain.Segment
+}
+
+// NewInMemorySegmentRepository creates an empty in-memory segment repository
+func NewInMemorySegmentRepository() *InMemorySegmentRepository {
+	return &InMemorySegmentRepository{segments: make(map[segmentKey]*domain.Segment)}
+}
+
+// Create stores a new segment
+func (r *InMemorySegmentRepository) Create(ctx context.Context, segment *domain.Segment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := segmentKey{segment.TenantID, segment.Key}
+	if _, exists := r.segments[k]; exists {
+		return ErrSegmentExists
+	}
+
+	now := time.Now()
+	segment.ID = primitive.NewObjectID()
+	segment.CreatedAt = now
+	segment.UpdatedAt = now
+	c := *segment
+	r.segments[k] = &c
+	return nil
+}
+
+// FindByKey finds a tenant's segment by key
+func (r *InMemorySegmentRepository) FindByKey(ctx context.Context, tenantID, key string) (*domain.Segment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if segment, ok := r.segments[segmentKey{tenantID, key}]; ok {
+		c := *segment
+		return &c, nil
+	}
+	return nil, nil
+}
+
+// List returns every segment of a tenant ordered by key
+func (r *InMemorySegmentRepository) List(ctx context.Context, tenantID string) ([]*domain.Segment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var segments []*domain.Segment
+	for k, segment := range r.segments {
+		if k.tenantID == tenantID {
+			c := *segment
+			segments = append(segments, &c)
+		}
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Key < segments[j].Key })
+	return segments, nil
+}
+
+// Update saves a segment's name, description and filter
+func (r *InMemorySegmentRepository) Update(ctx context.Context, segment *domain.Segment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.segments[segmentKey{segment.TenantID, segment.Key}]
+	if !ok {
+		return nil
+	}
+	segment.UpdatedAt = time.Now()
+	existing.Name = segment.Name
+	existing.Description = segment.Description
+	existing.Filter = segment.Filter
+	existing.UpdatedAt = segment.UpdatedAt
+	return nil
+}
+
+// Delete removes a segment from a tenant
+func (r *InMemorySegmentRepository) Delete(ctx context.Context, tenantID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.segments, segmentKey{tenantID, key})
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// settingsSchemaKey identifies a schema, mirroring the unique (tenantId, namespace) index
+type settingsSchemaKey struct {
+	tenantID  string
+	namespace string
+}
+
+// InMemorySettingsSchemaRepository is a thread-safe, in-process SettingsSchemaStore for tests and local tooling
+type InMemorySettingsSchemaRepository struct {
+	mu      sync.RWMutex
+	schemas map[settingsSchemaKey]*domain.SettingsSchema
+}
+
+// NewInMemorySettingsSchemaRepository creates an empty in-memory settings schema repository
+func NewInMemorySettingsSchemaRepository() *InMemorySettingsSchemaRepository {
+	return &InMemorySettingsSchemaRepository{schemas: make(map[settingsSchemaKey]*domain.SettingsSchema)}
+}
+
+// Save creates or replaces the schema of a namespace
+func (r *InMemorySettingsSchemaRepository) Save(ctx context.Context, schema *domain.SettingsSchema) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	k := settingsSchemaKey{schema.TenantID, schema.Namespace}
+	if existing, ok := r.schemas[k]; ok {
+		schema.ID = existing.ID
+		schema.CreatedAt = existing.CreatedAt
+	} else {
+		schema.ID = primitive.NewObjectID()
+		schema.CreatedAt = now
+	}
+	schema.UpdatedAt = now
+
+	saved := *schema
+	r.schemas[k] = &saved
+	return nil
+}
+
+// Find returns the schema of a namespace
+func (r *InMemorySettingsSchemaRepository) Find(ctx context.Context, tenantID, namespace string) (*domain.SettingsSchema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if schema, ok := r.schemas[settingsSchemaKey{tenantID, namespace}]; ok {
+		c := *schema
+		return &c, nil
+	}
+	return nil, nil
+}
+
+// List returns every schema registered for a tenant ordered by namespace
+func (r *InMemorySettingsSchemaRepository) List(ctx context.Context, tenantID string) ([]*domain.SettingsSchema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var schemas []*domain.SettingsSchema
+	for k, schema := range r.schemas {
+		if k.tenantID == tenantID {
+			c := *schema
+			schemas = append(schemas, &c)
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Namespace < schemas[j].Namespace })
+	return schemas, nil
+}
+
+// Delete removes the schema of a namespace
+func (r *InMemorySettingsSchemaRepository) Delete(ctx context.Context, tenantID, namespace string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := settingsSchemaKey{tenantID, namespace}
+	if _, ok := r.schemas[k]; !ok {
+		return false, nil
+	}
+	delete(r.schemas, k)
+	return true, nil
+}
@@ -0,0 +1,825 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// membershipKey identifies a user_tenants row, mirroring the unique (userId, tenantId) index
+type membershipKey struct {
+	userID   primitive.ObjectID
+	tenantID string
+}
+
+// InMemoryUserRepository is a thread-safe, in-process UserStore.
+// It mirrors the MongoDB indexes (unique email, sparse unique username and
+// document number, unique userId+tenantId) and is intended for tests and local tooling.
+// Values are copied on the way in and out so callers never share state with the store.
+type InMemoryUserRepository struct {
+	mu          sync.RWMutex
+	users       map[primitive.ObjectID]*domain.User
+	userTenants map[membershipKey]*domain.UserTenant
+}
+
+// NewInMemoryUserRepository creates an empty in-memory user repository
+func NewInMemoryUserRepository() *InMemoryUserRepository {
+	return &InMemoryUserRepository{
+		users:       make(map[primitive.ObjectID]*domain.User),
+		userTenants: make(map[membershipKey]*domain.UserTenant),
+	}
+}
+
+// Create upserts the global user by email and links it to the tenant
+func (r *InMemoryUserRepository) Create(ctx context.Context, user *domain.User, userTenant *domain.UserTenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	saved := r.findByEmailLocked(user.Email)
+	if saved != nil {
+		if _, exists := r.userTenants[membershipKey{saved.ID, userTenant.TenantID}]; exists {
+			return ErrUserTenantExists
+		}
+		saved.UpdatedAt = now
+	} else {
+		candidate := &domain.User{
+			ID:             primitive.NewObjectID(),
+			Email:          user.Email,
+			Username:       user.Username,
+			DocumentNumber: user.DocumentNumber,
+			PasswordHash:   user.PasswordHash,
+			Phone:          user.Phone,
+			AvatarURL:      user.AvatarURL,
+			IsActive:       user.IsActive,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if r.identifierTakenLocked(candidate) {
+			return ErrIdentifierTaken
+		}
+		r.users[candidate.ID] = candidate
+		saved = candidate
+	}
+
+	*user = *cloneUser(saved)
+	userTenant.ID = primitive.NewObjectID()
+	userTenant.UserID = saved.ID
+	userTenant.JoinedAt = now
+	setSearchFields(userTenant)
+	initStatus(userTenant, now)
+	r.userTenants[membershipKey{saved.ID, userTenant.TenantID}] = cloneUserTenant(userTenant)
+
+	return nil
+}
+
+// AddToTenant links an existing global user to a tenant
+func (r *InMemoryUserRepository) AddToTenant(ctx context.Context, userTenant *domain.UserTenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := membershipKey{userTenant.UserID, userTenant.TenantID}
+	if _, exists := r.userTenants[key]; exists {
+		return ErrUserTenantExists
+	}
+
+	userTenant.ID = primitive.NewObjectID()
+	userTenant.JoinedAt = time.Now()
+	setSearchFields(userTenant)
+	initStatus(userTenant, userTenant.JoinedAt)
+	r.userTenants[key] = cloneUserTenant(userTenant)
+
+	return nil
+}
+
+// FindByID finds a user by ID and tenant
+func (r *InMemoryUserRepository) FindByID(ctx context.Context, id, tenantID string) (*domain.User, *domain.UserTenant, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ut, ok := r.userTenants[membershipKey{userID, tenantID}]
+	if !ok {
+		return nil, nil, nil
+	}
+	u, ok := r.users[userID]
+	if !ok {
+		return nil, nil, fmt.Errorf("failed to find user: %s", id)
+	}
+
+	return cloneUser(u), cloneUserTenant(ut), nil
+}
+
+// FindByEmail finds a user by email and retrieves their tenant info
+func (r *InMemoryUserRepository) FindByEmail(ctx context.Context, email, tenantID string) (*domain.User, *domain.UserTenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u := r.findByEmailLocked(email)
+	if u == nil {
+		return nil, nil, nil
+	}
+	ut, ok := r.userTenants[membershipKey{u.ID, tenantID}]
+	if !ok {
+		// User exists but not in this tenant
+		return cloneUser(u), nil, nil
+	}
+
+	return cloneUser(u), cloneUserTenant(ut), nil
+}
+
+// FindUserByID finds a global user by ID regardless of tenant
+func (r *InMemoryUserRepository) FindUserByID(ctx context.Context, id string) (*domain.User, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	return cloneUser(u), nil
+}
+
+// FindByIdentifier finds a global user by email, username, phone or document number
+func (r *InMemoryUserRepository) FindByIdentifier(ctx context.Context, identifier, identifierType string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.sortedUsersLocked() {
+		var match bool
+		switch identifierType {
+		case "email":
+			match = u.Email == identifier
+		case "username":
+			match = u.Username == identifier
+		case "phone":
+			match = u.Phone == identifier
+		case "document_number":
+			match = u.DocumentNumber == identifier
+		default:
+			match = u.Username == identifier || u.Phone == identifier || u.DocumentNumber == identifier
+		}
+		if match && identifier != "" {
+			return cloneUser(u), nil
+		}
+	}
+	return nil, nil
+}
+
+// FindTenants lists every tenant membership of a user, oldest first
+func (r *InMemoryUserRepository) FindTenants(ctx context.Context, id string) ([]*domain.UserTenant, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*domain.UserTenant
+	for key, ut := range r.userTenants {
+		if key.userID == userID {
+			results = append(results, cloneUserTenant(ut))
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].JoinedAt.Equal(results[j].JoinedAt) {
+			return results[i].ID.Hex() < results[j].ID.Hex()
+		}
+		return results[i].JoinedAt.Before(results[j].JoinedAt)
+	})
+	return results, nil
+}
+
+// CountMembersWithRole counts a tenant's memberships holding role
+func (r *InMemoryUserRepository) CountMembersWithRole(ctx context.Context, tenantID, role string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for k, ut := range r.userTenants {
+		if k.tenantID != tenantID {
+			continue
+		}
+		for _, assigned := range ut.Roles {
+			if assigned == role {
+				count++
+				break
+			}
+		}
+	}
+	return count, nil
+}
+
+// AddTags adds tags to the tenant's memberships of userIDs
+func (r *InMemoryUserRepository) AddTags(ctx context.Context, tenantID string, userIDs []primitive.ObjectID, tags []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched int64
+	for _, ut := range r.membershipsLocked(tenantID, userIDs) {
+		for _, tag := range tags {
+			if !containsString(ut.Tags, tag) {
+				ut.Tags = append(ut.Tags, tag)
+			}
+		}
+		matched++
+	}
+	return matched, nil
+}
+
+// RemoveTags removes tags from the tenant's memberships of userIDs
+func (r *InMemoryUserRepository) RemoveTags(ctx context.Context, tenantID string, userIDs []primitive.ObjectID, tags []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched int64
+	for _, ut := range r.membershipsLocked(tenantID, userIDs) {
+		kept := ut.Tags[:0]
+		for _, tag := range ut.Tags {
+			if !containsString(tags, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		ut.Tags = kept
+		matched++
+	}
+	return matched, nil
+}
+
+// CountTags counts the tenant's memberships carrying each tag
+func (r *InMemoryUserRepository) CountTags(ctx context.Context, tenantID string) ([]domain.TagCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for k, ut := range r.userTenants {
+		if k.tenantID != tenantID {
+			continue
+		}
+		for _, tag := range ut.Tags {
+			counts[tag]++
+		}
+	}
+
+	var result []domain.TagCount
+	for tag, count := range counts {
+		result = append(result, domain.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result, nil
+}
+
+// membershipsLocked returns the stored memberships of userIDs in a tenant
+func (r *InMemoryUserRepository) membershipsLocked(tenantID string, userIDs []primitive.ObjectID) []*domain.UserTenant {
+	var memberships []*domain.UserTenant
+	seen := make(map[primitive.ObjectID]bool, len(userIDs))
+	for _, id := range userIDs {
+		if ut, ok := r.userTenants[membershipKey{id, tenantID}]; ok && !seen[id] {
+			seen[id] = true
+			memberships = append(memberships, ut)
+		}
+	}
+	return memberships
+}
+
+// List lists users for a tenant with pagination, newest members first
+func (r *InMemoryUserRepository) List(ctx context.Context, q MemberQuery) ([]*UserWithTenant, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := r.membersLocked(q.TenantID)
+	return pageMembers(matches, q)
+}
+
+// Search searches users by the kind of query, scored like UserRepository
+func (r *InMemoryUserRepository) Search(ctx context.Context, q MemberQuery, text string) ([]*UserWithTenant, int64, error) {
+	if q.Sort.Field == "" {
+		q.Sort = domain.RelevanceUserSort
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return pageMembers(r.searchLocked(q.TenantID, text), q)
+}
+
+// searchLocked returns the tenant's members matching text, with their scores
+func (r *InMemoryUserRepository) searchLocked(tenantID, text string) []*UserWithTenant {
+	plan := planSearch(text)
+	if plan.empty() {
+		return nil
+	}
+
+	var matches []*UserWithTenant
+	for _, m := range r.membersLocked(tenantID) {
+		if m.Score = plan.score(m); m.Score > 0 {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// Count counts the tenant's members matching q
+func (r *InMemoryUserRepository) Count(ctx context.Context, q MemberQuery) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, m := range r.membersLocked(q.TenantID) {
+		if matchesQuery(m, q) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Facets counts the members List or Search would match by each facet
+func (r *InMemoryUserRepository) Facets(ctx context.Context, q MemberQuery, text string, facets []string) (domain.UserFacets, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := r.membersLocked(q.TenantID)
+	if text != "" {
+		candidates = r.searchLocked(q.TenantID, text)
+	}
+	var matches []*UserWithTenant
+	for _, m := range candidates {
+		if matchesQuery(m, q) {
+			matches = append(matches, m)
+		}
+	}
+	return countFacets(matches, facets), nil
+}
+
+// Suggest returns autocomplete suggestions ordered like UserRepository
+func (r *InMemoryUserRepository) Suggest(ctx context.Context, tenantID, prefix string, limit int) ([]*domain.MemberSuggestion, error) {
+	plan := planSuggest(prefix)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []*UserWithTenant
+	for _, m := range r.membersLocked(tenantID) {
+		if m.UserTenant.IsActive && m.User.IsActive && plan.matches(m) {
+			m.Score = searchScore(&m.UserTenant, plan.terms, plan.phrase)
+			matches = append(matches, m)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return lessSuggestion(matches[i], matches[j]) })
+
+	suggestions := make([]*domain.MemberSuggestion, 0, limit)
+	for _, m := range matches {
+		if len(suggestions) == limit {
+			break
+		}
+		suggestions = append(suggestions, toSuggestion(m))
+	}
+	return suggestions, nil
+}
+
+// Update updates user and tenant info
+func (r *InMemoryUserRepository) Update(ctx context.Context, user *domain.User, userTenant *domain.UserTenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user != nil {
+		if _, ok := r.users[user.ID]; ok {
+			if r.identifierTakenLocked(user) {
+				return ErrIdentifierTaken
+			}
+			user.UpdatedAt = time.Now()
+			r.users[user.ID] = cloneUser(user)
+		}
+	}
+
+	if userTenant != nil {
+		if existing, ok := r.userTenants[membershipKey{userTenant.UserID, userTenant.TenantID}]; ok {
+			setMembershipFields(existing, userTenant)
+		}
+	}
+	return nil
+}
+
+// setMembershipFields copies the names, roles and expiry of from to ut, like
+// membershipUpdate does
+func setMembershipFields(ut, from *domain.UserTenant) {
+	ut.FirstName = from.FirstName
+	ut.LastName = from.LastName
+	ut.Roles = append([]string(nil), from.Roles...)
+	ut.ExpiresAt = cloneTime(from.ExpiresAt)
+	setSearchFields(ut)
+}
+
+// Delete soft deletes a user from a tenant
+func (r *InMemoryUserRepository) Delete(ctx context.Context, id, tenantID string) error {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ut, ok := r.userTenants[membershipKey{userID, tenantID}]; ok {
+		ut.ApplyTransition(domain.MembershipTransition{To: domain.MembershipRemoved, At: time.Now()})
+	}
+	return nil
+}
+
+// SetStatus applies t to the tenant membership of userID if it is still in status from
+func (r *InMemoryUserRepository) SetStatus(ctx context.Context, userID primitive.ObjectID, tenantID string, from domain.MembershipStatus, t domain.MembershipTransition) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ut, ok := r.userTenants[membershipKey{userID, tenantID}]
+	if !ok || ut.CurrentStatus() != from {
+		return false, nil
+	}
+	ut.ApplyTransition(t)
+	return true, nil
+}
+
+// Restore applies t to the removed tenant membership of userID together with
+// its new roles and expiry
+func (r *InMemoryUserRepository) Restore(ctx context.Context, userID primitive.ObjectID, tenantID string, roles []string, expiresAt *time.Time, t domain.MembershipTransition) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ut, ok := r.userTenants[membershipKey{userID, tenantID}]
+	if !ok || ut.CurrentStatus() != domain.MembershipRemoved {
+		return false, nil
+	}
+	ut.ApplyTransition(t)
+	ut.Roles = append([]string(nil), roles...)
+	ut.ExpiresAt = cloneTime(expiresAt)
+	return true, nil
+}
+
+// FindRemoved returns the removed memberships matching q
+func (r *InMemoryUserRepository) FindRemoved(ctx context.Context, q RemovedQuery) ([]*domain.UserTenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tenants []*domain.UserTenant
+	for _, ut := range r.userTenants {
+		if ut.Status != domain.MembershipRemoved || !ut.StatusChangedAt.Before(q.Before) {
+			continue
+		}
+		if (q.TenantID != "" && ut.TenantID != q.TenantID) ||
+			(q.TenantID == "" && containsString(q.ExcludeTenantIDs, ut.TenantID)) {
+			continue
+		}
+		tenants = append(tenants, cloneUserTenant(ut))
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		if !tenants[i].StatusChangedAt.Equal(tenants[j].StatusChangedAt) {
+			return tenants[i].StatusChangedAt.Before(tenants[j].StatusChangedAt)
+		}
+		return tenants[i].ID.Hex() < tenants[j].ID.Hex()
+	})
+	if q.Limit > 0 && len(tenants) > q.Limit {
+		tenants = tenants[:q.Limit]
+	}
+	return tenants, nil
+}
+
+// FindExpired returns the active and suspended memberships that expired at or
+// before now, soonest expiry first
+func (r *InMemoryUserRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*domain.UserTenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tenants []*domain.UserTenant
+	for _, ut := range r.userTenants {
+		status := ut.CurrentStatus()
+		if (status != domain.MembershipActive && status != domain.MembershipSuspended) || !ut.Expired(now) {
+			continue
+		}
+		tenants = append(tenants, cloneUserTenant(ut))
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		if !tenants[i].ExpiresAt.Equal(*tenants[j].ExpiresAt) {
+			return tenants[i].ExpiresAt.Before(*tenants[j].ExpiresAt)
+		}
+		return tenants[i].ID.Hex() < tenants[j].ID.Hex()
+	})
+	if limit > 0 && len(tenants) > limit {
+		tenants = tenants[:limit]
+	}
+	return tenants, nil
+}
+
+// Touch records that the member was seen at at, keeping a later LastSeenAt
+func (r *InMemoryUserRepository) Touch(ctx context.Context, userID primitive.ObjectID, tenantID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ut, ok := r.userTenants[membershipKey{userID, tenantID}]
+	if !ok || ut.CurrentStatus() != domain.MembershipActive {
+		return false, nil
+	}
+	if ut.LastSeenAt == nil || at.After(*ut.LastSeenAt) {
+		ut.LastSeenAt = &at
+	}
+	return true, nil
+}
+
+// FindInactive returns the active memberships matching q
+func (r *InMemoryUserRepository) FindInactive(ctx context.Context, q InactiveQuery) ([]*domain.UserTenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tenants []*domain.UserTenant
+	for _, ut := range r.userTenants {
+		if ut.TenantID != q.TenantID || ut.CurrentStatus() != domain.MembershipActive ||
+			!ut.InactiveSince(q.TrackedSince).Before(q.SeenBefore) || ut.ID.Hex() <= q.After.Hex() {
+			continue
+		}
+		tenants = append(tenants, cloneUserTenant(ut))
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].ID.Hex() < tenants[j].ID.Hex()
+	})
+	if q.Limit > 0 && len(tenants) > q.Limit {
+		tenants = tenants[:q.Limit]
+	}
+	return tenants, nil
+}
+
+// MarkInactivityWarned sets InactivityWarnedAt of an active membership
+func (r *InMemoryUserRepository) MarkInactivityWarned(ctx context.Context, userID primitive.ObjectID, tenantID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ut, ok := r.userTenants[membershipKey{userID, tenantID}]
+	if !ok || ut.CurrentStatus() != domain.MembershipActive {
+		return false, nil
+	}
+	ut.InactivityWarnedAt = &at
+	return true, nil
+}
+
+// Purge hard deletes the tenant membership of userID if it is still removed
+// and was removed before removedBefore, then deletes the global user once they
+// have no memberships left, and runs then. Both deletes are undone when then fails
+func (r *InMemoryUserRepository) Purge(ctx context.Context, userID primitive.ObjectID, tenantID string, removedBefore time.Time, then PurgeHook) (*PurgeResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := membershipKey{userID, tenantID}
+	ut, ok := r.userTenants[k]
+	if !ok || ut.Status != domain.MembershipRemoved || !ut.StatusChangedAt.Before(removedBefore) {
+		return &PurgeResult{}, nil
+	}
+	delete(r.userTenants, k)
+	result := &PurgeResult{Membership: cloneUserTenant(ut)}
+
+	remaining := false
+	for other := range r.userTenants {
+		if other.userID == userID {
+			remaining = true
+			break
+		}
+	}
+	user, found := r.users[userID]
+	if !remaining && found {
+		delete(r.users, userID)
+		result.UserPurged = true
+	}
+
+	if then != nil {
+		if err := then(&Tx{ctx: ctx}, result); err != nil {
+			r.userTenants[k] = ut
+			if result.UserPurged {
+				r.users[userID] = user
+			}
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (r *InMemoryUserRepository) findByEmailLocked(email string) *domain.User {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u
+		}
+	}
+	return nil
+}
+
+// identifierTakenLocked reports whether another user already holds the
+// email, username or document number of candidate
+func (r *InMemoryUserRepository) identifierTakenLocked(candidate *domain.User) bool {
+	for id, u := range r.users {
+		if id == candidate.ID {
+			continue
+		}
+		if u.Email == candidate.Email ||
+			(candidate.Username != "" && u.Username == candidate.Username) ||
+			(candidate.DocumentNumber != "" && u.DocumentNumber == candidate.DocumentNumber) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemoryUserRepository) sortedUsersLocked() []*domain.User {
+	users := make([]*domain.User, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID.Hex() < users[j].ID.Hex() })
+	return users
+}
+
+// membersLocked joins a tenant's memberships with their users.
+// Memberships whose user document is missing are skipped, like the $unwind stage.
+func (r *InMemoryUserRepository) membersLocked(tenantID string) []*UserWithTenant {
+	var results []*UserWithTenant
+	for key, ut := range r.userTenants {
+		if key.tenantID != tenantID {
+			continue
+		}
+		u, ok := r.users[key.userID]
+		if !ok {
+			continue
+		}
+		results = append(results, &UserWithTenant{User: *cloneUser(u), UserTenant: *cloneUserTenant(ut)})
+	}
+	return results
+}
+
+// pageMembers applies a query's filter, sort and offset or keyset page to members
+func pageMembers(members []*UserWithTenant, q MemberQuery) ([]*UserWithTenant, int64, error) {
+	var matches []*UserWithTenant
+	for _, m := range members {
+		if matchesQuery(m, q) {
+			matches = append(matches, m)
+		}
+	}
+
+	order := q.sortOrDefault()
+	sort.Slice(matches, func(i, j int) bool {
+		return comparePositions(Position(matches[i], order), Position(matches[j], order), order.Desc) < 0
+	})
+
+	var total int64
+	if !q.SkipTotal {
+		total = int64(len(matches))
+	}
+	if q.After == nil {
+		return paginate(matches, q.Page, q.PageSize), total, nil
+	}
+
+	if want := len(Position(&UserWithTenant{}, order).Keys); len(q.After.Keys) != want {
+		return nil, 0, fmt.Errorf("position has %d sort keys, %s needs %d", len(q.After.Keys), order, want)
+	}
+	start := sort.Search(len(matches), func(i int) bool {
+		return comparePositions(Position(matches[i], order), q.After, order.Desc) > 0
+	})
+	return paginate(matches[start:], 1, q.PageSize), total, nil
+}
+
+// matchesQuery reports whether a member is the query's user, if it names
+// one, and matches its filter
+func matchesQuery(m *UserWithTenant, q MemberQuery) bool {
+	return (q.UserID.IsZero() || m.UserTenant.UserID == q.UserID) && matchesFilter(m, q.Filter)
+}
+
+// matchesFilter mirrors the predicates UserRepository compiles from a filter
+func matchesFilter(m *UserWithTenant, f domain.UserFilter) bool {
+	status := m.UserTenant.CurrentStatus()
+	if (f.Status != "" && status != f.Status) || (f.Status == "" && status == domain.MembershipRemoved) {
+		return false
+	}
+	if f.IsActive != nil && m.UserTenant.IsActive != *f.IsActive {
+		return false
+	}
+	if f.Role != "" && !containsString(m.UserTenant.Roles, f.Role) {
+		return false
+	}
+	if f.Tag != "" && !containsString(m.UserTenant.Tags, f.Tag) {
+		return false
+	}
+	if !f.JoinedAt.Contains(m.UserTenant.JoinedAt) || !f.CreatedAt.Contains(m.User.CreatedAt) {
+		return false
+	}
+	if !f.ExpiresAt.IsZero() && (m.UserTenant.ExpiresAt == nil || !f.ExpiresAt.Contains(*m.UserTenant.ExpiresAt)) {
+		return false
+	}
+	if f.EmailDomain != "" && !strings.HasSuffix(strings.ToLower(m.User.Email), "@"+f.EmailDomain) {
+		return false
+	}
+	if f.HasPhone != nil && (m.User.Phone != "") != *f.HasPhone {
+		return false
+	}
+	return true
+}
+
+// comparePositions orders positions by their keys then _id, reversed when desc.
+// Empty strings order first, like the missing fields they stand for in MongoDB
+func comparePositions(a, b *MemberPosition, desc bool) int {
+	c := 0
+	for i := range a.Keys {
+		if c = compareKeys(a.Keys[i], b.Keys[i]); c != 0 {
+			break
+		}
+	}
+	if c == 0 {
+		c = strings.Compare(a.ID.Hex(), b.ID.Hex())
+	}
+	if desc {
+		return -c
+	}
+	return c
+}
+
+func compareKeys(a, b interface{}) int {
+	switch av := a.(type) {
+	case string:
+		bv, _ := b.(string)
+		return strings.Compare(av, bv)
+	case int:
+		bv, _ := b.(int)
+		return av - bv
+	case time.Time:
+		bv, _ := b.(time.Time)
+		return av.Compare(bv)
+	}
+	return 0
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func paginate(results []*UserWithTenant, page, pageSize int) []*UserWithTenant {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	start := (page - 1) * pageSize
+	if start >= len(results) {
+		return nil
+	}
+	end := start + pageSize
+	if end > len(results) {
+		end = len(results)
+	}
+	return results[start:end]
+}
+
+func cloneUser(u *domain.User) *domain.User {
+	c := *u
+	return &c
+}
+
+func cloneUserTenant(ut *domain.UserTenant) *domain.UserTenant {
+	c := *ut
+	c.Roles = append([]string(nil), ut.Roles...)
+	c.Tags = append([]string(nil), ut.Tags...)
+	c.Transitions = append([]domain.MembershipTransition(nil), ut.Transitions...)
+	c.SearchTokens = append([]string(nil), ut.SearchTokens...)
+	c.ExpiresAt = cloneTime(ut.ExpiresAt)
+	c.LastSeenAt = cloneTime(ut.LastSeenAt)
+	c.InactivityWarnedAt = cloneTime(ut.InactivityWarnedAt)
+	return &c
+}
+
+// cloneTime returns a copy of t, or nil
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
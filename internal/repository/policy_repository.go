@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PolicyStore persists each tenant's membership policy
+// PolicyRepository (MongoDB) and InMemoryPolicyRepository both implement it
+// and must pass the conformance suite in policy_store_conformance_test.go
+type PolicyStore interface {
+	// Find returns a tenant's policy, or nil if none was saved
+	Find(ctx context.Context, tenantID string) (*domain.MembershipPolicy, error)
+	// List returns every saved policy ordered by tenant
+	List(ctx context.Context) ([]*domain.MembershipPolicy, error)
+	// Save replaces a tenant's policy, creating it if needed
+	Save(ctx context.Context, policy *domain.MembershipPolicy) error
+}
+
+var (
+	_ PolicyStore = (*PolicyRepository)(nil)
+	_ PolicyStore = (*InMemoryPolicyRepository)(nil)
+)
+
+// PolicyRepository is the MongoDB implementation of PolicyStore
+type PolicyRepository struct {
+	policies *mongo.Collection
+}
+
+// NewPolicyRepository creates a new policy repository
+func NewPolicyRepository(db *mongo.Database) *PolicyRepository {
+	policies := db.Collection("membership_policies")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _ = policies.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenantId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return &PolicyRepository{policies: policies}
+}
+
+// Find returns a tenant's policy
+func (r *PolicyRepository) Find(ctx context.Context, tenantID string) (*domain.MembershipPolicy, error) {
+	var policy domain.MembershipPolicy
+	err := r.policies.FindOne(ctx, bson.M{"tenantId": tenantID}).Decode(&policy)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find membership policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// List returns every saved policy ordered by tenant
+func (r *PolicyRepository) List(ctx context.Context) ([]*domain.MembershipPolicy, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "tenantId", Value: 1}})
+	cursor, err := r.policies.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list membership policies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var policies []*domain.MembershipPolicy
+	if err := cursor.All(ctx, &policies); err != nil {
+		return nil, fmt.Errorf("failed to decode membership policies: %w", err)
+	}
+	return policies, nil
+}
+
+// Save replaces a tenant's policy
+func (r *PolicyRepository) Save(ctx context.Context, policy *domain.MembershipPolicy) error {
+	policy.UpdatedAt = time.Now()
+
+	doc := *policy
+	doc.ID = primitive.NilObjectID // Keep the existing _id on replace
+	res, err := r.policies.ReplaceOne(ctx,
+		bson.M{"tenantId": policy.TenantID},
+		doc,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save membership policy: %w", err)
+	}
+	if id, ok := res.UpsertedID.(primitive.ObjectID); ok {
+		policy.ID = id
+	}
+	return nil
+}
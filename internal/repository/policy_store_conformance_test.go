@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// testPolicyStore is the conformance suite every PolicyStore implementation must pass
+func testPolicyStore(t *testing.T, newStore func(t *testing.T) PolicyStore) {
+	ctx := context.Background()
+
+	t.Run("missing policies are nil", func(t *testing.T) {
+		store := newStore(t)
+		got, err := store.Find(ctx, "tenant-a")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		policies, err := store.List(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, policies)
+	})
+
+	t.Run("save upserts per tenant", func(t *testing.T) {
+		store := newStore(t)
+		policy := &domain.MembershipPolicy{TenantID: "tenant-b", RetentionDays: 90}
+		require.NoError(t, store.Save(ctx, policy))
+		assert.False(t, policy.ID.IsZero())
+		firstID := policy.ID
+		require.NoError(t, store.Save(ctx, &domain.MembershipPolicy{TenantID: "tenant-a", RetentionDays: 7}))
+
+		require.NoError(t, store.Save(ctx, &domain.MembershipPolicy{TenantID: "tenant-b", RetentionDays: 14}))
+		got, err := store.Find(ctx, "tenant-b")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, firstID, got.ID)
+		assert.Equal(t, 14, got.RetentionDays)
+		assert.False(t, got.UpdatedAt.IsZero())
+
+		policies, err := store.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, policies, 2)
+		assert.Equal(t, "tenant-a", policies[0].TenantID)
+		assert.Equal(t, "tenant-b", policies[1].TenantID)
+	})
+}
+
+func TestInMemoryPolicyRepository(t *testing.T) {
+	testPolicyStore(t, func(t *testing.T) PolicyStore {
+		return NewInMemoryPolicyRepository()
+	})
+}
+
+// TestPolicyRepository runs the conformance suite against a real MongoDB.
+func TestPolicyRepository(t *testing.T) {
+	testPolicyStore(t, func(t *testing.T) PolicyStore {
+		return NewPolicyRepository(newTestDatabase(t))
+	})
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PreferencesStore persists per-user, per-tenant preferences and each tenant's defaults
+// PreferencesRepository (MongoDB) and InMemoryPreferencesRepository both implement
+// it and must pass the conformance suite in preferences_store_conformance_test.go
+type PreferencesStore interface {
+	// Find returns a user's stored preferences in a tenant, or nil if none were saved
+	Find(ctx context.Context, userID, tenantID string) (*domain.UserPreferences, error)
+	// Save replaces a user's stored preferences in a tenant, creating them if needed
+	Save(ctx context.Context, prefs *domain.UserPreferences) error
+	// Delete removes a user's stored preferences in a tenant, if any
+	Delete(ctx context.Context, userID, tenantID string) error
+	// FindTenantDefaults returns a tenant's preference defaults, or nil if none were saved
+	FindTenantDefaults(ctx context.Context, tenantID string) (*domain.TenantPreferences, error)
+	// SaveTenantDefaults replaces a tenant's preference defaults, creating them if needed
+	SaveTenantDefaults(ctx context.Context, prefs *domain.TenantPreferences) error
+}
+
+var (
+	_ PreferencesStore = (*PreferencesRepository)(nil)
+	_ PreferencesStore = (*InMemoryPreferencesRepository)(nil)
+)
+
+// PreferencesRepository is the MongoDB implementation of PreferencesStore
+type PreferencesRepository struct {
+	preferences       *mongo.Collection
+	tenantPreferences *mongo.Collection
+}
+
+// NewPreferencesRepository creates a new preferences repository
+func NewPreferencesRepository(db *mongo.Database) *PreferencesRepository {
+	// Decode nested settings as maps rather than ordered documents so they
+	// serialize back to plain JSON objects
+	collOpts := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+	preferences := db.Collection("user_preferences", collOpts)
+	tenantPreferences := db.Collection("tenant_preferences", collOpts)
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	preferenceIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "tenantId", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, _ = preferences.Indexes().CreateMany(ctx, preferenceIndexes)
+
+	_, _ = tenantPreferences.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenantId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return &PreferencesRepository{
+		preferences:       preferences,
+		tenantPreferences: tenantPreferences,
+	}
+}
+
+// Find returns a user's stored preferences in a tenant
+func (r *PreferencesRepository) Find(ctx context.Context, userID, tenantID string) (*domain.UserPreferences, error) {
+	var prefs domain.UserPreferences
+	err := r.preferences.FindOne(ctx, bson.M{"userId": userID, "tenantId": tenantID}).Decode(&prefs)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// Save replaces a user's stored preferences in a tenant
+func (r *PreferencesRepository) Save(ctx context.Context, prefs *domain.UserPreferences) error {
+	prefs.UpdatedAt = time.Now()
+
+	doc := *prefs
+	doc.ID = primitive.NilObjectID // Keep the existing _id on replace
+	res, err := r.preferences.ReplaceOne(ctx,
+		bson.M{"userId": prefs.UserID, "tenantId": prefs.TenantID},
+		doc,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	if id, ok := res.UpsertedID.(primitive.ObjectID); ok {
+		prefs.ID = id
+	}
+	return nil
+}
+
+// Delete removes a user's stored preferences in a tenant
+func (r *PreferencesRepository) Delete(ctx context.Context, userID, tenantID string) error {
+	if _, err := r.preferences.DeleteOne(ctx, bson.M{"userId": userID, "tenantId": tenantID}); err != nil {
+		return fmt.Errorf("failed to delete preferences: %w", err)
+	}
+	return nil
+}
+
+// FindTenantDefaults returns a tenant's preference defaults
+func (r *PreferencesRepository) FindTenantDefaults(ctx context.Context, tenantID string) (*domain.TenantPreferences, error) {
+	var prefs domain.TenantPreferences
+	err := r.tenantPreferences.FindOne(ctx, bson.M{"tenantId": tenantID}).Decode(&prefs)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find tenant preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// SaveTenantDefaults replaces a tenant's preference defaults
+func (r *PreferencesRepository) SaveTenantDefaults(ctx context.Context, prefs *domain.TenantPreferences) error {
+	prefs.UpdatedAt = time.Now()
+
+	doc := *prefs
+	doc.ID = primitive.NilObjectID // Keep the existing _id on replace
+	res, err := r.tenantPreferences.ReplaceOne(ctx,
+		bson.M{"tenantId": prefs.TenantID},
+		doc,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save tenant preferences: %w", err)
+	}
+	if id, ok := res.UpsertedID.(primitive.ObjectID); ok {
+		prefs.ID = id
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// testPreferencesStore is the conformance suite every PreferencesStore implementation must pass
+func testPreferencesStore(t *testing.T, newStore func(t *testing.T) PreferencesStore) {
+	ctx := context.Background()
+
+	t.Run("missing preferences are nil", func(t *testing.T) {
+		store := newStore(t)
+		prefs, err := store.Find(ctx, "user-1", "tenant-a")
+		require.NoError(t, err)
+		assert.Nil(t, prefs)
+	})
+
+	t.Run("save upserts per user and tenant", func(t *testing.T) {
+		store := newStore(t)
+		prefs := &domain.UserPreferences{
+			UserID:   "user-1",
+			TenantID: "tenant-a",
+			Language: "vi",
+			Settings: map[string]interface{}{
+				"notifications": map[string]interface{}{"email": true, "channels": []interface{}{"sms"}},
+			},
+		}
+		require.NoError(t, store.Save(ctx, prefs))
+		assert.False(t, prefs.ID.IsZero())
+		firstID := prefs.ID
+
+		got, err := store.Find(ctx, "user-1", "tenant-a")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "vi", got.Language)
+		assert.False(t, got.UpdatedAt.IsZero())
+		notifications, ok := got.Settings["notifications"].(map[string]interface{})
+		if !ok {
+			// MongoDB decodes nested documents as primitive.M
+			notifications = map[string]interface{}(got.Settings["notifications"].(interface {
+				Map() map[string]interface{}
+			}).Map())
+		}
+		assert.Equal(t, true, notifications["email"])
+
+		// Replacing keeps the document identity and drops unset fields
+		require.NoError(t, store.Save(ctx, &domain.UserPreferences{UserID: "user-1", TenantID: "tenant-a", Theme: "dark"}))
+		got, err = store.Find(ctx, "user-1", "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, firstID, got.ID)
+		assert.Equal(t, "", got.Language)
+		assert.Equal(t, "dark", got.Theme)
+		assert.Empty(t, got.Settings)
+
+		other, err := store.Find(ctx, "user-1", "tenant-b")
+		require.NoError(t, err)
+		assert.Nil(t, other)
+
+		require.NoError(t, store.Delete(ctx, "user-1", "tenant-a"))
+		require.NoError(t, store.Delete(ctx, "user-1", "tenant-a"))
+		got, err = store.Find(ctx, "user-1", "tenant-a")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("tenant defaults upsert per tenant", func(t *testing.T) {
+		store := newStore(t)
+		defaults, err := store.FindTenantDefaults(ctx, "tenant-a")
+		require.NoError(t, err)
+		assert.Nil(t, defaults)
+
+		require.NoError(t, store.SaveTenantDefaults(ctx, &domain.TenantPreferences{
+			TenantID: "tenant-a",
+			Language: "vi",
+			Timezone: "Asia/Ho_Chi_Minh",
+			Locked:   []string{"timezone"},
+		}))
+		require.NoError(t, store.SaveTenantDefaults(ctx, &domain.TenantPreferences{
+			TenantID: "tenant-a",
+			Language: "vi",
+			Locked:   []string{"language", "settings.notifications"},
+		}))
+
+		defaults, err = store.FindTenantDefaults(ctx, "tenant-a")
+		require.NoError(t, err)
+		require.NotNil(t, defaults)
+		assert.Equal(t, "vi", defaults.Language)
+		assert.Equal(t, "", defaults.Timezone)
+		assert.Equal(t, []string{"language", "settings.notifications"}, defaults.Locked)
+		assert.False(t, defaults.UpdatedAt.IsZero())
+
+		other, err := store.FindTenantDefaults(ctx, "tenant-b")
+		require.NoError(t, err)
+		assert.Nil(t, other)
+	})
+}
+
+func TestInMemoryPreferencesRepository(t *testing.T) {
+	testPreferencesStore(t, func(t *testing.T) PreferencesStore {
+		return NewInMemoryPreferencesRepository()
+	})
+}
+
+// TestPreferencesRepository runs the conformance suite against a real MongoDB.
+func TestPreferencesRepository(t *testing.T) {
+	testPreferencesStore(t, func(t *testing.T) PreferencesStore {
+		return NewPreferencesRepository(newTestDatabase(t))
+	})
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrRoleExists is returned when a tenant already has a role with the same key
+var ErrRoleExists = errors.New("role already exists in this tenant")
+
+// RoleStore persists each tenant's custom role catalog
+// RoleRepository (MongoDB) and InMemoryRoleRepository both implement it and must
+// pass the conformance suite in role_store_conformance_test.go
+type RoleStore interface {
+	Create(ctx context.Context, role *domain.Role) error
+	FindByKey(ctx context.Context, tenantID, key string) (*domain.Role, error)
+	// List returns every custom role of a tenant ordered by key
+	List(ctx context.Context, tenantID string) ([]*domain.Role, error)
+	Update(ctx context.Context, role *domain.Role) error
+	Delete(ctx context.Context, tenantID, key string) error
+}
+
+var (
+	_ RoleStore = (*RoleRepository)(nil)
+	_ RoleStore = (*InMemoryRoleRepository)(nil)
+)
+
+// RoleRepository is the MongoDB implementation of RoleStore
+type RoleRepository struct {
+	roles *mongo.Collection
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *mongo.Database) *RoleRepository {
+	roles := db.Collection("roles")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	roleIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "key", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, _ = roles.Indexes().CreateMany(ctx, roleIndexes)
+
+	return &RoleRepository{roles: roles}
+}
+
+// Create stores a new custom role
+func (r *RoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	now := time.Now()
+	role.CreatedAt = now
+	role.UpdatedAt = now
+
+	res, err := r.roles.InsertOne(ctx, role)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrRoleExists
+		}
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+	role.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByKey finds a tenant's role by key
+func (r *RoleRepository) FindByKey(ctx context.Context, tenantID, key string) (*domain.Role, error) {
+	var role domain.Role
+	err := r.roles.FindOne(ctx, bson.M{"tenantId": tenantID, "key": key}).Decode(&role)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find role: %w", err)
+	}
+	return &role, nil
+}
+
+// List returns every custom role of a tenant ordered by key
+func (r *RoleRepository) List(ctx context.Context, tenantID string) ([]*domain.Role, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "key", Value: 1}})
+	cursor, err := r.roles.Find(ctx, bson.M{"tenantId": tenantID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var roles []*domain.Role
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, fmt.Errorf("failed to decode roles: %w", err)
+	}
+	return roles, nil
+}
+
+// Update saves a role's display name, description and permissions
+func (r *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	role.UpdatedAt = time.Now()
+
+	_, err := r.roles.UpdateOne(ctx,
+		bson.M{"tenantId": role.TenantID, "key": role.Key},
+		bson.M{"$set": bson.M{
+			"displayName": role.DisplayName,
+			"description": role.Description,
+			"permissions": role.Permissions,
+			"updatedAt":   role.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a role from a tenant's catalog
+func (r *RoleRepository) Delete(ctx context.Context, tenantID, key string) error {
+	if _, err := r.roles.DeleteOne(ctx, bson.M{"tenantId": tenantID, "key": key}); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// testRoleStore is the conformance suite every RoleStore implementation must pass
+func testRoleStore(t *testing.T, newStore func(t *testing.T) RoleStore) {
+	ctx := context.Background()
+
+	t.Run("create, find and list per tenant", func(t *testing.T) {
+		store := newStore(t)
+		for _, role := range []*domain.Role{
+			{TenantID: "tenant-a", Key: "warehouse-lead", DisplayName: "Warehouse Lead", Permissions: []string{"users:update:any"}},
+			{TenantID: "tenant-a", Key: "accountant", DisplayName: "Kế toán"},
+			{TenantID: "tenant-b", Key: "accountant", DisplayName: "Accountant"},
+		} {
+			require.NoError(t, store.Create(ctx, role))
+			assert.False(t, role.ID.IsZero())
+		}
+
+		got, err := store.FindByKey(ctx, "tenant-a", "accountant")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "Kế toán", got.DisplayName)
+
+		roles, err := store.List(ctx, "tenant-a")
+		require.NoError(t, err)
+		require.Len(t, roles, 2)
+		assert.Equal(t, "accountant", roles[0].Key)
+		assert.Equal(t, "warehouse-lead", roles[1].Key)
+
+		missing, err := store.FindByKey(ctx, "tenant-c", "accountant")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("keys are unique per tenant", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Create(ctx, &domain.Role{TenantID: "tenant-a", Key: "auditor", DisplayName: "Auditor"}))
+		err := store.Create(ctx, &domain.Role{TenantID: "tenant-a", Key: "auditor", DisplayName: "Other"})
+		assert.ErrorIs(t, err, ErrRoleExists)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		store := newStore(t)
+		role := &domain.Role{TenantID: "tenant-a", Key: "support", DisplayName: "Support"}
+		require.NoError(t, store.Create(ctx, role))
+
+		role.DisplayName = "Customer Support"
+		role.Permissions = []string{"users:create"}
+		require.NoError(t, store.Update(ctx, role))
+
+		got, err := store.FindByKey(ctx, "tenant-a", "support")
+		require.NoError(t, err)
+		assert.Equal(t, "Customer Support", got.DisplayName)
+		assert.Equal(t, []string{"users:create"}, got.Permissions)
+
+		require.NoError(t, store.Delete(ctx, "tenant-a", "support"))
+		got, err = store.FindByKey(ctx, "tenant-a", "support")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func TestInMemoryRoleRepository(t *testing.T) {
+	testRoleStore(t, func(t *testing.T) RoleStore {
+		return NewInMemoryRoleRepository()
+	})
+}
+
+// TestRoleRepository runs the conformance suite against a real MongoDB.
+func TestRoleRepository(t *testing.T) {
+	testRoleStore(t, func(t *testing.T) RoleStore {
+		return NewRoleRepository(newTestDatabase(t))
+	})
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSegmentExists is returned when a tenant already has a segment with the same key
+var ErrSegmentExists = errors.New("segment already exists in this tenant")
+
+// SegmentStore persists each tenant's saved member segments
+// SegmentRepository (MongoDB) and InMemorySegmentRepository both implement it
+// and must pass the conformance suite in segment_store_conformance_test.go
+type SegmentStore interface {
+	Create(ctx context.Context, segment *domain.Segment) error
+	FindByKey(ctx context.Context, tenantID, key string) (*domain.Segment, error)
+	// List returns every segment of a tenant ordered by key
+	List(ctx context.Context, tenantID string) ([]*domain.Segment, error)
+	Update(ctx context.Context, segment *domain.Segment) error
+	Delete(ctx context.Context, tenantID, key string) error
+}
+
+var (
+	_ SegmentStore = (*SegmentRepository)(nil)
+	_ SegmentStore = (*InMemorySegmentRepository)(nil)
+)
+
+// SegmentRepository is the MongoDB implementation of SegmentStore
+type SegmentRepository struct {
+	segments *mongo.Collection
+}
+
+// NewSegmentRepository creates a new segment repository
+func NewSegmentRepository(db *mongo.Database) *SegmentRepository {
+	segments := db.Collection("segments")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	segmentIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "key", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, _ = segments.Indexes().CreateMany(ctx, segmentIndexes)
+
+	return &SegmentRepository{segments: segments}
+}
+
+// Create stores a new segment
+func (r *SegmentRepository) Create(ctx context.Context, segment *domain.Segment) error {
+	now := time.Now()
+	segment.CreatedAt = now
+	segment.UpdatedAt = now
+
+	res, err := r.segments.InsertOne(ctx, segment)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrSegmentExists
+		}
+		return fmt.Errorf("failed to create segment: %w", err)
+	}
+	segment.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByKey finds a tenant's segment by key
+func (r *SegmentRepository) FindByKey(ctx context.Context, tenantID, key string) (*domain.Segment, error) {
+	var segment domain.Segment
+	err := r.segments.FindOne(ctx, bson.M{"tenantId": tenantID, "key": key}).Decode(&segment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find segment: %w", err)
+	}
+	return &segment, nil
+}
+
+// List returns every segment of a tenant ordered by key
+func (r *SegmentRepository) List(ctx context.Context, tenantID string) ([]*domain.Segment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "key", Value: 1}})
+	cursor, err := r.segments.Find(ctx, bson.M{"tenantId": tenantID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var segments []*domain.Segment
+	if err := cursor.All(ctx, &segments); err != nil {
+		return nil, fmt.Errorf("failed to decode segments: %w", err)
+	}
+	return segments, nil
+}
+
+// Update saves a segment's name, description and filter
+func (r *SegmentRepository) Update(ctx context.Context, segment *domain.Segment) error {
+	segment.UpdatedAt = time.Now()
+
+	_, err := r.segments.UpdateOne(ctx,
+		bson.M{"tenantId": segment.TenantID, "key": segment.Key},
+		bson.M{"$set": bson.M{
+			"name":        segment.Name,
+			"description": segment.Description,
+			"filter":      segment.Filter,
+			"updatedAt":   segment.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update segment: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a segment from a tenant
+func (r *SegmentRepository) Delete(ctx context.Context, tenantID, key string) error {
+	if _, err := r.segments.DeleteOne(ctx, bson.M{"tenantId": tenantID, "key": key}); err != nil {
+		return fmt.Errorf("failed to delete segment: %w", err)
+	}
+	return nil
+}
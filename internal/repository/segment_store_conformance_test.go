@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// testSegmentStore is the conformance suite every SegmentStore implementation must pass
+func testSegmentStore(t *testing.T, newStore func(t *testing.T) SegmentStore) {
+	ctx := context.Background()
+
+	t.Run("create, find and list per tenant", func(t *testing.T) {
+		store := newStore(t)
+		for _, segment := range []*domain.Segment{
+			{TenantID: "tenant-a", Key: "inactive-contractors", Name: "Inactive contractors", Filter: "isActive:false,role:contractor"},
+			{TenantID: "tenant-a", Key: "admins", Name: "Admins", Filter: "role:admin"},
+			{TenantID: "tenant-b", Key: "admins", Name: "Admins"},
+		} {
+			require.NoError(t, store.Create(ctx, segment))
+			assert.False(t, segment.ID.IsZero())
+			assert.False(t, segment.CreatedAt.IsZero())
+		}
+
+		got, err := store.FindByKey(ctx, "tenant-a", "inactive-contractors")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "isActive:false,role:contractor", got.Filter)
+
+		segments, err := store.List(ctx, "tenant-a")
+		require.NoError(t, err)
+		require.Len(t, segments, 2)
+		assert.Equal(t, "admins", segments[0].Key)
+		assert.Equal(t, "inactive-contractors", segments[1].Key)
+
+		missing, err := store.FindByKey(ctx, "tenant-c", "admins")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("keys are unique per tenant", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Create(ctx, &domain.Segment{TenantID: "tenant-a", Key: "vip", Name: "VIP"}))
+		err := store.Create(ctx, &domain.Segment{TenantID: "tenant-a", Key: "vip", Name: "Other"})
+		assert.ErrorIs(t, err, ErrSegmentExists)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		store := newStore(t)
+		segment := &domain.Segment{TenantID: "tenant-a", Key: "new-joiners", Name: "New joiners", Filter: "joinedAt>=2024-01-01"}
+		require.NoError(t, store.Create(ctx, segment))
+
+		segment.Name = "Joined this year"
+		segment.Filter = "joinedAt>=2025-01-01"
+		require.NoError(t, store.Update(ctx, segment))
+
+		got, err := store.FindByKey(ctx, "tenant-a", "new-joiners")
+		require.NoError(t, err)
+		assert.Equal(t, "Joined this year", got.Name)
+		assert.Equal(t, "joinedAt>=2025-01-01", got.Filter)
+
+		require.NoError(t, store.Delete(ctx, "tenant-a", "new-joiners"))
+		got, err = store.FindByKey(ctx, "tenant-a", "new-joiners")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func TestInMemorySegmentRepository(t *testing.T) {
+	testSegmentStore(t, func(t *testing.T) SegmentStore {
+		return NewInMemorySegmentRepository()
+	})
+}
+
+// TestSegmentRepository runs the conformance suite against a real MongoDB.
+func TestSegmentRepository(t *testing.T) {
+	testSegmentStore(t, func(t *testing.T) SegmentStore {
+		return NewSegmentRepository(newTestDatabase(t))
+	})
+}
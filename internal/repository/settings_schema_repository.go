@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SettingsSchemaStore persists JSON Schemas for preference settings namespaces
+// Platform schemas are stored with an empty tenant ID.
+// SettingsSchemaRepository (MongoDB) and InMemorySettingsSchemaRepository both
+// implement it and must pass the conformance suite in settings_schema_store_conformance_test.go
+type SettingsSchemaStore interface {
+	// Save creates or replaces the schema of a namespace
+	Save(ctx context.Context, schema *domain.SettingsSchema) error
+	// Find returns the schema of a namespace, or nil if none is registered
+	Find(ctx context.Context, tenantID, namespace string) (*domain.SettingsSchema, error)
+	// List returns every schema registered for a tenant ordered by namespace
+	List(ctx context.Context, tenantID string) ([]*domain.SettingsSchema, error)
+	// Delete removes the schema of a namespace and reports whether one existed
+	Delete(ctx context.Context, tenantID, namespace string) (bool, error)
+}
+
+var (
+	_ SettingsSchemaStore = (*SettingsSchemaRepository)(nil)
+	_ SettingsSchemaStore = (*InMemorySettingsSchemaRepository)(nil)
+)
+
+// SettingsSchemaRepository is the MongoDB implementation of SettingsSchemaStore
+type SettingsSchemaRepository struct {
+	schemas *mongo.Collection
+}
+
+// NewSettingsSchemaRepository creates a new settings schema repository
+func NewSettingsSchemaRepository(db *mongo.Database) *SettingsSchemaRepository {
+	schemas := db.Collection("settings_schemas")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schemaIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "namespace", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, _ = schemas.Indexes().CreateMany(ctx, schemaIndexes)
+
+	return &SettingsSchemaRepository{schemas: schemas}
+}
+
+// Save creates or replaces the schema of a namespace
+func (r *SettingsSchemaRepository) Save(ctx context.Context, schema *domain.SettingsSchema) error {
+	now := time.Now()
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.schemas.FindOneAndUpdate(ctx,
+		bson.M{"tenantId": schema.TenantID, "namespace": schema.Namespace},
+		bson.M{
+			"$set":         bson.M{"schema": schema.Schema, "updatedAt": now},
+			"$setOnInsert": bson.M{"createdAt": now},
+		},
+		opts,
+	).Decode(schema)
+	if err != nil {
+		return fmt.Errorf("failed to save settings schema: %w", err)
+	}
+	return nil
+}
+
+// Find returns the schema of a namespace
+func (r *SettingsSchemaRepository) Find(ctx context.Context, tenantID, namespace string) (*domain.SettingsSchema, error) {
+	var schema domain.SettingsSchema
+	err := r.schemas.FindOne(ctx, bson.M{"tenantId": tenantID, "namespace": namespace}).Decode(&schema)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find settings schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// List returns every schema registered for a tenant ordered by namespace
+func (r *SettingsSchemaRepository) List(ctx context.Context, tenantID string) ([]*domain.SettingsSchema, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "namespace", Value: 1}})
+	cursor, err := r.schemas.Find(ctx, bson.M{"tenantId": tenantID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settings schemas: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var schemas []*domain.SettingsSchema
+	if err := cursor.All(ctx, &schemas); err != nil {
+		return nil, fmt.Errorf("failed to decode settings schemas: %w", err)
+	}
+	return schemas, nil
+}
+
+// Delete removes the schema of a namespace
+func (r *SettingsSchemaRepository) Delete(ctx context.Context, tenantID, namespace string) (bool, error) {
+	res, err := r.schemas.DeleteOne(ctx, bson.M{"tenantId": tenantID, "namespace": namespace})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete settings schema: %w", err)
+	}
+	return res.DeletedCount > 0, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// testSettingsSchemaStore is the conformance suite every SettingsSchemaStore implementation must pass
+func testSettingsSchemaStore(t *testing.T, newStore func(t *testing.T) SettingsSchemaStore) {
+	ctx := context.Background()
+
+	t.Run("save, find and replace", func(t *testing.T) {
+		store := newStore(t)
+		schema := &domain.SettingsSchema{Namespace: "notifications", Schema: `{"type":"object"}`}
+		require.NoError(t, store.Save(ctx, schema))
+		assert.False(t, schema.ID.IsZero())
+		assert.False(t, schema.CreatedAt.IsZero())
+		createdAt := schema.CreatedAt
+
+		replaced := &domain.SettingsSchema{Namespace: "notifications", Schema: `{"$id":"n","type":"object"}`}
+		require.NoError(t, store.Save(ctx, replaced))
+		assert.Equal(t, schema.ID, replaced.ID)
+		assert.WithinDuration(t, createdAt, replaced.CreatedAt, time.Millisecond)
+
+		got, err := store.Find(ctx, "", "notifications")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, `{"$id":"n","type":"object"}`, got.Schema)
+
+		missing, err := store.Find(ctx, "tenant-a", "notifications")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("list is per tenant and ordered by namespace", func(t *testing.T) {
+		store := newStore(t)
+		for _, s := range []*domain.SettingsSchema{
+			{TenantID: "tenant-a", Namespace: "notifications", Schema: "{}"},
+			{TenantID: "tenant-a", Namespace: "dashboard", Schema: "{}"},
+			{Namespace: "billing", Schema: "{}"},
+		} {
+			require.NoError(t, store.Save(ctx, s))
+		}
+
+		schemas, err := store.List(ctx, "tenant-a")
+		require.NoError(t, err)
+		require.Len(t, schemas, 2)
+		assert.Equal(t, "dashboard", schemas[0].Namespace)
+		assert.Equal(t, "notifications", schemas[1].Namespace)
+
+		platform, err := store.List(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, platform, 1)
+		assert.Equal(t, "billing", platform[0].Namespace)
+	})
+
+	t.Run("delete reports whether a schema existed", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Save(ctx, &domain.SettingsSchema{TenantID: "tenant-a", Namespace: "dashboard", Schema: "{}"}))
+
+		deleted, err := store.Delete(ctx, "tenant-a", "dashboard")
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		deleted, err = store.Delete(ctx, "tenant-a", "dashboard")
+		require.NoError(t, err)
+		assert.False(t, deleted)
+	})
+}
+
+func TestInMemorySettingsSchemaRepository(t *testing.T) {
+	testSettingsSchemaStore(t, func(t *testing.T) SettingsSchemaStore {
+		return NewInMemorySettingsSchemaRepository()
+	})
+}
+
+// TestSettingsSchemaRepository runs the conformance suite against a real MongoDB.
+func TestSettingsSchemaRepository(t *testing.T) {
+	testSettingsSchemaStore(t, func(t *testing.T) SettingsSchemaStore {
+		return NewSettingsSchemaRepository(newTestDatabase(t))
+	})
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TokenStore persists opaque access tokens by their hash
+// It is an interface so a Redis-backed store can replace MongoDB
+type TokenStore interface {
+	Create(ctx context.Context, token *domain.AccessToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*domain.AccessToken, error)
+	Revoke(ctx context.Context, tokenHash string) (bool, error)
+}
+
+// TokenRepository is the MongoDB implementation of TokenStore
+type TokenRepository struct {
+	tokens *mongo.Collection
+}
+
+// NewTokenRepository creates a new token repository
+func NewTokenRepository(db *mongo.Database) *TokenRepository {
+	tokens := db.Collection("access_tokens")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tokenIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+		{
+			// MongoDB removes documents once expiresAt has passed
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, _ = tokens.Indexes().CreateMany(ctx, tokenIndexes)
+
+	return &TokenRepository{tokens: tokens}
+}
+
+// Create stores a new token
+func (r *TokenRepository) Create(ctx context.Context, token *domain.AccessToken) error {
+	token.CreatedAt = time.Now()
+
+	if _, err := r.tokens.InsertOne(ctx, token); err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+	return nil
+}
+
+// FindByHash finds a token by its hash
+// Expired tokens may still be returned until the TTL monitor removes them
+func (r *TokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.AccessToken, error) {
+	var token domain.AccessToken
+	err := r.tokens.FindOne(ctx, bson.M{"tokenHash": tokenHash}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find token: %w", err)
+	}
+	return &token, nil
+}
+
+// Revoke marks a token as revoked, reporting whether a live token was found
+func (r *TokenRepository) Revoke(ctx context.Context, tokenHash string) (bool, error) {
+	res, err := r.tokens.UpdateOne(ctx,
+		bson.M{"tokenHash": tokenHash, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now()}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return res.MatchedCount > 0, nil
+}
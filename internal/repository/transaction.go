@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Tx is handed to multi-document write functions run by txRunner.
+// Writes must use Context() so they join the session transaction when one is active.
+type Tx struct {
+	ctx           context.Context
+	transactional bool
+	compensations []func(ctx context.Context) error
+}
+
+// Context returns the context writes must be issued with
+func (tx *Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// OnRollback registers an undo step for a write that has already been applied.
+// Undo steps only run when transactions are unavailable and a later write fails;
+// inside a real transaction the server discards the writes instead.
+func (tx *Tx) OnRollback(undo func(ctx context.Context) error) {
+	if tx.transactional {
+		return
+	}
+	tx.compensations = append(tx.compensations, undo)
+}
+
+// rollback replays the registered undo steps in reverse order
+func (tx *Tx) rollback(ctx context.Context, cause error) error {
+	var undoErr error
+	for i := len(tx.compensations) - 1; i >= 0; i-- {
+		if err := tx.compensations[i](ctx); err != nil && undoErr == nil {
+			undoErr = err
+		}
+	}
+	if undoErr != nil {
+		return fmt.Errorf("%w (rollback failed: %v)", cause, undoErr)
+	}
+	return cause
+}
+
+// txRunner runs multi-document writes atomically: inside a session transaction
+// when the deployment supports it (replica set or sharded cluster), otherwise
+// with compensating rollback of the writes that already succeeded.
+type txRunner struct {
+	client    *mongo.Client
+	supported bool
+}
+
+// newTxRunner detects whether the deployment behind db supports transactions
+func newTxRunner(ctx context.Context, db *mongo.Database) *txRunner {
+	return &txRunner{
+		client:    db.Client(),
+		supported: supportsTransactions(ctx, db),
+	}
+}
+
+// Run executes fn atomically. fn may be retried on transient transaction errors,
+// so it must not have side effects outside the database.
+func (r *txRunner) Run(ctx context.Context, fn func(tx *Tx) error) error {
+	if !r.supported {
+		tx := &Tx{ctx: ctx}
+		if err := fn(tx); err != nil {
+			return tx.rollback(ctx, err)
+		}
+		return nil
+	}
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(&Tx{ctx: sessCtx, transactional: true})
+	})
+	return err
+}
+
+// supportsTransactions reports whether the server is a replica set member or mongos
+func supportsTransactions(ctx context.Context, db *mongo.Database) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		// Servers older than 4.4.2 only understand isMaster
+		err = db.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello)
+	}
+	if err != nil {
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxRunnerCompensatesWithoutTransactions(t *testing.T) {
+	runner := &txRunner{supported: false}
+	ctx := context.Background()
+
+	t.Run("undo steps run in reverse order on failure", func(t *testing.T) {
+		var undone []string
+		cause := errors.New("second write failed")
+
+		err := runner.Run(ctx, func(tx *Tx) error {
+			tx.OnRollback(func(context.Context) error { undone = append(undone, "first"); return nil })
+			tx.OnRollback(func(context.Context) error { undone = append(undone, "second"); return nil })
+			return cause
+		})
+
+		assert.ErrorIs(t, err, cause)
+		assert.Equal(t, []string{"second", "first"}, undone)
+	})
+
+	t.Run("undo steps are skipped on success", func(t *testing.T) {
+		called := false
+		err := runner.Run(ctx, func(tx *Tx) error {
+			tx.OnRollback(func(context.Context) error { called = true; return nil })
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.False(t, called)
+	})
+
+	t.Run("failed undo is reported alongside the cause", func(t *testing.T) {
+		err := runner.Run(ctx, func(tx *Tx) error {
+			tx.OnRollback(func(context.Context) error { return errors.New("delete failed") })
+			return ErrUserTenantExists
+		})
+
+		assert.ErrorIs(t, err, ErrUserTenantExists)
+		assert.Contains(t, err.Error(), "rollback failed: delete failed")
+	})
+}
+
+func TestTxIgnoresUndoStepsInsideTransactions(t *testing.T) {
+	tx := &Tx{ctx: context.Background(), transactional: true}
+	tx.OnRollback(func(context.Context) error { return nil })
+	assert.Empty(t, tx.compensations)
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/vhvplatform/go-user-service/internal/domain"
@@ -12,10 +14,19 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	// ErrUserTenantExists is returned when a user is already linked to a tenant
+	ErrUserTenantExists = errors.New("user already in tenant")
+
+	// ErrIdentifierTaken is returned when a username or document number belongs to another user
+	ErrIdentifierTaken = errors.New("identifier already in use")
+)
+
 // UserRepository handles user data access
 type UserRepository struct {
 	users       *mongo.Collection
 	userTenants *mongo.Collection
+	tx          *txRunner
 }
 
 // NewUserRepository creates a new user repository
@@ -32,6 +43,15 @@ func NewUserRepository(db *mongo.Database) *UserRepository {
 			Keys:    bson.D{{Key: "email", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "documentNumber", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{Keys: bson.D{{Key: "phone", Value: 1}}},
 	}
 	_, _ = users.Indexes().CreateMany(ctx, userIndexes)
 
@@ -45,9 +65,78 @@ func NewUserRepository(db *mongo.Database) *UserRepository {
 		},
 		{Keys: bson.D{{Key: "tenantId", Value: 1}}},
 		{
+			// Role counts and role filters in the default listing order
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "roles", Value: 1},
+				{Key: "joinedAt", Value: -1},
+			},
+		},
+		{
+			// Tag filters and the tag inventory
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "tags", Value: 1},
+			},
+		},
+		{
+			// Member listing order and keyset pagination
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "joinedAt", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			// isActive filter in the default listing order
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "isActive", Value: 1},
+				{Key: "joinedAt", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			// Sorting by name
 			Keys: bson.D{
-				{Key: "firstName", Value: "text"},
-				{Key: "lastName", Value: "text"},
+				{Key: "tenantId", Value: 1},
+				{Key: "firstName", Value: 1},
+				{Key: "lastName", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+		},
+		{
+			// Prefix search on folded name words
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "searchTokens", Value: 1},
+			},
+		},
+		{
+			// Removed memberships due for purging
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "statusChangedAt", Value: 1},
+			},
+		},
+		{
+			// Expired memberships due for deactivation
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Expiring members of a tenant, soonest first
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "expiresAt", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+		},
+		{
+			// Inactive members of a tenant
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "lastSeenAt", Value: 1},
 			},
 		},
 	}
@@ -56,47 +145,140 @@ func NewUserRepository(db *mongo.Database) *UserRepository {
 	return &UserRepository{
 		users:       users,
 		userTenants: userTenants,
+		tx:          newTxRunner(ctx, db),
 	}
 }
 
-// Create transactionally creates a user and tenant association
-// Note: MongoDB transactions require replica set. Assuming support or using best-effort.
-// Implementation here uses independent creates for simplicity in this environment.
+// Create atomically upserts the global user by email and links it to the tenant.
+// Inside a replica set both writes share a session transaction; on a standalone
+// server a failed link insert rolls the user upsert back, so a user and their
+// first membership are never left half-created and a duplicate link does not
+// bump updatedAt.
 func (r *UserRepository) Create(ctx context.Context, user *domain.User, userTenant *domain.UserTenant) error {
-	// 1. Upsert User (Global)
-	// We check if user exists by email. If so, use existing ID.
-	filter := bson.M{"email": user.Email}
-	update := bson.M{
-		"$setOnInsert": bson.M{
+	var savedUser domain.User
+	var linkID primitive.ObjectID
+	var joinedAt time.Time
+
+	err := r.tx.Run(ctx, func(tx *Tx) error {
+		now := time.Now()
+
+		// 1. Upsert User (Global)
+		// We check if user exists by email. If so, use existing ID.
+		// The _id is chosen up front so an insert can be told apart from a match.
+		newID := primitive.NewObjectID()
+		filter := bson.M{"email": user.Email}
+		setOnInsert := bson.M{
+			"_id":       newID,
 			"email":     user.Email,
 			"phone":     user.Phone,
 			"avatarUrl": user.AvatarURL,
 			"isActive":  user.IsActive,
-			"createdAt": time.Now(),
-		},
-		"$set": bson.M{"updatedAt": time.Now()},
-	}
-	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+			"createdAt": now,
+		}
+		// Sparse unique fields must be omitted rather than stored empty
+		if user.Username != "" {
+			setOnInsert["username"] = user.Username
+		}
+		if user.DocumentNumber != "" {
+			setOnInsert["documentNumber"] = user.DocumentNumber
+		}
+		if user.PasswordHash != "" {
+			setOnInsert["passwordHash"] = user.PasswordHash
+		}
+		update := bson.M{
+			"$setOnInsert": setOnInsert,
+			"$set":         bson.M{"updatedAt": now},
+		}
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
 
-	var savedUser domain.User
-	err := r.users.FindOneAndUpdate(ctx, filter, update, opts).Decode(&savedUser)
+		var previous domain.User
+		err := r.users.FindOneAndUpdate(tx.Context(), filter, update, opts).Decode(&previous)
+		switch {
+		case err == mongo.ErrNoDocuments:
+			// Inserted a new global user
+			savedUser = domain.User{
+				ID:             newID,
+				Email:          user.Email,
+				Username:       user.Username,
+				DocumentNumber: user.DocumentNumber,
+				PasswordHash:   user.PasswordHash,
+				Phone:          user.Phone,
+				AvatarURL:      user.AvatarURL,
+				IsActive:       user.IsActive,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			}
+			tx.OnRollback(func(ctx context.Context) error {
+				_, err := r.users.DeleteOne(ctx, bson.M{"_id": newID})
+				return err
+			})
+		case err != nil:
+			if mongo.IsDuplicateKeyError(err) {
+				return ErrIdentifierTaken
+			}
+			return fmt.Errorf("failed to upsert user: %w", err)
+		default:
+			// Matched an existing global user
+			savedUser = previous
+			savedUser.UpdatedAt = now
+			tx.OnRollback(func(ctx context.Context) error {
+				// Only restore updatedAt if nobody has written the user since
+				_, err := r.users.UpdateOne(ctx,
+					bson.M{"_id": previous.ID, "updatedAt": now},
+					bson.M{"$set": bson.M{"updatedAt": previous.UpdatedAt}},
+				)
+				return err
+			})
+		}
+
+		// 2. Insert UserTenant
+		setSearchFields(userTenant)
+		initStatus(userTenant, now)
+		link := *userTenant
+		link.ID = primitive.NewObjectID()
+		link.UserID = savedUser.ID
+		link.JoinedAt = now
+		if _, err := r.userTenants.InsertOne(tx.Context(), &link); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return ErrUserTenantExists
+			}
+			return fmt.Errorf("failed to create user tenant link: %w", err)
+		}
+		linkID = link.ID
+		joinedAt = now
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to upsert user: %w", err)
+		return err
 	}
 
-	// Update input user with ID
-	user.ID = savedUser.ID
+	// Update inputs with the stored documents
+	*user = savedUser
+	userTenant.ID = linkID
 	userTenant.UserID = savedUser.ID
+	userTenant.JoinedAt = joinedAt
+
+	return nil
+}
+
+// AddToTenant links an existing global user to a tenant
+func (r *UserRepository) AddToTenant(ctx context.Context, userTenant *domain.UserTenant) error {
+	userTenant.ID = primitive.NilObjectID
 	userTenant.JoinedAt = time.Now()
+	setSearchFields(userTenant)
+	initStatus(userTenant, userTenant.JoinedAt)
 
-	// 2. Insert UserTenant
-	_, err = r.userTenants.InsertOne(ctx, userTenant)
+	res, err := r.userTenants.InsertOne(ctx, userTenant)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
-			return fmt.Errorf("user already in tenant")
+			return ErrUserTenantExists
 		}
 		return fmt.Errorf("failed to create user tenant link: %w", err)
 	}
+	if id, ok := res.InsertedID.(primitive.ObjectID); ok {
+		userTenant.ID = id
+	}
 
 	return nil
 }
@@ -105,6 +287,8 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User, userTena
 type UserWithTenant struct {
 	User       domain.User       `bson:"user"`
 	UserTenant domain.UserTenant `bson:",inline"`
+	// Score is the search relevance; zero when listing
+	Score int `bson:"score,omitempty"`
 }
 
 // FindByID finds a user by ID and tenant
@@ -160,77 +344,198 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email, tenantID string
 	return &u, &ut, nil
 }
 
-// List lists users for a tenant with pagination
-func (r *UserRepository) List(ctx context.Context, tenantID string, page, pageSize int) ([]*UserWithTenant, int64, error) {
-	if page < 1 {
-		page = 1
+// FindUserByID finds a global user by ID regardless of tenant
+func (r *UserRepository) FindUserByID(ctx context.Context, id string) (*domain.User, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
-	if pageSize < 1 {
-		pageSize = 20
+
+	var u domain.User
+	err = r.users.FindOne(ctx, bson.M{"_id": userID}).Decode(&u)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
-	skip := (page - 1) * pageSize
 
-	// Count on UserTenant
-	total, err := r.userTenants.CountDocuments(ctx, bson.M{"tenantId": tenantID})
+	return &u, nil
+}
+
+// FindByIdentifier finds a global user by email, username, phone or document number
+// An empty identifierType matches any of the non-email identifiers
+func (r *UserRepository) FindByIdentifier(ctx context.Context, identifier, identifierType string) (*domain.User, error) {
+	var filter bson.M
+	switch identifierType {
+	case "email":
+		filter = bson.M{"email": identifier}
+	case "username":
+		filter = bson.M{"username": identifier}
+	case "phone":
+		filter = bson.M{"phone": identifier}
+	case "document_number":
+		filter = bson.M{"documentNumber": identifier}
+	default:
+		filter = bson.M{"$or": bson.A{
+			bson.M{"username": identifier},
+			bson.M{"phone": identifier},
+			bson.M{"documentNumber": identifier},
+		}}
+	}
+
+	var u domain.User
+	err := r.users.FindOne(ctx, filter).Decode(&u)
 	if err != nil {
-		return nil, 0, err
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find user by identifier: %w", err)
 	}
 
-	// Aggregation to join Users
+	return &u, nil
+}
+
+// FindTenants lists every tenant membership of a user
+func (r *UserRepository) FindTenants(ctx context.Context, id string) ([]*domain.UserTenant, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "joinedAt", Value: 1}})
+	cursor, err := r.userTenants.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user tenants: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []*domain.UserTenant
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// CountMembersWithRole counts a tenant's memberships holding role
+func (r *UserRepository) CountMembersWithRole(ctx context.Context, tenantID, role string) (int64, error) {
+	count, err := r.userTenants.CountDocuments(ctx, bson.M{"tenantId": tenantID, "roles": role})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count members with role: %w", err)
+	}
+	return count, nil
+}
+
+// AddTags adds tags to the tenant's memberships of userIDs
+func (r *UserRepository) AddTags(ctx context.Context, tenantID string, userIDs []primitive.ObjectID, tags []string) (int64, error) {
+	res, err := r.userTenants.UpdateMany(ctx,
+		bson.M{"tenantId": tenantID, "userId": bson.M{"$in": userIDs}},
+		bson.M{"$addToSet": bson.M{"tags": bson.M{"$each": tags}}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add tags: %w", err)
+	}
+	return res.MatchedCount, nil
+}
+
+// RemoveTags removes tags from the tenant's memberships of userIDs
+func (r *UserRepository) RemoveTags(ctx context.Context, tenantID string, userIDs []primitive.ObjectID, tags []string) (int64, error) {
+	res, err := r.userTenants.UpdateMany(ctx,
+		bson.M{"tenantId": tenantID, "userId": bson.M{"$in": userIDs}},
+		bson.M{"$pullAll": bson.M{"tags": tags}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove tags: %w", err)
+	}
+	return res.MatchedCount, nil
+}
+
+// CountTags counts the tenant's memberships carrying each tag
+func (r *UserRepository) CountTags(ctx context.Context, tenantID string) ([]domain.TagCount, error) {
 	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{"tenantId": tenantID}}},
-		{{Key: "$sort", Value: bson.M{"joinedAt": -1}}},
-		{{Key: "$skip", Value: int64(skip)}},
-		{{Key: "$limit", Value: int64(pageSize)}},
-		{{Key: "$lookup", Value: bson.M{
-			"from":         "users",
-			"localField":   "userId",
-			"foreignField": "_id",
-			"as":           "user_docs",
-		}}},
-		{{Key: "$unwind", Value: "$user_docs"}},
-		{{Key: "$project", Value: bson.M{
-			"user":   "$user_docs",
-			"userId": 1, "tenantId": 1, "roles": 1, "firstName": 1, "lastName": 1, "isActive": 1, "joinedAt": 1,
-		}}},
+		{{Key: "$match", Value: bson.M{"tenantId": tenantID, "tags.0": bson.M{"$exists": true}}}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "tag": "$_id", "count": 1}}},
 	}
 
 	cursor, err := r.userTenants.Aggregate(ctx, pipeline)
 	if err != nil {
-		return nil, 0, err
+		return nil, fmt.Errorf("failed to count tags: %w", err)
 	}
 	defer cursor.Close(ctx)
 
-	var results []*UserWithTenant
-	if err := cursor.All(ctx, &results); err != nil {
-		return nil, 0, err
+	var counts []domain.TagCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
 	}
+	return counts, nil
+}
 
-	return results, total, nil
+// List lists users for a tenant with pagination, newest members first
+func (r *UserRepository) List(ctx context.Context, q MemberQuery) ([]*UserWithTenant, int64, error) {
+	return r.members(ctx, memberSelect{match: bson.M{"tenantId": q.TenantID}}, q)
 }
 
 // Update updates user and tenant info
 func (r *UserRepository) Update(ctx context.Context, user *domain.User, userTenant *domain.UserTenant) error {
-	// Update User
-	if user != nil {
-		user.UpdatedAt = time.Now()
-		_, err := r.users.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": user})
-		if err != nil {
-			return err
+	// Both documents change together or not at all
+	return r.tx.Run(ctx, func(tx *Tx) error {
+		// Update User
+		if user != nil {
+			user.UpdatedAt = time.Now()
+			var previous domain.User
+			err := r.users.FindOneAndUpdate(tx.Context(), bson.M{"_id": user.ID}, bson.M{"$set": user}).Decode(&previous)
+			if err != nil && err != mongo.ErrNoDocuments {
+				if mongo.IsDuplicateKeyError(err) {
+					return ErrIdentifierTaken
+				}
+				return err
+			}
+			if err == nil {
+				tx.OnRollback(func(ctx context.Context) error {
+					_, err := r.users.ReplaceOne(ctx, bson.M{"_id": previous.ID}, previous)
+					return err
+				})
+			}
 		}
-	}
 
-	// Update UserTenant
-	if userTenant != nil {
-		_, err := r.userTenants.UpdateOne(ctx,
-			bson.M{"userId": userTenant.UserID, "tenantId": userTenant.TenantID},
-			bson.M{"$set": userTenant},
-		)
-		if err != nil {
-			return err
+		// Update UserTenant; only the editable fields are written, so a status
+		// change, tags or activity recorded since it was read are kept
+		if userTenant != nil {
+			update := membershipUpdate(userTenant)
+			_, err := r.userTenants.UpdateOne(tx.Context(),
+				bson.M{"userId": userTenant.UserID, "tenantId": userTenant.TenantID},
+				update,
+			)
+			if err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+}
+
+// membershipUpdate returns the update writing a membership's names, roles and
+// expiry. The search fields are derived from the names and a cleared expiry is unset
+func membershipUpdate(ut *domain.UserTenant) bson.M {
+	setSearchFields(ut)
+	set := bson.M{
+		"firstName":    ut.FirstName,
+		"lastName":     ut.LastName,
+		"searchName":   ut.SearchName,
+		"searchTokens": ut.SearchTokens,
+		"roles":        ut.Roles,
 	}
-	return nil
+	update := bson.M{"$set": set}
+	if ut.ExpiresAt != nil {
+		set["expiresAt"] = ut.ExpiresAt
+	} else {
+		update["$unset"] = bson.M{"expiresAt": ""}
+	}
+	return update
 }
 
 // Delete soft deletes a user from a tenant
@@ -242,31 +547,355 @@ func (r *UserRepository) Delete(ctx context.Context, id, tenantID string) error
 
 	_, err = r.userTenants.UpdateOne(ctx,
 		bson.M{"userId": userID, "tenantId": tenantID},
-		bson.M{"$set": bson.M{"isActive": false}},
+		transitionUpdate(domain.MembershipTransition{To: domain.MembershipRemoved, At: time.Now()}),
 	)
 	return err
 }
 
-// Search searches users
-func (r *UserRepository) Search(ctx context.Context, tenantID, query string, page, pageSize int) ([]*UserWithTenant, int64, error) {
-	skip := (page - 1) * pageSize
+// Search searches users by the kind of query: user ID, email, phone number or
+// free text (see planSearch), best matches first unless q sets another sort
+func (r *UserRepository) Search(ctx context.Context, q MemberQuery, text string) ([]*UserWithTenant, int64, error) {
+	sel, ok, err := r.searchSelect(ctx, q.TenantID, text)
+	if err != nil || !ok {
+		return nil, 0, err
+	}
+	if q.Sort.Field == "" {
+		q.Sort = domain.RelevanceUserSort
+	}
+	return r.members(ctx, sel, q)
+}
 
-	// Search in UserTenant (firstName, lastName)
-	match := bson.M{
-		"tenantId": tenantID,
-		"$text":    bson.M{"$search": query},
+// searchSelect selects the members of a tenant matching a search, or reports
+// false when text can match nothing. Users matching by identifier are looked
+// up first, so only their memberships and name matches are joined and scored
+func (r *UserRepository) searchSelect(ctx context.Context, tenantID, text string) (memberSelect, bool, error) {
+	plan := planSearch(text)
+	if plan.empty() {
+		return memberSelect{}, false, nil
 	}
+	found := []primitive.ObjectID{}
+	if len(plan.lookups) > 0 {
+		var err error
+		if found, err = r.findSearchUsers(ctx, tenantID, plan); err != nil {
+			return memberSelect{}, false, err
+		}
+	}
+	return memberSelect{
+		match:          plan.match(tenantID, found),
+		score:          plan.scoreExpr(found),
+		scoreReadsUser: plan.readsUser(),
+	}, true, nil
+}
 
-	// Count
-	total, err := r.userTenants.CountDocuments(ctx, match)
+// findSearchUsers returns the members of a tenant the plan's lookups find.
+// The lookups run on the users indexes, and each found user's membership is
+// read through the (userId, tenantId) index
+func (r *UserRepository) findSearchUsers(ctx context.Context, tenantID string, plan searchPlan) ([]primitive.ObjectID, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: plan.userMatch()}},
+		{{Key: "$project", Value: bson.M{"_id": 1}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "user_tenants",
+			"let":  bson.M{"userId": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"tenantId": tenantID}},
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$userId", "$$userId"}}}},
+				bson.M{"$project": bson.M{"_id": 1}},
+			},
+			"as": "membership",
+		}}},
+		{{Key: "$match", Value: bson.M{"membership": bson.M{"$ne": bson.A{}}}}},
+	}
+
+	cursor, err := r.users.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users by identifier: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	found := []primitive.ObjectID{}
+	for cursor.Next(ctx) {
+		var user struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			return nil, err
+		}
+		found = append(found, user.ID)
+	}
+	return found, cursor.Err()
+}
+
+// Count counts the tenant's members matching q, joining users only when the
+// filter reads them
+func (r *UserRepository) Count(ctx context.Context, q MemberQuery) (int64, error) {
+	sel := memberSelect{match: bson.M{"tenantId": q.TenantID}}
+	stages, _, postMatch := memberStages(sel, q, false)
+	if !postMatch {
+		return r.userTenants.CountDocuments(ctx, sel.match)
+	}
+	return r.countStages(ctx, stages)
+}
+
+// Facets counts the matching members by each facet with a single $facet stage
+// over the same selection as List or Search
+func (r *UserRepository) Facets(ctx context.Context, q MemberQuery, text string, facets []string) (domain.UserFacets, error) {
+	sel := memberSelect{match: bson.M{"tenantId": q.TenantID}}
+	if text != "" {
+		var ok bool
+		var err error
+		if sel, ok, err = r.searchSelect(ctx, q.TenantID, text); err != nil {
+			return nil, err
+		}
+		if !ok {
+			return countFacets(nil, facets), nil
+		}
+	}
+
+	pipeline, _, _ := memberStages(sel, q, containsString(facets, domain.UserFacetEmailDomain))
+	stages := bson.M{}
+	for _, facet := range facets {
+		stages[facet] = facetPipeline(facet)
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: stages}})
+
+	cursor, err := r.userTenants.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count facets: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []map[string][]facetBucket
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	counts := make(domain.UserFacets, len(facets))
+	for _, facet := range facets {
+		var buckets []facetBucket
+		if len(results) > 0 {
+			buckets = results[0][facet]
+		}
+		counts[facet] = toFacetCounts(buckets)
+	}
+	return counts, nil
+}
+
+// Suggest returns autocomplete suggestions in a single aggregation: name
+// matches from the (tenantId, searchTokens) index are unioned with memberships
+// of users found by email prefix, then ranked and joined for the projection
+func (r *UserRepository) Suggest(ctx context.Context, tenantID, prefix string, limit int) ([]*domain.MemberSuggestion, error) {
+	plan := planSuggest(prefix)
+	if len(plan.terms) == 0 && len(plan.emails) == 0 {
+		return []*domain.MemberSuggestion{}, nil
+	}
+	active := bson.M{"tenantId": tenantID, "isActive": true}
+
+	// Memberships of users whose email matches, run on users
+	var byEmail mongo.Pipeline
+	if len(plan.emails) > 0 {
+		byEmail = mongo.Pipeline{
+			{{Key: "$match", Value: plan.emailMatch()}},
+			{{Key: "$lookup", Value: bson.M{
+				"from": "user_tenants",
+				"let":  bson.M{"userId": "$_id"},
+				"pipeline": bson.A{
+					bson.M{"$match": active},
+					bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$userId", "$$userId"}}}},
+				},
+				"as": "membership",
+			}}},
+			{{Key: "$unwind", Value: "$membership"}},
+			{{Key: "$limit", Value: int64(limit)}},
+			{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$membership"}}},
+		}
+	}
+
+	var pipeline mongo.Pipeline
+	aggregate := r.userTenants
+	if len(plan.terms) > 0 {
+		nameMatch := plan.nameMatch()
+		for k, v := range active {
+			nameMatch[k] = v
+		}
+		pipeline = mongo.Pipeline{
+			{{Key: "$match", Value: nameMatch}},
+			{{Key: "$limit", Value: int64(limit * suggestCandidates)}},
+		}
+		if byEmail != nil {
+			pipeline = append(pipeline,
+				bson.D{{Key: "$unionWith", Value: bson.M{"coll": "users", "pipeline": byEmail}}},
+				// A member matching both ways is listed once
+				bson.D{{Key: "$group", Value: bson.M{"_id": "$_id", "doc": bson.M{"$first": "$$ROOT"}}}},
+				bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$doc"}}},
+			)
+		}
+	} else {
+		aggregate = r.users
+		pipeline = byEmail
+	}
+
+	pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: bson.M{"score": nameScoreExpr(plan.terms, plan.phrase)}}})
+	pipeline = append(pipeline, userJoin()...)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$match", Value: bson.M{"user_docs.isActive": true}}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "score", Value: -1},
+			{Key: "firstName", Value: 1},
+			{Key: "lastName", Value: 1},
+			{Key: "_id", Value: 1},
+		}}},
+		bson.D{{Key: "$limit", Value: int64(limit)}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"userId": 1, "firstName": 1, "lastName": 1,
+			"user": bson.M{"email": "$user_docs.email", "avatarUrl": "$user_docs.avatarUrl"},
+		}}},
+	)
+
+	cursor, err := aggregate.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest members: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []*UserWithTenant
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	suggestions := make([]*domain.MemberSuggestion, len(results))
+	for i, m := range results {
+		suggestions[i] = toSuggestion(m)
+	}
+	return suggestions, nil
+}
+
+// memberSelect selects the memberships a listing pages through
+type memberSelect struct {
+	// match holds predicates on stored membership fields
+	match bson.M
+	// score computes the relevance of a search, nil when listing; members
+	// scoring 0 are left out
+	score bson.M
+	// scoreReadsUser is set when score reads joined user fields
+	scoreReadsUser bool
+}
+
+// members returns the page of selected memberships matching the query's
+// filter, joined with their users. Membership filters, and for membership
+// sort keys the sort and page as well, run before the $lookup so they can use
+// the user_tenants indexes; filters, sorts and scores on user fields run
+// after the join
+func (r *UserRepository) members(ctx context.Context, sel memberSelect, q MemberQuery) ([]*UserWithTenant, int64, error) {
+	page, pageSize := q.Page, q.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	sort := q.sortOrDefault()
+
+	filter, joinFirst, postMatch := memberStages(sel, q, sort.Field == domain.UserSortEmail || sort.Field == domain.UserSortCreatedAt)
+
+	// Count on UserTenant alone unless the selection needs more stages
+	var total int64
+	if !q.SkipTotal {
+		var err error
+		if !postMatch {
+			total, err = r.userTenants.CountDocuments(ctx, sel.match)
+		} else {
+			total, err = r.countStages(ctx, filter)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	pipeline := append(mongo.Pipeline{}, filter...)
+
+	// Keyset pagination continues strictly after the cursor position
+	paths := sortPaths(sort.Field)
+	if q.After != nil {
+		if len(q.After.Keys) != len(paths) {
+			return nil, 0, fmt.Errorf("position has %d sort keys, %s needs %d", len(q.After.Keys), sort, len(paths))
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: keysetFilter(paths, q.After, sort.Desc)}})
+	}
+
+	direction := 1
+	if sort.Desc {
+		direction = -1
+	}
+	order := bson.D{}
+	for _, path := range paths {
+		order = append(order, bson.E{Key: path, Value: direction})
+	}
+	order = append(order, bson.E{Key: "_id", Value: direction})
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: order}})
+	if q.After == nil {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: int64((page - 1) * pageSize)}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$limit", Value: int64(pageSize)}})
+
+	// Aggregation to join Users
+	if !joinFirst {
+		pipeline = append(pipeline, userJoin()...)
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.M{
+		"user":   "$user_docs",
+		"userId": 1, "tenantId": 1, "roles": 1, "firstName": 1, "lastName": 1, "isActive": 1, "joinedAt": 1, "score": 1,
+		"status": 1, "statusChangedAt": 1, "tags": 1, "expiresAt": 1, "lastSeenAt": 1,
+	}}})
+
+	cursor, err := r.userTenants.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, 0, err
 	}
+	defer cursor.Close(ctx)
 
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: match}},
-		{{Key: "$skip", Value: int64(skip)}},
-		{{Key: "$limit", Value: int64(pageSize)}},
+	var results []*UserWithTenant
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// memberStages returns the stages selecting the members of sel that match q's
+// user and filter, whose membership predicates it adds to sel.match. Users
+// are joined when the selection reads them or joinUser is set; joined reports
+// whether they were. postMatch reports whether a $match follows the initial
+// one, so that counting needs the stages rather than sel.match alone
+func memberStages(sel memberSelect, q MemberQuery, joinUser bool) (stages mongo.Pipeline, joined, postMatch bool) {
+	if !q.UserID.IsZero() {
+		sel.match["userId"] = q.UserID
+	}
+	addMemberFilter(sel.match, q.Filter)
+	userMatch := userFilter(q.Filter)
+	joined = joinUser || len(userMatch) > 0 || sel.scoreReadsUser
+	addScore := bson.D{{Key: "$addFields", Value: bson.M{"score": sel.score}}}
+
+	// User filters may read joined or computed fields, so they follow them;
+	// MongoDB moves predicates on stored membership fields ahead
+	stages = mongo.Pipeline{{{Key: "$match", Value: sel.match}}}
+	if sel.score != nil && !sel.scoreReadsUser {
+		stages = append(stages, addScore)
+	}
+	if joined {
+		stages = append(stages, userJoin()...)
+	}
+	if sel.scoreReadsUser {
+		stages = append(stages, addScore)
+		userMatch["score"] = bson.M{"$gt": 0}
+	}
+	if len(userMatch) > 0 {
+		stages = append(stages, bson.D{{Key: "$match", Value: userMatch}})
+	}
+	return stages, joined, len(userMatch) > 0
+}
+
+// userJoin returns the stages joining each membership with its user as user_docs
+func userJoin() []bson.D {
+	return []bson.D{
 		{{Key: "$lookup", Value: bson.M{
 			"from":         "users",
 			"localField":   "userId",
@@ -274,22 +903,158 @@ func (r *UserRepository) Search(ctx context.Context, tenantID, query string, pag
 			"as":           "user_docs",
 		}}},
 		{{Key: "$unwind", Value: "$user_docs"}},
-		{{Key: "$project", Value: bson.M{
-			"user":   "$user_docs",
-			"userId": 1, "tenantId": 1, "roles": 1, "firstName": 1, "lastName": 1, "isActive": 1, "joinedAt": 1,
-		}}},
 	}
+}
+
+// countStages counts the documents an aggregation pipeline outputs
+func (r *UserRepository) countStages(ctx context.Context, stages mongo.Pipeline) (int64, error) {
+	pipeline := append(append(mongo.Pipeline{}, stages...), bson.D{{Key: "$count", Value: "total"}})
 
 	cursor, err := r.userTenants.Aggregate(ctx, pipeline)
 	if err != nil {
-		return nil, 0, err
+		return 0, err
 	}
 	defer cursor.Close(ctx)
 
-	var results []*UserWithTenant
-	if err := cursor.All(ctx, &results); err != nil {
-		return nil, 0, err
+	var counts []struct {
+		Total int64 `bson:"total"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return 0, err
 	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	return counts[0].Total, nil
+}
 
-	return results, total, nil
+// addMemberFilter adds the filter's membership predicates to match
+func addMemberFilter(match bson.M, f domain.UserFilter) {
+	// statusMatch also covers memberships the status backfill has not reached
+	if f.Status != "" {
+		match["$and"] = bson.A{statusMatch(f.Status)}
+	} else {
+		match["$nor"] = bson.A{statusMatch(domain.MembershipRemoved)}
+	}
+	if f.IsActive != nil {
+		match["isActive"] = *f.IsActive
+	}
+	if f.Role != "" {
+		match["roles"] = f.Role
+	}
+	if f.Tag != "" {
+		match["tags"] = f.Tag
+	}
+	if !f.JoinedAt.IsZero() {
+		match["joinedAt"] = timeRange(f.JoinedAt)
+	}
+	if !f.ExpiresAt.IsZero() {
+		match["expiresAt"] = timeRange(f.ExpiresAt)
+	}
+}
+
+// userFilter returns the filter's predicates on the joined user document
+func userFilter(f domain.UserFilter) bson.M {
+	match := bson.M{}
+	if !f.CreatedAt.IsZero() {
+		match["user_docs.createdAt"] = timeRange(f.CreatedAt)
+	}
+	if f.EmailDomain != "" {
+		match["user_docs.email"] = primitive.Regex{Pattern: "@" + regexp.QuoteMeta(f.EmailDomain) + "$", Options: "i"}
+	}
+	if f.HasPhone != nil {
+		// Phones are stored empty or omitted when unset
+		if *f.HasPhone {
+			match["user_docs.phone"] = bson.M{"$nin": bson.A{nil, ""}}
+		} else {
+			match["user_docs.phone"] = bson.M{"$in": bson.A{nil, ""}}
+		}
+	}
+	return match
+}
+
+func timeRange(tr domain.TimeRange) bson.M {
+	m := bson.M{}
+	if tr.Gt != nil {
+		m["$gt"] = *tr.Gt
+	}
+	if tr.Gte != nil {
+		m["$gte"] = *tr.Gte
+	}
+	if tr.Lt != nil {
+		m["$lt"] = *tr.Lt
+	}
+	if tr.Lte != nil {
+		m["$lte"] = *tr.Lte
+	}
+	return m
+}
+
+// sortPaths returns the pipeline paths a sort field orders by, before _id
+func sortPaths(field string) []string {
+	switch field {
+	case domain.UserSortName:
+		return []string{"firstName", "lastName"}
+	case domain.UserSortEmail:
+		return []string{"user_docs.email"}
+	case domain.UserSortCreatedAt:
+		return []string{"user_docs.createdAt"}
+	case domain.UserSortRelevance:
+		return []string{"score", "joinedAt"}
+	case domain.UserSortExpiresAt:
+		return []string{"expiresAt"}
+	}
+	return []string{"joinedAt"}
+}
+
+// keysetFilter matches documents ordered strictly after pos by paths then _id
+func keysetFilter(paths []string, pos *MemberPosition, desc bool) bson.M {
+	var or bson.A
+	for i := 0; i <= len(paths); i++ {
+		var and bson.A
+		for j := 0; j < i; j++ {
+			and = append(and, keyEquals(paths[j], pos.Keys[j]))
+		}
+		if i < len(paths) {
+			after := keyAfter(paths[i], pos.Keys[i], desc)
+			if after == nil {
+				continue
+			}
+			and = append(and, after)
+		} else if desc {
+			and = append(and, bson.M{"_id": bson.M{"$lt": pos.ID}})
+		} else {
+			and = append(and, bson.M{"_id": bson.M{"$gt": pos.ID}})
+		}
+		or = append(or, bson.M{"$and": and})
+	}
+	return bson.M{"$or": or}
+}
+
+// keyEquals matches values equal to value. Names are omitted from documents
+// when empty, so an empty string key stands for a missing field, which MongoDB
+// orders before every string
+func keyEquals(path string, value interface{}) bson.M {
+	if s, ok := value.(string); ok && s == "" {
+		return bson.M{path: nil}
+	}
+	return bson.M{path: value}
+}
+
+// keyAfter matches values ordered strictly after value, or returns nil if none can be
+func keyAfter(path string, value interface{}, desc bool) bson.M {
+	if s, ok := value.(string); ok {
+		switch {
+		case s == "" && desc:
+			return nil
+		case s == "":
+			return bson.M{path: bson.M{"$type": "string"}}
+		case desc:
+			return bson.M{"$or": bson.A{bson.M{path: bson.M{"$lt": s}}, bson.M{path: nil}}}
+		}
+	}
+	if desc {
+		return bson.M{path: bson.M{"$lt": value}}
+	}
+	return bson.M{path: bson.M{"$gt": value}}
 }
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemberQuery selects a page of a tenant's members for List and Search.
+// Members are ordered by Sort (newest first when zero), ties broken by the
+// membership _id. Without After, Page and PageSize select an offset page; with
+// After, keyset pagination returns up to PageSize members ordered strictly
+// after that position and Page is ignored.
+type MemberQuery struct {
+	TenantID string
+	// UserID restricts the query to one user's membership when set
+	UserID   primitive.ObjectID
+	Filter   domain.UserFilter
+	Sort     domain.UserSort
+	Page     int
+	PageSize int
+	After    *MemberPosition
+	// SkipTotal skips counting the matching members; the returned total is 0
+	SkipTotal bool
+}
+
+// MemberPosition is a member's place in a sort order: the values of the sort
+// keys (string, int or time.Time) followed by the membership _id
+type MemberPosition struct {
+	Keys []interface{}
+	ID   primitive.ObjectID
+}
+
+// Position returns the position of a member in the given sort order
+func Position(m *UserWithTenant, sort domain.UserSort) *MemberPosition {
+	var keys []interface{}
+	switch sort.Field {
+	case domain.UserSortName:
+		keys = []interface{}{m.UserTenant.FirstName, m.UserTenant.LastName}
+	case domain.UserSortEmail:
+		keys = []interface{}{m.User.Email}
+	case domain.UserSortCreatedAt:
+		keys = []interface{}{m.User.CreatedAt}
+	case domain.UserSortRelevance:
+		keys = []interface{}{m.Score, m.UserTenant.JoinedAt}
+	case domain.UserSortExpiresAt:
+		var expiresAt time.Time
+		if m.UserTenant.ExpiresAt != nil {
+			expiresAt = *m.UserTenant.ExpiresAt
+		}
+		keys = []interface{}{expiresAt}
+	default:
+		keys = []interface{}{m.UserTenant.JoinedAt}
+	}
+	return &MemberPosition{Keys: keys, ID: m.UserTenant.ID}
+}
+
+// sortOrDefault returns the query's sort, newest members first when unset
+func (q MemberQuery) sortOrDefault() domain.UserSort {
+	if q.Sort.Field == "" {
+		return domain.DefaultUserSort
+	}
+	return q.Sort
+}
+
+// UserStore is the persistence contract for global users and their tenant memberships.
+// UserRepository (MongoDB) and InMemoryUserRepository both implement it and must pass
+// the shared conformance suite in user_store_conformance_test.go.
+//
+// Lookups return nil results (not an error) when nothing matches. Duplicate memberships
+// are reported as ErrUserTenantExists and duplicate usernames, document numbers or
+// emails as ErrIdentifierTaken. Delete is a soft delete that moves the membership to
+// domain.MembershipRemoved. Memberships created without a status take the one
+// domain.UserTenant.CurrentStatus derives from IsActive.
+type UserStore interface {
+	// Create upserts the global user by email and links it to userTenant.TenantID
+	Create(ctx context.Context, user *domain.User, userTenant *domain.UserTenant) error
+	AddToTenant(ctx context.Context, userTenant *domain.UserTenant) error
+
+	FindByID(ctx context.Context, id, tenantID string) (*domain.User, *domain.UserTenant, error)
+	FindByEmail(ctx context.Context, email, tenantID string) (*domain.User, *domain.UserTenant, error)
+	FindUserByID(ctx context.Context, id string) (*domain.User, error)
+	FindByIdentifier(ctx context.Context, identifier, identifierType string) (*domain.User, error)
+	FindTenants(ctx context.Context, id string) ([]*domain.UserTenant, error)
+	// CountMembersWithRole counts a tenant's memberships (active or not) holding role
+	CountMembersWithRole(ctx context.Context, tenantID, role string) (int64, error)
+	// AddTags adds tags to the tenant's memberships of userIDs, keeping tags they
+	// already carry, and returns how many memberships matched
+	AddTags(ctx context.Context, tenantID string, userIDs []primitive.ObjectID, tags []string) (int64, error)
+	// RemoveTags removes tags from the tenant's memberships of userIDs and returns
+	// how many memberships matched
+	RemoveTags(ctx context.Context, tenantID string, userIDs []primitive.ObjectID, tags []string) (int64, error)
+	// CountTags counts the tenant's memberships (active or not) carrying each tag,
+	// most used first, then by tag
+	CountTags(ctx context.Context, tenantID string) ([]domain.TagCount, error)
+
+	// List returns a page of the tenant's members and the number of members
+	List(ctx context.Context, q MemberQuery) ([]*UserWithTenant, int64, error)
+	// Search is List restricted to members matching text, which is read as a user
+	// ID, an email, a phone number or names depending on its shape (see planSearch).
+	// Without a sort, best matches come first and UserWithTenant.Score holds the relevance
+	Search(ctx context.Context, q MemberQuery, text string) ([]*UserWithTenant, int64, error)
+	// Count counts the members List matches for q's tenant, user and filter
+	Count(ctx context.Context, q MemberQuery) (int64, error)
+	// Facets counts the members List, or Search when text is not empty, matches for
+	// q's tenant and filter by each of facets (domain.UserFacet*)
+	Facets(ctx context.Context, q MemberQuery, text string, facets []string) (domain.UserFacets, error)
+	// Suggest returns up to limit active members of an active user whose name words
+	// each start with a word of prefix, ignoring case and diacritics, or whose email
+	// starts with prefix. Closer name matches come first, then by name
+	Suggest(ctx context.Context, tenantID, prefix string, limit int) ([]*domain.MemberSuggestion, error)
+
+	// Update writes the user and the membership's names, roles and expiry. Status,
+	// tags and activity are left alone; they change through their own methods
+	Update(ctx context.Context, user *domain.User, userTenant *domain.UserTenant) error
+	Delete(ctx context.Context, id, tenantID string) error
+	// SetStatus applies t to the tenant membership of userID, keeping the most recent
+	// transitions, and reports false when the membership is not in status from,
+	// because it does not exist or its status changed in the meantime
+	SetStatus(ctx context.Context, userID primitive.ObjectID, tenantID string, from domain.MembershipStatus, t domain.MembershipTransition) (bool, error)
+	// Restore applies t to the removed tenant membership of userID and gives it roles
+	// and expiresAt in one write, reporting false when the membership is not removed
+	Restore(ctx context.Context, userID primitive.ObjectID, tenantID string, roles []string, expiresAt *time.Time, t domain.MembershipTransition) (bool, error)
+	// FindExpired returns up to limit active or suspended memberships whose
+	// ExpiresAt is at or before now, soonest expiry first
+	FindExpired(ctx context.Context, now time.Time, limit int) ([]*domain.UserTenant, error)
+	// Touch records that the member was seen at at, unless a later time was
+	// recorded already, and reports false when the membership is not active
+	Touch(ctx context.Context, userID primitive.ObjectID, tenantID string, at time.Time) (bool, error)
+	// FindInactive returns up to q.Limit active memberships of a tenant not seen
+	// since q.SeenBefore (see InactiveQuery)
+	FindInactive(ctx context.Context, q InactiveQuery) ([]*domain.UserTenant, error)
+	// MarkInactivityWarned sets InactivityWarnedAt of an active membership and
+	// reports false when the membership is not active
+	MarkInactivityWarned(ctx context.Context, userID primitive.ObjectID, tenantID string, at time.Time) (bool, error)
+	// FindRemoved returns removed memberships due for purging (see RemovedQuery)
+	FindRemoved(ctx context.Context, q RemovedQuery) ([]*domain.UserTenant, error)
+	// Purge hard deletes a membership that is still removed and was removed before
+	// removedBefore, and the global user once no memberships remain. A membership
+	// restored in the meantime is left alone and PurgeResult.Membership is nil.
+	// then, if set, runs once something was purged, and the purge is undone when it fails
+	Purge(ctx context.Context, userID primitive.ObjectID, tenantID string, removedBefore time.Time, then PurgeHook) (*PurgeResult, error)
+}
+
+var (
+	_ UserStore = (*UserRepository)(nil)
+	_ UserStore = (*InMemoryUserRepository)(nil)
+)
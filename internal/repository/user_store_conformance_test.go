@@ -0,0 +1,1048 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testUserStore is the conformance suite every UserStore implementation must pass
+func testUserStore(t *testing.T, newStore func(t *testing.T) UserStore) {
+	ctx := context.Background()
+
+	create := func(t *testing.T, store UserStore, email, tenantID, firstName, lastName string) (*domain.User, *domain.UserTenant) {
+		t.Helper()
+		user := &domain.User{Email: email, IsActive: true}
+		ut := &domain.UserTenant{TenantID: tenantID, FirstName: firstName, LastName: lastName, Roles: []string{"user"}, IsActive: true}
+		require.NoError(t, store.Create(ctx, user, ut))
+		// Keep joinedAt strictly increasing at millisecond precision
+		time.Sleep(2 * time.Millisecond)
+		return user, ut
+	}
+
+	t.Run("create and find by id", func(t *testing.T) {
+		store := newStore(t)
+		user, ut := create(t, store, "john@example.com", "tenant-a", "John", "Doe")
+
+		assert.False(t, user.ID.IsZero())
+		assert.False(t, ut.ID.IsZero())
+		assert.Equal(t, user.ID, ut.UserID)
+		assert.False(t, ut.JoinedAt.IsZero())
+
+		gotUser, gotTenant, err := store.FindByID(ctx, user.ID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		require.NotNil(t, gotUser)
+		require.NotNil(t, gotTenant)
+		assert.Equal(t, "john@example.com", gotUser.Email)
+		assert.True(t, gotUser.IsActive)
+		assert.False(t, gotUser.CreatedAt.IsZero())
+		assert.Equal(t, "John", gotTenant.FirstName)
+		assert.Equal(t, []string{"user"}, gotTenant.Roles)
+		assert.True(t, gotTenant.IsActive)
+
+		gotUser, gotTenant, err = store.FindByID(ctx, user.ID.Hex(), "tenant-b")
+		require.NoError(t, err)
+		assert.Nil(t, gotUser)
+		assert.Nil(t, gotTenant)
+
+		_, _, err = store.FindByID(ctx, "not-an-object-id", "tenant-a")
+		assert.Error(t, err)
+	})
+
+	t.Run("same email in another tenant reuses the global user", func(t *testing.T) {
+		store := newStore(t)
+		first, _ := create(t, store, "shared@example.com", "tenant-a", "Ann", "Lee")
+		second, _ := create(t, store, "shared@example.com", "tenant-b", "Annie", "Lee")
+
+		assert.Equal(t, first.ID, second.ID)
+		assert.WithinDuration(t, first.CreatedAt, second.CreatedAt, time.Millisecond)
+
+		tenants, err := store.FindTenants(ctx, first.ID.Hex())
+		require.NoError(t, err)
+		require.Len(t, tenants, 2)
+		assert.Equal(t, "tenant-a", tenants[0].TenantID)
+		assert.Equal(t, "tenant-b", tenants[1].TenantID)
+	})
+
+	t.Run("unique membership per tenant", func(t *testing.T) {
+		store := newStore(t)
+		user, _ := create(t, store, "dup@example.com", "tenant-a", "Dup", "User")
+
+		before, err := store.FindUserByID(ctx, user.ID.Hex())
+		require.NoError(t, err)
+
+		err = store.Create(ctx, &domain.User{Email: "dup@example.com", IsActive: true},
+			&domain.UserTenant{TenantID: "tenant-a", Roles: []string{"user"}, IsActive: true})
+		assert.ErrorIs(t, err, ErrUserTenantExists)
+
+		// A rejected duplicate link leaves the global user untouched
+		after, err := store.FindUserByID(ctx, user.ID.Hex())
+		require.NoError(t, err)
+		assert.WithinDuration(t, before.UpdatedAt, after.UpdatedAt, time.Millisecond)
+
+		err = store.AddToTenant(ctx, &domain.UserTenant{UserID: user.ID, TenantID: "tenant-a", Roles: []string{"user"}, IsActive: true})
+		assert.ErrorIs(t, err, ErrUserTenantExists)
+
+		added := &domain.UserTenant{UserID: user.ID, TenantID: "tenant-c", Roles: []string{"admin"}, IsActive: true}
+		require.NoError(t, store.AddToTenant(ctx, added))
+		assert.False(t, added.ID.IsZero())
+		assert.False(t, added.JoinedAt.IsZero())
+
+		_, got, err := store.FindByID(ctx, user.ID.Hex(), "tenant-c")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, []string{"admin"}, got.Roles)
+	})
+
+	t.Run("unique username and document number", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Create(ctx,
+			&domain.User{Email: "a@example.com", Username: "alice", DocumentNumber: "DOC-1", IsActive: true},
+			&domain.UserTenant{TenantID: "tenant-a", IsActive: true}))
+
+		err := store.Create(ctx,
+			&domain.User{Email: "b@example.com", Username: "alice", IsActive: true},
+			&domain.UserTenant{TenantID: "tenant-a", IsActive: true})
+		assert.ErrorIs(t, err, ErrIdentifierTaken)
+
+		err = store.Create(ctx,
+			&domain.User{Email: "c@example.com", DocumentNumber: "DOC-1", IsActive: true},
+			&domain.UserTenant{TenantID: "tenant-a", IsActive: true})
+		assert.ErrorIs(t, err, ErrIdentifierTaken)
+
+		// Failed creates leave neither a user nor a membership behind
+		orphan, _, err := store.FindByEmail(ctx, "b@example.com", "tenant-a")
+		require.NoError(t, err)
+		assert.Nil(t, orphan)
+
+		// Users without a username do not collide with each other
+		create(t, store, "d@example.com", "tenant-a", "Dee", "One")
+		create(t, store, "e@example.com", "tenant-a", "Eve", "Two")
+	})
+
+	t.Run("find by email", func(t *testing.T) {
+		store := newStore(t)
+		create(t, store, "mail@example.com", "tenant-a", "Mail", "User")
+
+		user, ut, err := store.FindByEmail(ctx, "mail@example.com", "tenant-a")
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		require.NotNil(t, ut)
+
+		user, ut, err = store.FindByEmail(ctx, "mail@example.com", "tenant-b")
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		assert.Nil(t, ut)
+
+		user, ut, err = store.FindByEmail(ctx, "missing@example.com", "tenant-a")
+		require.NoError(t, err)
+		assert.Nil(t, user)
+		assert.Nil(t, ut)
+	})
+
+	t.Run("find by identifier", func(t *testing.T) {
+		store := newStore(t)
+		user := &domain.User{Email: "id@example.com", Username: "ident", Phone: "+84901234567", DocumentNumber: "079123456789", IsActive: true}
+		require.NoError(t, store.Create(ctx, user, &domain.UserTenant{TenantID: "tenant-a", IsActive: true}))
+
+		for _, tc := range []struct{ identifier, identifierType string }{
+			{"id@example.com", "email"},
+			{"ident", "username"},
+			{"+84901234567", "phone"},
+			{"079123456789", "document_number"},
+			{"ident", ""},
+			{"+84901234567", ""},
+		} {
+			got, err := store.FindByIdentifier(ctx, tc.identifier, tc.identifierType)
+			require.NoError(t, err)
+			require.NotNil(t, got, "%s/%s", tc.identifierType, tc.identifier)
+			assert.Equal(t, user.ID, got.ID)
+		}
+
+		got, err := store.FindByIdentifier(ctx, "ident", "email")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		got, err = store.FindUserByID(ctx, primitive.NewObjectID().Hex())
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("list paginates newest first within a tenant", func(t *testing.T) {
+		store := newStore(t)
+		for i := 0; i < 5; i++ {
+			create(t, store, fmt.Sprintf("user%d@example.com", i), "tenant-a", "User", "Number")
+		}
+		create(t, store, "other@example.com", "tenant-b", "Other", "Tenant")
+
+		page1, total, err := store.List(ctx, MemberQuery{TenantID: "tenant-a", Page: 1, PageSize: 2})
+		require.NoError(t, err)
+		assert.EqualValues(t, 5, total)
+		require.Len(t, page1, 2)
+		assert.Equal(t, "user4@example.com", page1[0].User.Email)
+		assert.Equal(t, "user3@example.com", page1[1].User.Email)
+
+		page3, _, err := store.List(ctx, MemberQuery{TenantID: "tenant-a", Page: 3, PageSize: 2})
+		require.NoError(t, err)
+		require.Len(t, page3, 1)
+		assert.Equal(t, "user0@example.com", page3[0].User.Email)
+		assert.Equal(t, "tenant-a", page3[0].UserTenant.TenantID)
+
+		empty, total, err := store.List(ctx, MemberQuery{TenantID: "tenant-z", Page: 1, PageSize: 20})
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, total)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("list continues after a keyset position", func(t *testing.T) {
+		store := newStore(t)
+		for i := 0; i < 5; i++ {
+			create(t, store, fmt.Sprintf("user%d@example.com", i), "tenant-a", "User", "Number")
+		}
+
+		var emails []string
+		q := MemberQuery{TenantID: "tenant-a", PageSize: 2, SkipTotal: true}
+		for {
+			page, total, err := store.List(ctx, q)
+			require.NoError(t, err)
+			assert.EqualValues(t, 0, total)
+			if len(page) == 0 {
+				break
+			}
+			for _, m := range page {
+				emails = append(emails, m.User.Email)
+			}
+			q.After = Position(page[len(page)-1], domain.DefaultUserSort)
+		}
+		assert.Equal(t, []string{
+			"user4@example.com", "user3@example.com", "user2@example.com", "user1@example.com", "user0@example.com",
+		}, emails)
+
+		// Members sharing a joinedAt are ordered by _id
+		first, _, err := store.List(ctx, MemberQuery{TenantID: "tenant-a", PageSize: 1})
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		after := &MemberPosition{Keys: []interface{}{first[0].UserTenant.JoinedAt}, ID: primitive.NewObjectID()}
+		rest, total, err := store.List(ctx, MemberQuery{TenantID: "tenant-a", PageSize: 10, After: after})
+		require.NoError(t, err)
+		assert.EqualValues(t, 5, total)
+		require.Len(t, rest, 5)
+		assert.Equal(t, "user4@example.com", rest[0].User.Email)
+
+		results, _, err := store.Search(ctx, MemberQuery{TenantID: "tenant-a", Sort: domain.DefaultUserSort, PageSize: 10, After: Position(first[0], domain.DefaultUserSort)}, "number")
+		require.NoError(t, err)
+		assert.Len(t, results, 4)
+	})
+
+	t.Run("list filters and sorts", func(t *testing.T) {
+		store := newStore(t)
+		start := time.Now().Add(-time.Second)
+		anh, anhTenant := create(t, store, "anh@corp.example", "tenant-a", "Anh", "Tran")
+		create(t, store, "binh@example.com", "tenant-a", "Binh", "Le")
+		middle := time.Now()
+		_, chiTenant := create(t, store, "chi@CORP.example", "tenant-a", "Chi", "Pham")
+		create(t, store, "nameless@example.com", "tenant-a", "", "")
+
+		anh.Phone = "+84901234567"
+		anhTenant.Roles = []string{"admin"}
+		require.NoError(t, store.Update(ctx, anh, anhTenant))
+		ok, err := store.SetStatus(ctx, chiTenant.UserID, "tenant-a", domain.MembershipActive,
+			domain.MembershipTransition{From: domain.MembershipActive, To: domain.MembershipDeactivated, At: time.Now()})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		emails := func(members []*UserWithTenant) []string {
+			var out []string
+			for _, m := range members {
+				out = append(out, m.User.Email)
+			}
+			return out
+		}
+		list := func(filter domain.UserFilter, order domain.UserSort) ([]string, int64) {
+			t.Helper()
+			members, total, err := store.List(ctx, MemberQuery{TenantID: "tenant-a", Filter: filter, Sort: order, PageSize: 20})
+			require.NoError(t, err)
+			return emails(members), total
+		}
+		yes, no := true, false
+
+		got, total := list(domain.UserFilter{IsActive: &no}, domain.UserSort{})
+		assert.Equal(t, []string{"chi@CORP.example"}, got)
+		assert.EqualValues(t, 1, total)
+
+		got, _ = list(domain.UserFilter{Role: "admin"}, domain.UserSort{})
+		assert.Equal(t, []string{"anh@corp.example"}, got)
+
+		got, total = list(domain.UserFilter{EmailDomain: "corp.example"}, domain.UserSort{Field: domain.UserSortEmail})
+		assert.Equal(t, []string{"anh@corp.example", "chi@CORP.example"}, got)
+		assert.EqualValues(t, 2, total)
+
+		got, _ = list(domain.UserFilter{HasPhone: &yes}, domain.UserSort{})
+		assert.Equal(t, []string{"anh@corp.example"}, got)
+		got, _ = list(domain.UserFilter{HasPhone: &no, IsActive: &yes}, domain.UserSort{})
+		assert.Equal(t, []string{"nameless@example.com", "binh@example.com"}, got)
+
+		got, _ = list(domain.UserFilter{JoinedAt: domain.TimeRange{Gte: &start, Lt: &middle}}, domain.UserSort{Field: domain.UserSortJoinedAt})
+		assert.Equal(t, []string{"anh@corp.example", "binh@example.com"}, got)
+		got, _ = list(domain.UserFilter{CreatedAt: domain.TimeRange{Gt: &middle}}, domain.UserSort{Field: domain.UserSortCreatedAt, Desc: true})
+		assert.Equal(t, []string{"nameless@example.com", "chi@CORP.example"}, got)
+
+		// Members without a name sort first
+		got, _ = list(domain.UserFilter{}, domain.UserSort{Field: domain.UserSortName})
+		assert.Equal(t, []string{"nameless@example.com", "anh@corp.example", "binh@example.com", "chi@CORP.example"}, got)
+
+		// Keyset pagination follows every sort in both directions
+		for _, field := range []string{domain.UserSortName, domain.UserSortEmail, domain.UserSortJoinedAt, domain.UserSortCreatedAt} {
+			for _, desc := range []bool{false, true} {
+				order := domain.UserSort{Field: field, Desc: desc}
+				want, _ := list(domain.UserFilter{}, order)
+
+				var paged []string
+				q := MemberQuery{TenantID: "tenant-a", Sort: order, PageSize: 1, SkipTotal: true}
+				for {
+					page, _, err := store.List(ctx, q)
+					require.NoError(t, err)
+					if len(page) == 0 {
+						break
+					}
+					paged = append(paged, emails(page)...)
+					q.After = Position(page[0], order)
+				}
+				assert.Equal(t, want, paged, order.String())
+			}
+		}
+	})
+
+	t.Run("update persists user and membership fields", func(t *testing.T) {
+		store := newStore(t)
+		user, ut := create(t, store, "upd@example.com", "tenant-a", "Old", "Name")
+		create(t, store, "taken@example.com", "tenant-a", "Taken", "Name")
+
+		user.Phone = "+84987654321"
+		ut.FirstName = "New"
+		ut.Roles = []string{"admin"}
+		require.NoError(t, store.Update(ctx, user, ut))
+
+		gotUser, gotTenant, err := store.FindByID(ctx, user.ID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, "+84987654321", gotUser.Phone)
+		assert.Equal(t, "New", gotTenant.FirstName)
+		assert.Equal(t, []string{"admin"}, gotTenant.Roles)
+
+		user.Email = "taken@example.com"
+		assert.ErrorIs(t, store.Update(ctx, user, nil), ErrIdentifierTaken)
+	})
+
+	t.Run("update keeps status, tags and activity changed since the read", func(t *testing.T) {
+		store := newStore(t)
+		_, ut := create(t, store, "stale@example.com", "tenant-a", "Old", "Name")
+		stale := *ut
+
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		ok, err := store.SetStatus(ctx, ut.UserID, "tenant-a", domain.MembershipActive,
+			domain.MembershipTransition{From: domain.MembershipActive, To: domain.MembershipSuspended, At: now})
+		require.NoError(t, err)
+		require.True(t, ok)
+		_, err = store.AddTags(ctx, "tenant-a", []primitive.ObjectID{ut.UserID}, []string{"vip"})
+		require.NoError(t, err)
+
+		stale.FirstName = "New"
+		stale.Roles = []string{"manager"}
+		require.NoError(t, store.Update(ctx, nil, &stale))
+
+		_, got, err := store.FindByID(ctx, ut.UserID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, "New", got.FirstName)
+		assert.Equal(t, []string{"manager"}, got.Roles)
+		assert.Equal(t, domain.MembershipSuspended, got.Status)
+		assert.False(t, got.IsActive)
+		require.NotEmpty(t, got.Transitions)
+		assert.Equal(t, domain.MembershipSuspended, got.Transitions[len(got.Transitions)-1].To)
+		assert.Equal(t, []string{"vip"}, got.Tags)
+	})
+
+	t.Run("delete is a soft delete", func(t *testing.T) {
+		store := newStore(t)
+		user, _ := create(t, store, "del@example.com", "tenant-a", "Del", "User")
+		create(t, store, "del@example.com", "tenant-b", "Del", "User")
+
+		require.NoError(t, store.Delete(ctx, user.ID.Hex(), "tenant-a"))
+
+		gotUser, gotTenant, err := store.FindByID(ctx, user.ID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		require.NotNil(t, gotTenant)
+		assert.False(t, gotTenant.IsActive)
+		assert.Equal(t, domain.MembershipRemoved, gotTenant.Status)
+		require.Len(t, gotTenant.Transitions, 1)
+		assert.Equal(t, domain.MembershipRemoved, gotTenant.Transitions[0].To)
+		assert.True(t, gotUser.IsActive)
+
+		// Removed members are only listed when asked for
+		_, total, err := store.List(ctx, MemberQuery{TenantID: "tenant-a", Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		results, total, err := store.List(ctx, MemberQuery{TenantID: "tenant-a", Filter: domain.UserFilter{Status: domain.MembershipRemoved}, Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		require.Len(t, results, 1)
+		assert.Equal(t, domain.MembershipRemoved, results[0].UserTenant.Status)
+
+		_, other, err := store.FindByID(ctx, user.ID.Hex(), "tenant-b")
+		require.NoError(t, err)
+		assert.True(t, other.IsActive)
+
+		// Deleting a missing membership is not an error
+		require.NoError(t, store.Delete(ctx, user.ID.Hex(), "tenant-z"))
+	})
+
+	t.Run("set status moves memberships from the expected status", func(t *testing.T) {
+		store := newStore(t)
+		user, ut := create(t, store, "lan@example.com", "tenant-a", "Lan", "Phạm")
+		assert.Equal(t, domain.MembershipActive, ut.Status)
+		assert.False(t, ut.StatusChangedAt.IsZero())
+
+		at := time.Now().UTC().Truncate(time.Millisecond)
+		suspend := domain.MembershipTransition{From: domain.MembershipActive, To: domain.MembershipSuspended, Reason: "audit", By: "admin-1", At: at}
+		ok, err := store.SetStatus(ctx, user.ID, "tenant-a", domain.MembershipActive, suspend)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// The membership is no longer active
+		ok, err = store.SetStatus(ctx, user.ID, "tenant-a", domain.MembershipActive, suspend)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		ok, err = store.SetStatus(ctx, user.ID, "tenant-b", domain.MembershipActive, suspend)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		_, got, err := store.FindByID(ctx, user.ID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MembershipSuspended, got.Status)
+		assert.False(t, got.IsActive)
+		assert.True(t, at.Equal(got.StatusChangedAt))
+		require.Len(t, got.Transitions, 1)
+		assert.Equal(t, "audit", got.Transitions[0].Reason)
+		assert.Equal(t, "admin-1", got.Transitions[0].By)
+
+		// Only removed memberships are restored, with their new roles and expiry
+		restore := domain.MembershipTransition{From: domain.MembershipRemoved, To: domain.MembershipActive, At: at}
+		expiresAt := at.Add(24 * time.Hour)
+		ok, err = store.Restore(ctx, user.ID, "tenant-a", []string{"user"}, &expiresAt, restore)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		_, owner := create(t, store, "owner@example.com", "tenant-a", "Chủ", "Nhà")
+		owner.Roles = []string{"owner"}
+		require.NoError(t, store.Update(ctx, nil, owner))
+		require.NoError(t, store.Delete(ctx, owner.UserID.Hex(), "tenant-a"))
+		ok, err = store.Restore(ctx, owner.UserID, "tenant-a", []string{"user"}, &expiresAt, restore)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		_, got, err = store.FindByID(ctx, owner.UserID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MembershipActive, got.Status)
+		assert.True(t, got.IsActive)
+		assert.Equal(t, []string{"user"}, got.Roles)
+		require.NotNil(t, got.ExpiresAt)
+		assert.True(t, got.ExpiresAt.Equal(expiresAt))
+
+		// Only the most recent transitions are kept
+		for i := 0; i < domain.MaxMembershipTransitions; i++ {
+			from, to := domain.MembershipSuspended, domain.MembershipActive
+			if i%2 == 1 {
+				from, to = to, from
+			}
+			ok, err := store.SetStatus(ctx, user.ID, "tenant-a", from, domain.MembershipTransition{From: from, To: to, At: time.Now()})
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+		_, got, err = store.FindByID(ctx, user.ID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		assert.Len(t, got.Transitions, domain.MaxMembershipTransitions)
+		assert.Equal(t, domain.MembershipSuspended, got.Status)
+		assert.Equal(t, domain.MembershipActive, got.Transitions[0].To)
+	})
+
+	t.Run("purge deletes removed memberships past their window", func(t *testing.T) {
+		store := newStore(t)
+		lan, _ := create(t, store, "lan@example.com", "tenant-a", "Lan", "Phạm")
+		require.NoError(t, store.AddToTenant(ctx, &domain.UserTenant{UserID: lan.ID, TenantID: "tenant-b", Roles: []string{"user"}, IsActive: true}))
+		hoa, _ := create(t, store, "hoa@example.com", "tenant-b", "Hoa", "Lê")
+		create(t, store, "mai@example.com", "tenant-a", "Mai", "Trần")
+
+		removedAt := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Millisecond)
+		remove := func(userID primitive.ObjectID, tenantID string, at time.Time) {
+			ok, err := store.SetStatus(ctx, userID, tenantID, domain.MembershipActive,
+				domain.MembershipTransition{From: domain.MembershipActive, To: domain.MembershipRemoved, At: at})
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+		remove(lan.ID, "tenant-a", removedAt)
+		remove(lan.ID, "tenant-b", removedAt.Add(time.Hour))
+		remove(hoa.ID, "tenant-b", time.Now().UTC())
+
+		cutoff := time.Now().Add(-24 * time.Hour)
+		due, err := store.FindRemoved(ctx, RemovedQuery{Before: cutoff})
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		assert.Equal(t, "tenant-a", due[0].TenantID)
+		assert.Equal(t, "tenant-b", due[1].TenantID)
+
+		due, err = store.FindRemoved(ctx, RemovedQuery{TenantID: "tenant-b", Before: cutoff})
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		due, err = store.FindRemoved(ctx, RemovedQuery{ExcludeTenantIDs: []string{"tenant-b"}, Before: cutoff, Limit: 5})
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, "tenant-a", due[0].TenantID)
+
+		// Recently removed memberships are kept
+		result, err := store.Purge(ctx, hoa.ID, "tenant-b", cutoff, nil)
+		require.NoError(t, err)
+		assert.Nil(t, result.Membership)
+
+		// The user stays while another membership remains
+		result, err = store.Purge(ctx, lan.ID, "tenant-a", cutoff, nil)
+		require.NoError(t, err)
+		require.NotNil(t, result.Membership)
+		assert.Equal(t, "tenant-a", result.Membership.TenantID)
+		assert.False(t, result.UserPurged)
+		_, gone, err := store.FindByID(ctx, lan.ID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		assert.Nil(t, gone)
+
+		// A failing hook undoes the purge, and sees what was purged
+		failed := fmt.Errorf("audit log unavailable")
+		result, err = store.Purge(ctx, lan.ID, "tenant-b", cutoff, func(tx *Tx, result *PurgeResult) error {
+			assert.NotNil(t, tx.Context())
+			assert.True(t, result.UserPurged)
+			return failed
+		})
+		assert.ErrorIs(t, err, failed)
+		assert.Nil(t, result)
+		_, kept, err := store.FindByID(ctx, lan.ID.Hex(), "tenant-b")
+		require.NoError(t, err)
+		require.NotNil(t, kept)
+		assert.Equal(t, domain.MembershipRemoved, kept.Status)
+
+		result, err = store.Purge(ctx, lan.ID, "tenant-b", cutoff, nil)
+		require.NoError(t, err)
+		require.NotNil(t, result.Membership)
+		assert.True(t, result.UserPurged)
+		user, err := store.FindUserByID(ctx, lan.ID.Hex())
+		require.NoError(t, err)
+		assert.Nil(t, user)
+
+		result, err = store.Purge(ctx, lan.ID, "tenant-b", cutoff, nil)
+		require.NoError(t, err)
+		assert.Nil(t, result.Membership)
+	})
+
+	t.Run("memberships expire", func(t *testing.T) {
+		store := newStore(t)
+		_, lan := create(t, store, "lan@example.com", "tenant-a", "Lan", "Phạm")
+		_, hoa := create(t, store, "hoa@example.com", "tenant-a", "Hoa", "Lê")
+		_, mai := create(t, store, "mai@example.com", "tenant-a", "Mai", "Trần")
+		_, removed := create(t, store, "an@example.com", "tenant-a", "An", "Võ")
+
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		expire := func(ut *domain.UserTenant, at time.Time) {
+			ut.ExpiresAt = &at
+			require.NoError(t, store.Update(ctx, nil, ut))
+		}
+		expire(lan, now.Add(-time.Hour))
+		expire(hoa, now.Add(-2*time.Hour))
+		expire(mai, now.Add(72*time.Hour))
+		expire(removed, now.Add(-3*time.Hour))
+		ok, err := store.SetStatus(ctx, removed.UserID, "tenant-a", domain.MembershipActive,
+			domain.MembershipTransition{From: domain.MembershipActive, To: domain.MembershipRemoved, At: now})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, got, err := store.FindByID(ctx, mai.UserID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		require.NotNil(t, got.ExpiresAt)
+		assert.True(t, got.ExpiresAt.Equal(now.Add(72*time.Hour)))
+
+		// Removed memberships are not deactivated again
+		due, err := store.FindExpired(ctx, now, 0)
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		assert.Equal(t, hoa.UserID, due[0].UserID)
+		assert.Equal(t, lan.UserID, due[1].UserID)
+		due, err = store.FindExpired(ctx, now, 1)
+		require.NoError(t, err)
+		assert.Len(t, due, 1)
+
+		soon := now.Add(7 * 24 * time.Hour)
+		members, total, err := store.List(ctx, MemberQuery{
+			TenantID: "tenant-a",
+			Filter:   domain.UserFilter{ExpiresAt: domain.TimeRange{Gt: &now, Lte: &soon}},
+			Sort:     domain.UserSort{Field: domain.UserSortExpiresAt},
+			PageSize: 10,
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		require.Len(t, members, 1)
+		assert.Equal(t, "mai@example.com", members[0].User.Email)
+		require.NotNil(t, members[0].UserTenant.ExpiresAt)
+
+		members, _, err = store.List(ctx, MemberQuery{
+			TenantID: "tenant-a",
+			Filter:   domain.UserFilter{ExpiresAt: domain.TimeRange{Lte: &soon}},
+			Sort:     domain.UserSort{Field: domain.UserSortExpiresAt},
+			PageSize: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, members, 3)
+		for i, email := range []string{"hoa@example.com", "lan@example.com", "mai@example.com"} {
+			assert.Equal(t, email, members[i].User.Email)
+		}
+
+		// Clearing the expiry removes it
+		mai.ExpiresAt = nil
+		require.NoError(t, store.Update(ctx, nil, mai))
+		_, got, err = store.FindByID(ctx, mai.UserID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		assert.Nil(t, got.ExpiresAt)
+	})
+
+	t.Run("inactive memberships", func(t *testing.T) {
+		store := newStore(t)
+		_, lan := create(t, store, "lan@example.com", "tenant-a", "Lan", "Phạm")
+		_, hoa := create(t, store, "hoa@example.com", "tenant-a", "Hoa", "Lê")
+		_, mai := create(t, store, "mai@example.com", "tenant-a", "Mai", "Trần")
+		_, suspended := create(t, store, "an@example.com", "tenant-a", "An", "Võ")
+		create(t, store, "lan@example.net", "tenant-b", "Lan", "Phạm")
+
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		ok, err := store.SetStatus(ctx, suspended.UserID, "tenant-a", domain.MembershipActive,
+			domain.MembershipTransition{From: domain.MembershipActive, To: domain.MembershipSuspended, At: now})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// Touching keeps the latest time and skips inactive memberships
+		later := now.Add(40 * 24 * time.Hour)
+		ok, err = store.Touch(ctx, lan.UserID, "tenant-a", later)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = store.Touch(ctx, lan.UserID, "tenant-a", now)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = store.Touch(ctx, suspended.UserID, "tenant-a", later)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		_, got, err := store.FindByID(ctx, lan.UserID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		require.NotNil(t, got.LastSeenAt)
+		assert.True(t, got.LastSeenAt.Equal(later))
+
+		ok, err = store.MarkInactivityWarned(ctx, hoa.UserID, "tenant-a", now)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = store.MarkInactivityWarned(ctx, suspended.UserID, "tenant-a", now)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// Members not seen count from their last status change
+		cutoff := now.Add(30 * 24 * time.Hour)
+		inactive, err := store.FindInactive(ctx, InactiveQuery{TenantID: "tenant-a", SeenBefore: cutoff})
+		require.NoError(t, err)
+		require.Len(t, inactive, 2)
+		ids := []primitive.ObjectID{hoa.ID, mai.ID}
+		if ids[1].Hex() < ids[0].Hex() {
+			ids[0], ids[1] = ids[1], ids[0]
+		}
+		assert.Equal(t, ids[0], inactive[0].ID)
+		assert.Equal(t, ids[1], inactive[1].ID)
+		for _, ut := range inactive {
+			if ut.UserID == hoa.UserID {
+				require.NotNil(t, ut.InactivityWarnedAt)
+				assert.True(t, ut.InactivityWarnedAt.Equal(now))
+			}
+		}
+
+		inactive, err = store.FindInactive(ctx, InactiveQuery{TenantID: "tenant-a", SeenBefore: cutoff, After: ids[0], Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, inactive, 1)
+		assert.Equal(t, ids[1], inactive[0].ID)
+
+		inactive, err = store.FindInactive(ctx, InactiveQuery{TenantID: "tenant-a", SeenBefore: now.Add(-time.Hour)})
+		require.NoError(t, err)
+		assert.Empty(t, inactive)
+
+		// Members never seen count from when the tenant started tracking
+		inactive, err = store.FindInactive(ctx, InactiveQuery{TenantID: "tenant-a", SeenBefore: cutoff, TrackedSince: cutoff})
+		require.NoError(t, err)
+		assert.Empty(t, inactive)
+	})
+
+	t.Run("search matches names within a tenant", func(t *testing.T) {
+		store := newStore(t)
+		create(t, store, "jd@example.com", "tenant-a", "John", "Doe")
+		create(t, store, "js@example.com", "tenant-a", "Jane", "Smith")
+		create(t, store, "jb@example.com", "tenant-b", "John", "Brown")
+
+		results, total, err := store.Search(ctx, MemberQuery{TenantID: "tenant-a", Page: 1, PageSize: 20}, "john")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		require.Len(t, results, 1)
+		assert.Equal(t, "jd@example.com", results[0].User.Email)
+
+		results, total, err = store.Search(ctx, MemberQuery{TenantID: "tenant-a", Page: 1, PageSize: 20}, "smith doe")
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, total)
+		emails := []string{results[0].User.Email, results[1].User.Email}
+		sort.Strings(emails)
+		assert.Equal(t, []string{"jd@example.com", "js@example.com"}, emails)
+
+		results, total, err = store.Search(ctx, MemberQuery{TenantID: "tenant-a", Page: 1, PageSize: 20}, "brown")
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, total)
+		assert.Empty(t, results)
+	})
+
+	t.Run("search ignores diacritics and ranks matches", func(t *testing.T) {
+		store := newStore(t)
+		create(t, store, "u1@example.com", "tenant-a", "Văn An", "Nguyễn")
+		create(t, store, "u2@example.com", "tenant-a", "Ngọc Ánh", "Trần")
+		create(t, store, "u3@example.com", "tenant-a", "Đào", "Đặng")
+		create(t, store, "u4@example.com", "tenant-a", "An", "Văn")
+
+		search := func(text string) []string {
+			t.Helper()
+			results, total, err := store.Search(ctx, MemberQuery{TenantID: "tenant-a", PageSize: 20}, text)
+			require.NoError(t, err)
+			assert.EqualValues(t, len(results), total)
+			var emails []string
+			for _, m := range results {
+				emails = append(emails, m.User.Email)
+			}
+			return emails
+		}
+
+		// Folded exact words rank above prefixes; newest first on ties
+		assert.Equal(t, []string{"u4@example.com", "u1@example.com", "u2@example.com"}, search("an"))
+		assert.Equal(t, []string{"u3@example.com"}, search("dang dao"))
+		assert.Equal(t, []string{"u3@example.com"}, search("ĐẶNG"))
+		assert.Equal(t, []string{"u2@example.com"}, search("tra"))
+		// The phrase bonus puts the name that starts with the query first
+		assert.Equal(t, []string{"u1@example.com", "u4@example.com", "u2@example.com"}, search("van an"))
+		assert.Empty(t, search("!!"))
+		assert.Empty(t, search("xyz"))
+
+		// Relevance pages with a keyset like any other sort
+		var paged []string
+		q := MemberQuery{TenantID: "tenant-a", PageSize: 1, SkipTotal: true}
+		for {
+			page, _, err := store.Search(ctx, q, "van an")
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			paged = append(paged, page[0].User.Email)
+			q.After = Position(page[0], domain.RelevanceUserSort)
+		}
+		assert.Equal(t, search("van an"), paged)
+	})
+
+	t.Run("search detects the kind of query", func(t *testing.T) {
+		store := newStore(t)
+		withIdentifiers := func(email, phone, username, document, first, last string) *domain.User {
+			t.Helper()
+			user := &domain.User{Email: email, Phone: phone, Username: username, DocumentNumber: document, IsActive: true}
+			ut := &domain.UserTenant{TenantID: "tenant-a", FirstName: first, LastName: last, Roles: []string{"user"}, IsActive: true}
+			require.NoError(t, store.Create(ctx, user, ut))
+			time.Sleep(2 * time.Millisecond)
+			return user
+		}
+		minh := withIdentifiers("minh@example.com", "+84901234567", "minh", "079201000123", "Minh", "Trần")
+		withIdentifiers("minh.le@example.com", "+84912345678", "", "", "Lê", "Minh")
+		withIdentifiers("hoa@corp.vn", "", "minhhoa", "", "Hoa", "Phạm")
+		other, _ := create(t, store, "x@example.com", "tenant-b", "Minh", "Other")
+
+		search := func(text string) []string {
+			t.Helper()
+			results, total, err := store.Search(ctx, MemberQuery{TenantID: "tenant-a", PageSize: 20}, text)
+			require.NoError(t, err)
+			assert.EqualValues(t, len(results), total)
+			var emails []string
+			for _, m := range results {
+				emails = append(emails, m.User.Email)
+			}
+			return emails
+		}
+
+		// Emails: exact, then prefix, as typed or in lower case; fragments do not match
+		assert.Equal(t, []string{"minh@example.com"}, search("MINH@example.com"))
+		assert.Equal(t, []string{"minh.le@example.com"}, search("Minh.le@"))
+		assert.Equal(t, []string{"hoa@corp.vn"}, search("hoa@"))
+		assert.Empty(t, search("@example.com"))
+		// Phones: E.164 and national with a leading 0, in full or their start
+		assert.Equal(t, []string{"minh@example.com"}, search("+84901234567"))
+		assert.Equal(t, []string{"minh@example.com"}, search("0901 234 567"))
+		assert.Equal(t, []string{"minh.le@example.com", "minh@example.com"}, search("+849"))
+		assert.Equal(t, []string{"minh.le@example.com"}, search("0912"))
+		assert.Empty(t, search("234"))
+		// Document numbers are matched like phones
+		assert.Equal(t, []string{"minh@example.com"}, search("079201000123"))
+		// User IDs match only within the tenant
+		assert.Equal(t, []string{"minh@example.com"}, search(minh.ID.Hex()))
+		assert.Empty(t, search(other.ID.Hex()))
+		// A single word also matches usernames and email prefixes, which rank above names
+		assert.Equal(t, []string{"minh@example.com", "minh.le@example.com", "hoa@corp.vn"}, search("minh"))
+		// Several words only match names
+		assert.Equal(t, []string{"minh@example.com", "minh.le@example.com"}, search("minh tran"))
+	})
+
+	t.Run("count matches the user and filter", func(t *testing.T) {
+		store := newStore(t)
+		lan, _ := create(t, store, "lan@corp.vn", "tenant-a", "Lan", "Phạm")
+		create(t, store, "hoa@example.com", "tenant-a", "Hoa", "Lê")
+		create(t, store, "mai@corp.vn", "tenant-b", "Mai", "Trần")
+
+		count, err := store.Count(ctx, MemberQuery{TenantID: "tenant-a"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+
+		count, err = store.Count(ctx, MemberQuery{TenantID: "tenant-a", Filter: domain.UserFilter{EmailDomain: "corp.vn"}})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+
+		count, err = store.Count(ctx, MemberQuery{TenantID: "tenant-a", UserID: lan.ID, Filter: domain.UserFilter{EmailDomain: "corp.vn"}})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+
+		count, err = store.Count(ctx, MemberQuery{TenantID: "tenant-a", UserID: lan.ID, Filter: domain.UserFilter{EmailDomain: "example.com"}})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		count, err = store.Count(ctx, MemberQuery{TenantID: "tenant-b", UserID: lan.ID})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("facets count the filtered members", func(t *testing.T) {
+		store := newStore(t)
+		_, lan := create(t, store, "lan@corp.vn", "tenant-a", "Lan", "Phạm")
+		create(t, store, "hoa@Corp.VN", "tenant-a", "Hoa", "Lê")
+		away, _ := create(t, store, "an@example.com", "tenant-a", "An", "Nguyễn")
+		gone, _ := create(t, store, "binh@corp.vn", "tenant-a", "Bình", "Võ")
+		create(t, store, "x@other.com", "tenant-b", "X", "Other")
+		lan.Roles = []string{"user", "admin"}
+		require.NoError(t, store.Update(ctx, nil, lan))
+		_, err := store.SetStatus(ctx, away.ID, "tenant-a", domain.MembershipActive, domain.MembershipTransition{To: domain.MembershipSuspended, At: time.Now()})
+		require.NoError(t, err)
+		require.NoError(t, store.Delete(ctx, gone.ID.Hex(), "tenant-a"))
+
+		// Removed members are left out
+		month := time.Now().UTC().Format("2006-01")
+		all := []string{domain.UserFacetRole, domain.UserFacetIsActive, domain.UserFacetStatus, domain.UserFacetJoinedMonth, domain.UserFacetEmailDomain}
+		facets, err := store.Facets(ctx, MemberQuery{TenantID: "tenant-a"}, "", all)
+		require.NoError(t, err)
+		assert.Equal(t, domain.UserFacets{
+			domain.UserFacetRole:        {{Value: "user", Count: 3}, {Value: "admin", Count: 1}},
+			domain.UserFacetIsActive:    {{Value: "true", Count: 2}, {Value: "false", Count: 1}},
+			domain.UserFacetStatus:      {{Value: "active", Count: 2}, {Value: "suspended", Count: 1}},
+			domain.UserFacetJoinedMonth: {{Value: month, Count: 3}},
+			domain.UserFacetEmailDomain: {{Value: "corp.vn", Count: 2}, {Value: "example.com", Count: 1}},
+		}, facets)
+
+		// Facets honour the filter and the search
+		active := true
+		facets, err = store.Facets(ctx, MemberQuery{TenantID: "tenant-a", Filter: domain.UserFilter{IsActive: &active}}, "", []string{domain.UserFacetEmailDomain})
+		require.NoError(t, err)
+		assert.Equal(t, domain.UserFacets{domain.UserFacetEmailDomain: {{Value: "corp.vn", Count: 2}}}, facets)
+
+		facets, err = store.Facets(ctx, MemberQuery{TenantID: "tenant-a"}, "lan", []string{domain.UserFacetRole})
+		require.NoError(t, err)
+		assert.Equal(t, domain.UserFacets{domain.UserFacetRole: {{Value: "admin", Count: 1}, {Value: "user", Count: 1}}}, facets)
+
+		facets, err = store.Facets(ctx, MemberQuery{TenantID: "tenant-a"}, "!!", []string{domain.UserFacetRole})
+		require.NoError(t, err)
+		assert.Equal(t, domain.UserFacets{domain.UserFacetRole: {}}, facets)
+	})
+
+	t.Run("suggest matches name and email prefixes of active members", func(t *testing.T) {
+		store := newStore(t)
+		create(t, store, "lan@corp.vn", "tenant-a", "Lan", "Phạm")
+		create(t, store, "anh.le@corp.vn", "tenant-a", "Ánh", "Lê")
+		create(t, store, "an@corp.vn", "tenant-a", "An", "Nguyễn")
+		create(t, store, "andrew@corp.vn", "tenant-b", "Andrew", "Other")
+		gone, _ := create(t, store, "anna@corp.vn", "tenant-a", "Anna", "Gone")
+		require.NoError(t, store.Delete(ctx, gone.ID.Hex(), "tenant-a"))
+
+		suggest := func(prefix string, limit int) []string {
+			t.Helper()
+			suggestions, err := store.Suggest(ctx, "tenant-a", prefix, limit)
+			require.NoError(t, err)
+			require.NotNil(t, suggestions)
+			var names []string
+			for _, s := range suggestions {
+				names = append(names, s.DisplayName)
+			}
+			return names
+		}
+
+		// Exact name words first, then by name; deactivated and other tenants' members are left out
+		assert.Equal(t, []string{"An Nguyễn", "Ánh Lê"}, suggest("a", 8))
+		assert.Equal(t, []string{"An Nguyễn"}, suggest("a", 1))
+		assert.Equal(t, []string{"Lan Phạm", "Ánh Lê"}, suggest("l", 8))
+		assert.Equal(t, []string{"Ánh Lê"}, suggest("anh l", 8))
+		// Emails match by prefix only
+		assert.Equal(t, []string{"Ánh Lê"}, suggest("anh.", 8))
+		assert.Equal(t, []string{"Lan Phạm"}, suggest("LAN@", 8))
+		assert.Empty(t, suggest("corp", 8))
+
+		suggestions, err := store.Suggest(ctx, "tenant-a", "lan", 8)
+		require.NoError(t, err)
+		require.Len(t, suggestions, 1)
+		assert.Equal(t, "lan@corp.vn", suggestions[0].Email)
+		assert.NotEmpty(t, suggestions[0].ID)
+	})
+
+	t.Run("count members with role", func(t *testing.T) {
+		store := newStore(t)
+		_, ut := create(t, store, "a@example.com", "tenant-a", "A", "A")
+		create(t, store, "b@example.com", "tenant-a", "B", "B")
+		create(t, store, "c@example.com", "tenant-b", "C", "C")
+
+		ut.Roles = []string{"user", "accountant"}
+		require.NoError(t, store.Update(ctx, nil, ut))
+
+		count, err := store.CountMembersWithRole(ctx, "tenant-a", "user")
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+
+		count, err = store.CountMembersWithRole(ctx, "tenant-a", "accountant")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+
+		count, err = store.CountMembersWithRole(ctx, "tenant-b", "accountant")
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, count)
+	})
+
+	t.Run("tags are scoped to the tenant membership", func(t *testing.T) {
+		store := newStore(t)
+		lan, _ := create(t, store, "lan@example.com", "tenant-a", "Lan", "Phạm")
+		hoa, _ := create(t, store, "hoa@example.com", "tenant-a", "Hoa", "Lê")
+		require.NoError(t, store.AddToTenant(ctx, &domain.UserTenant{UserID: lan.ID, TenantID: "tenant-b", Roles: []string{"user"}, IsActive: true}))
+
+		matched, err := store.AddTags(ctx, "tenant-a", []primitive.ObjectID{lan.ID, hoa.ID, primitive.NewObjectID()}, []string{"vip", "remote"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, matched)
+		matched, err = store.AddTags(ctx, "tenant-a", []primitive.ObjectID{lan.ID}, []string{"vip", "team:backend"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, matched)
+		matched, err = store.RemoveTags(ctx, "tenant-a", []primitive.ObjectID{hoa.ID}, []string{"remote"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, matched)
+
+		_, ut, err := store.FindByID(ctx, lan.ID.Hex(), "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vip", "remote", "team:backend"}, ut.Tags)
+		_, ut, err = store.FindByID(ctx, lan.ID.Hex(), "tenant-b")
+		require.NoError(t, err)
+		assert.Empty(t, ut.Tags)
+
+		counts, err := store.CountTags(ctx, "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, []domain.TagCount{{Tag: "vip", Count: 2}, {Tag: "remote", Count: 1}, {Tag: "team:backend", Count: 1}}, counts)
+		counts, err = store.CountTags(ctx, "tenant-b")
+		require.NoError(t, err)
+		assert.Empty(t, counts)
+
+		results, total, err := store.List(ctx, MemberQuery{TenantID: "tenant-a", Filter: domain.UserFilter{Tag: "remote"}, Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		require.Len(t, results, 1)
+		assert.Equal(t, lan.ID, results[0].User.ID)
+	})
+}
+
+func TestInMemoryUserRepository(t *testing.T) {
+	testUserStore(t, func(t *testing.T) UserStore {
+		return NewInMemoryUserRepository()
+	})
+}
+
+// TestUserRepository runs the conformance suite against a real MongoDB.
+func TestUserRepository(t *testing.T) {
+	testUserStore(t, func(t *testing.T) UserStore {
+		return NewUserRepository(newTestDatabase(t))
+	})
+}
+
+func TestUserRepositoryBackfillMembershipStatus(t *testing.T) {
+	repo := NewUserRepository(newTestDatabase(t))
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	joinedAt := now.AddDate(0, 0, -90)
+	userID := primitive.NewObjectID()
+
+	_, err := repo.userTenants.InsertMany(ctx, []interface{}{
+		bson.M{"userId": userID, "tenantId": "tenant-a", "isActive": true, "joinedAt": joinedAt},
+		bson.M{"userId": userID, "tenantId": "tenant-b", "isActive": false, "joinedAt": joinedAt},
+	})
+	require.NoError(t, err)
+
+	updated, err := repo.BackfillMembershipStatus(ctx, now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, updated)
+
+	var active, removed domain.UserTenant
+	require.NoError(t, repo.userTenants.FindOne(ctx, bson.M{"tenantId": "tenant-a"}).Decode(&active))
+	require.NoError(t, repo.userTenants.FindOne(ctx, bson.M{"tenantId": "tenant-b"}).Decode(&removed))
+	assert.Equal(t, domain.MembershipActive, active.Status)
+	assert.True(t, active.StatusChangedAt.Equal(joinedAt))
+	assert.Equal(t, domain.MembershipRemoved, removed.Status)
+	assert.True(t, removed.StatusChangedAt.Equal(now))
+
+	// A legacy soft delete survives the next purge pass
+	before := now.AddDate(0, 0, -domain.DefaultRetentionDays)
+	due, err := repo.FindRemoved(ctx, RemovedQuery{Before: before})
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	result, err := repo.Purge(ctx, userID, "tenant-b", before, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result.Membership)
+
+	due, err = repo.FindRemoved(ctx, RemovedQuery{Before: now.Add(time.Millisecond)})
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+}
+
+func TestInMemoryUserRepositoryConcurrentCreate(t *testing.T) {
+	store := NewInMemoryUserRepository()
+	ctx := context.Background()
+
+	const tenants = 20
+	errs := make(chan error, tenants)
+	for i := 0; i < tenants; i++ {
+		go func(i int) {
+			errs <- store.Create(ctx,
+				&domain.User{Email: "race@example.com", IsActive: true},
+				&domain.UserTenant{TenantID: fmt.Sprintf("tenant-%d", i), IsActive: true})
+		}(i)
+	}
+	for i := 0; i < tenants; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	user, err := store.FindByIdentifier(ctx, "race@example.com", "email")
+	require.NoError(t, err)
+	require.NotNil(t, user)
+
+	memberships, err := store.FindTenants(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.Len(t, memberships, tenants)
+}
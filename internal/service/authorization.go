@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// authorizer resolves callers' permissions from their memberships and the
+// tenant's role catalog. It is shared by every service that enforces permissions
+type authorizer struct {
+	userRepo repository.UserStore
+	roleRepo repository.RoleStore
+	logger   *logger.Logger
+}
+
+// caller is the authenticated identity behind a request, resolved against a tenant
+type caller struct {
+	identity *auth.Identity
+	roles    []string // Effective roles in the tenant, empty for platform callers
+	catalog  auth.RoleCatalog
+}
+
+// isPlatform reports whether the caller is a platform administrator or internal service
+func (c *caller) isPlatform() bool {
+	return c.identity.IsPlatform()
+}
+
+// canGrant reports whether the caller may assign the given roles to a member
+func (c *caller) canGrant(roles []string) bool {
+	return c.isPlatform() || auth.CanGrantRoles(c.roles, roles, c.catalog)
+}
+
+// holdsAll reports whether the caller holds every one of perms
+func (c *caller) holdsAll(perms []auth.Permission) bool {
+	if c.isPlatform() {
+		return true
+	}
+	held := auth.PermissionsForRoles(c.roles, c.catalog)
+	for _, p := range perms {
+		if !held[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// authorize checks that the caller holds at least one of perms in the tenant
+// Platform identities (tenant "*") are trusted with every permission. Everyone
+// else must be an active member of the tenant; their membership roles are the
+// source of truth, and roles carried by the token can only narrow them
+func (a *authorizer) authorize(ctx context.Context, tenantID string, perms ...auth.Permission) (*caller, error) {
+	identity := auth.IdentityFromContext(ctx)
+	if identity == nil {
+		return nil, errors.Unauthorized("Authentication required")
+	}
+	c := &caller{identity: identity}
+	if identity.IsPlatform() {
+		return c, nil
+	}
+	if !identity.HasTenant(tenantID) {
+		return nil, errors.Forbidden("Access to this tenant is not allowed")
+	}
+
+	roles, err := a.memberRoles(ctx, identity.UserID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(identity.Roles) > 0 {
+		roles = intersectRoles(roles, identity.Roles)
+	}
+	c.roles = roles
+
+	if c.catalog, err = a.catalogFor(ctx, tenantID, roles); err != nil {
+		return nil, err
+	}
+
+	for _, p := range perms {
+		if auth.RolesAllow(roles, c.catalog, p) {
+			return c, nil
+		}
+	}
+	a.logger.Warn("Permission denied",
+		zap.String("user_id", identity.UserID),
+		zap.String("tenant_id", tenantID),
+		zap.Strings("roles", roles),
+		zap.Any("required", perms),
+	)
+	return nil, errors.Forbidden(fmt.Sprintf("Missing permission %s", perms[0]))
+}
+
+// memberRoles returns the roles of an active member, or nil if userID is not an
+// active member of the tenant
+func (a *authorizer) memberRoles(ctx context.Context, userID, tenantID string) ([]string, error) {
+	if validation.ValidateObjectID(userID) != nil {
+		return nil, nil
+	}
+	user, userTenant, err := a.userRepo.FindByID(ctx, userID, tenantID)
+	if err != nil {
+		a.logger.Error("Failed to load caller membership", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to check permissions")
+	}
+	if user == nil || userTenant == nil || !user.IsActive || !userTenant.Active() {
+		return nil, nil
+	}
+	return userTenant.Roles, nil
+}
+
+// catalogFor loads the tenant's custom roles, skipping the lookup when every
+// role in roles is built in
+func (a *authorizer) catalogFor(ctx context.Context, tenantID string, roles []string) (auth.RoleCatalog, error) {
+	custom := false
+	for _, r := range roles {
+		if !auth.IsBuiltinRole(r) {
+			custom = true
+			break
+		}
+	}
+	if !custom {
+		return nil, nil
+	}
+	return a.catalog(ctx, tenantID)
+}
+
+// catalog loads every custom role of a tenant
+func (a *authorizer) catalog(ctx context.Context, tenantID string) (auth.RoleCatalog, error) {
+	roles, err := a.roleRepo.List(ctx, tenantID)
+	if err != nil {
+		a.logger.Error("Failed to load role catalog", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to check permissions")
+	}
+	catalog := make(auth.RoleCatalog, len(roles))
+	for _, role := range roles {
+		catalog[role.Key] = toPermissions(role.Permissions)
+	}
+	return catalog, nil
+}
+
+// validateAssignment checks that every role is built in or defined in the
+// tenant's catalog, and extends the caller's catalog with the tenant's custom
+// roles so canGrant can see their permissions
+func (a *authorizer) validateAssignment(ctx context.Context, c *caller, tenantID string, roles []string) error {
+	catalog, err := a.catalogFor(ctx, tenantID, roles)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if _, ok := catalog[r]; !ok && !auth.IsBuiltinRole(r) {
+			return errors.BadRequest(fmt.Sprintf("unknown role %q", r))
+		}
+	}
+	if catalog != nil {
+		c.catalog = catalog
+	}
+	return nil
+}
+
+// CheckPermission reports whether a user may perform an action in a tenant
+// Used by other services; inactive users and non-members are never allowed.
+// Callers must be platform services or have access to the tenant
+func (s *UserService) CheckPermission(ctx context.Context, userID, tenantID, permission string) (*domain.PermissionCheck, error) {
+	if err := validation.ValidateObjectID(userID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if !auth.IsValidPermission(auth.Permission(permission)) {
+		return nil, errors.BadRequest(fmt.Sprintf("unknown permission %q", permission))
+	}
+
+	identity := auth.IdentityFromContext(ctx)
+	if identity == nil {
+		return nil, errors.Unauthorized("Authentication required")
+	}
+	if !identity.HasTenant(tenantID) {
+		return nil, errors.Forbidden("Access to tenant " + tenantID + " is not allowed")
+	}
+
+	roles, err := s.authz.memberRoles(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := s.authz.catalogFor(ctx, tenantID, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PermissionCheck{
+		Allowed: auth.RolesAllow(roles, catalog, auth.Permission(permission)),
+		Roles:   roles,
+	}, nil
+}
+
+// intersectRoles returns the roles present in both lists, in the order of a
+func intersectRoles(a, b []string) []string {
+	allowed := make(map[string]bool, len(b))
+	for _, r := range b {
+		allowed[r] = true
+	}
+	var result []string
+	for _, r := range a {
+		if allowed[r] {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// toPermissions converts stored permission strings to auth.Permission values
+func toPermissions(perms []string) []auth.Permission {
+	result := make([]auth.Permission, len(perms))
+	for i, p := range perms {
+		result[i] = auth.Permission(p)
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// memberContext returns a context authenticated as a member of tenantID
+func memberContext(userID, tenantID string, tokenRoles ...string) context.Context {
+	return auth.WithIdentity(context.Background(), &auth.Identity{
+		UserID:    userID,
+		TenantIDs: []string{tenantID},
+		Roles:     tokenRoles,
+	})
+}
+
+func TestUserService_Authorization(t *testing.T) {
+	svc := newTestUserService(t)
+	admin := platformContext()
+
+	create := func(email string, roles ...string) string {
+		profile, err := svc.CreateUser(admin, &domain.CreateUserRequest{Email: email, TenantID: "tenant-a", Roles: roles})
+		require.NoError(t, err)
+		return profile.User.ID.Hex()
+	}
+	ownerID := create("owner@example.com", "owner")
+	managerID := create("manager@example.com", "manager")
+	userID := create("user@example.com", "user")
+	otherID := create("other@example.com", "user")
+
+	ownerCtx := memberContext(ownerID, "tenant-a")
+	managerCtx := memberContext(managerID, "tenant-a")
+	userCtx := memberContext(userID, "tenant-a")
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := svc.CreateUser(context.Background(), &domain.CreateUserRequest{Email: "x@example.com", TenantID: "tenant-a"})
+		assertStatus(t, err, http.StatusUnauthorized)
+	})
+
+	t.Run("user updates self but not others", func(t *testing.T) {
+		_, err := svc.UpdateUser(userCtx, userID, "tenant-a", &domain.UpdateUserRequest{FirstName: "Me"})
+		assert.NoError(t, err)
+
+		_, err = svc.UpdateUser(userCtx, otherID, "tenant-a", &domain.UpdateUserRequest{FirstName: "You"})
+		assertStatus(t, err, http.StatusForbidden)
+	})
+
+	t.Run("user cannot create or delete", func(t *testing.T) {
+		_, err := svc.CreateUser(userCtx, &domain.CreateUserRequest{Email: "new@example.com", TenantID: "tenant-a"})
+		assertStatus(t, err, http.StatusForbidden)
+
+		assertStatus(t, svc.DeleteUser(userCtx, otherID, "tenant-a"), http.StatusForbidden)
+	})
+
+	t.Run("manager creates and updates but cannot delete", func(t *testing.T) {
+		_, err := svc.CreateUser(managerCtx, &domain.CreateUserRequest{Email: "hire@example.com", TenantID: "tenant-a"})
+		assert.NoError(t, err)
+
+		_, err = svc.UpdateUser(managerCtx, otherID, "tenant-a", &domain.UpdateUserRequest{FirstName: "Renamed"})
+		assert.NoError(t, err)
+
+		assertStatus(t, svc.DeleteUser(managerCtx, otherID, "tenant-a"), http.StatusForbidden)
+	})
+
+	t.Run("manager cannot grant admin", func(t *testing.T) {
+		_, err := svc.CreateUser(managerCtx, &domain.CreateUserRequest{Email: "boss@example.com", TenantID: "tenant-a", Roles: []string{"admin"}})
+		assertStatus(t, err, http.StatusForbidden)
+	})
+
+	t.Run("manager cannot demote owner", func(t *testing.T) {
+		_, err := svc.UpdateUser(managerCtx, ownerID, "tenant-a", &domain.UpdateUserRequest{Roles: []string{"user"}})
+		assertStatus(t, err, http.StatusForbidden)
+
+		_, err = svc.UpdateUser(managerCtx, userID, "tenant-a", &domain.UpdateUserRequest{Roles: []string{"manager"}})
+		assert.NoError(t, err)
+		_, err = svc.UpdateUser(managerCtx, userID, "tenant-a", &domain.UpdateUserRequest{Roles: []string{"user"}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("custom deleter cannot remove owner", func(t *testing.T) {
+		err := svc.authz.roleRepo.Create(context.Background(), &domain.Role{
+			TenantID: "tenant-a", Key: "offboarder", DisplayName: "Offboarder", Permissions: []string{"users:delete"},
+		})
+		require.NoError(t, err)
+		deleterID := create("deleter@example.com", "offboarder")
+		deleterCtx := memberContext(deleterID, "tenant-a")
+
+		assertStatus(t, svc.DeleteUser(deleterCtx, ownerID, "tenant-a"), http.StatusForbidden)
+		assertStatus(t, svc.DeleteUser(memberContext(managerID, "tenant-a", "manager"), ownerID, "tenant-a"), http.StatusForbidden)
+	})
+
+	t.Run("token roles narrow membership roles", func(t *testing.T) {
+		_, err := svc.UpdateUser(memberContext(ownerID, "tenant-a", "user"), otherID, "tenant-a", &domain.UpdateUserRequest{FirstName: "X"})
+		assertStatus(t, err, http.StatusForbidden)
+	})
+
+	t.Run("non-member is denied", func(t *testing.T) {
+		err := svc.DeleteUser(memberContext(ownerID, "tenant-b"), otherID, "tenant-b")
+		assertStatus(t, err, http.StatusForbidden)
+	})
+
+	t.Run("owner deletes", func(t *testing.T) {
+		assert.NoError(t, svc.DeleteUser(ownerCtx, otherID, "tenant-a"))
+	})
+}
+
+func TestUserService_CheckPermission(t *testing.T) {
+	svc := newTestUserService(t)
+
+	profile, err := svc.CreateUser(platformContext(), &domain.CreateUserRequest{Email: "mgr@example.com", TenantID: "tenant-a", Roles: []string{"manager"}})
+	require.NoError(t, err)
+	id := profile.User.ID.Hex()
+
+	tests := []struct {
+		name       string
+		tenantID   string
+		permission string
+		allowed    bool
+		status     int
+	}{
+		{name: "granted", tenantID: "tenant-a", permission: "users:update:any", allowed: true},
+		{name: "not granted", tenantID: "tenant-a", permission: "users:delete", allowed: false},
+		{name: "not a member", tenantID: "tenant-b", permission: "users:create", allowed: false},
+		{name: "unknown permission", tenantID: "tenant-a", permission: "users:fly", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.CheckPermission(platformContext(), id, tt.tenantID, tt.permission)
+			if tt.status != 0 {
+				assertStatus(t, err, tt.status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, result.Allowed)
+		})
+	}
+	t.Run("caller needs access to the tenant", func(t *testing.T) {
+		result, err := svc.CheckPermission(memberContext(id, "tenant-a"), id, "tenant-a", "users:create")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		_, err = svc.CheckPermission(memberContext(id, "tenant-b"), id, "tenant-a", "users:create")
+		assertStatus(t, err, http.StatusForbidden)
+		_, err = svc.CheckPermission(context.Background(), id, "tenant-a", "users:create")
+		assertStatus(t, err, http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memberCursor is the encoded form of a position in a user listing: the sort
+// it was issued for, the sort key values of the last member returned and its
+// membership _id
+type memberCursor struct {
+	Sort string   `json:"s"`
+	Keys []string `json:"k"`
+	ID   string   `json:"i"`
+}
+
+// encodeMemberCursor encodes a position in the given sort order as an opaque,
+// URL-safe cursor
+func encodeMemberCursor(pos *repository.MemberPosition, order domain.UserSort) string {
+	c := memberCursor{Sort: order.String(), ID: pos.ID.Hex()}
+	for _, key := range pos.Keys {
+		switch v := key.(type) {
+		case time.Time:
+			c.Keys = append(c.Keys, v.UTC().Format(time.RFC3339Nano))
+		case string:
+			c.Keys = append(c.Keys, v)
+		case int:
+			c.Keys = append(c.Keys, strconv.Itoa(v))
+		}
+	}
+	encoded, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodeMemberCursor decodes a cursor produced by encodeMemberCursor for the same sort order
+func decodeMemberCursor(cursor string, order domain.UserSort) (*repository.MemberPosition, error) {
+	invalid := fmt.Errorf("cursor is invalid")
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var c memberCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, invalid
+	}
+	if c.Sort != order.String() {
+		return nil, fmt.Errorf("cursor was issued for sort %q, not %q", c.Sort, order.String())
+	}
+	id, err := primitive.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return nil, invalid
+	}
+
+	pos := &repository.MemberPosition{ID: id}
+	switch order.Field {
+	case domain.UserSortName:
+		if len(c.Keys) != 2 {
+			return nil, invalid
+		}
+		pos.Keys = []interface{}{c.Keys[0], c.Keys[1]}
+	case domain.UserSortEmail:
+		if len(c.Keys) != 1 {
+			return nil, invalid
+		}
+		pos.Keys = []interface{}{c.Keys[0]}
+	case domain.UserSortRelevance:
+		if len(c.Keys) != 2 {
+			return nil, invalid
+		}
+		score, err := strconv.Atoi(c.Keys[0])
+		if err != nil {
+			return nil, invalid
+		}
+		t, err := time.Parse(time.RFC3339Nano, c.Keys[1])
+		if err != nil {
+			return nil, invalid
+		}
+		pos.Keys = []interface{}{score, t}
+	default:
+		if len(c.Keys) != 1 {
+			return nil, invalid
+		}
+		t, err := time.Parse(time.RFC3339Nano, c.Keys[0])
+		if err != nil {
+			return nil, invalid
+		}
+		pos.Keys = []interface{}{t}
+	}
+	return pos, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.uber.org/zap"
+)
+
+// EventPublisher delivers membership events to other services
+type EventPublisher interface {
+	Publish(ctx context.Context, event *domain.MembershipEvent) error
+}
+
+// LogEventPublisher publishes events as structured log entries, for
+// deployments that ship their logs to the services reacting to them
+type LogEventPublisher struct {
+	logger *logger.Logger
+}
+
+// NewLogEventPublisher creates a new log event publisher
+func NewLogEventPublisher(log *logger.Logger) *LogEventPublisher {
+	return &LogEventPublisher{logger: log}
+}
+
+// Publish logs the event
+func (p *LogEventPublisher) Publish(ctx context.Context, event *domain.MembershipEvent) error {
+	p.logger.Info("Membership event",
+		zap.String("event_type", event.Type),
+		zap.String("tenant_id", event.TenantID),
+		zap.String("user_id", event.UserID),
+		zap.Time("at", event.At),
+		zap.Any("details", event.Details),
+	)
+	return nil
+}
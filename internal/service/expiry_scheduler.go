@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Defaults of ExpiryConfig
+const (
+	DefaultExpiryInterval  = 5 * time.Minute
+	DefaultExpiryBatchSize = 100
+)
+
+// ExpiryConfig configures the ExpiryScheduler
+type ExpiryConfig struct {
+	Interval  time.Duration // How often expired memberships are checked
+	BatchSize int           // Memberships read per query
+}
+
+// ExpiryConfigFromEnv builds an ExpiryConfig from MEMBERSHIP_EXPIRY_INTERVAL
+// (a Go duration, default 5m)
+func ExpiryConfigFromEnv() ExpiryConfig {
+	cfg := ExpiryConfig{Interval: DefaultExpiryInterval, BatchSize: DefaultExpiryBatchSize}
+	if v := os.Getenv("MEMBERSHIP_EXPIRY_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Interval = d
+		}
+	}
+	return cfg
+}
+
+// ExpiryScheduler deactivates active and suspended memberships once their
+// ExpiresAt has passed and publishes a domain.EventMembershipExpired event for each
+type ExpiryScheduler struct {
+	userRepo repository.UserStore
+	events   EventPublisher
+	cfg      ExpiryConfig
+	logger   *logger.Logger
+}
+
+// NewExpiryScheduler creates a new membership expiry scheduler
+func NewExpiryScheduler(userRepo repository.UserStore, events EventPublisher, cfg ExpiryConfig, log *logger.Logger) *ExpiryScheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultExpiryInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultExpiryBatchSize
+	}
+	return &ExpiryScheduler{
+		userRepo: userRepo,
+		events:   events,
+		cfg:      cfg,
+		logger:   log,
+	}
+}
+
+// Run expires memberships once right away and then every Interval until ctx is done
+func (e *ExpiryScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := e.ExpireOnce(ctx, time.Now())
+		if err != nil {
+			e.logger.Error("Failed to expire memberships", zap.Int("expired", expired), zap.Error(err))
+		} else if expired > 0 {
+			e.logger.Info("Expired memberships", zap.Int("expired", expired))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireOnce deactivates every membership that expired at or before now and
+// returns how many were deactivated
+func (e *ExpiryScheduler) ExpireOnce(ctx context.Context, now time.Time) (int, error) {
+	expired := 0
+	for {
+		due, err := e.userRepo.FindExpired(ctx, now, e.cfg.BatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		batch := 0
+		for _, ut := range due {
+			ok, err := e.expire(ctx, ut, now)
+			if err != nil {
+				return expired, err
+			}
+			if ok {
+				batch++
+			}
+		}
+		expired += batch
+
+		// Memberships changed since they were read are skipped, so an
+		// unproductive batch means the rest are not due either
+		if len(due) < e.cfg.BatchSize || batch == 0 {
+			return expired, nil
+		}
+	}
+}
+
+// expire deactivates one membership and publishes its event, reporting false
+// when its status changed in the meantime
+func (e *ExpiryScheduler) expire(ctx context.Context, ut *domain.UserTenant, now time.Time) (bool, error) {
+	from := ut.CurrentStatus()
+	transition := domain.MembershipTransition{
+		From:   from,
+		To:     domain.MembershipDeactivated,
+		Reason: "membership expired",
+		By:     domain.ActorExpiryScheduler,
+		At:     now.UTC(),
+	}
+	ok, err := e.userRepo.SetStatus(ctx, ut.UserID, ut.TenantID, from, transition)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	userID := ut.UserID.Hex()
+	fields := []zap.Field{zap.String("user_id", userID), zap.String("tenant_id", ut.TenantID)}
+	event := &domain.MembershipEvent{
+		Type:     domain.EventMembershipExpired,
+		TenantID: ut.TenantID,
+		UserID:   userID,
+		At:       transition.At,
+		Details: map[string]string{
+			"expiresAt":      ut.ExpiresAt.UTC().Format(time.RFC3339),
+			"previousStatus": string(from),
+		},
+	}
+	if err := e.events.Publish(ctx, event); err != nil {
+		e.logger.Error("Failed to publish membership event", append(fields, zap.String("event_type", event.Type), zap.Error(err))...)
+	}
+
+	e.logger.Info("Membership expired", fields...)
+	return true, nil
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// ExportService exports everything the service stores about a user as a
+// machine-readable bundle
+type ExportService struct {
+	userRepo  repository.UserStore
+	prefRepo  repository.PreferencesStore
+	auditRepo repository.AuditStore
+	logger    *logger.Logger
+}
+
+// NewExportService creates a new export service
+func NewExportService(userRepo repository.UserStore, prefRepo repository.PreferencesStore, auditRepo repository.AuditStore, log *logger.Logger) *ExportService {
+	return &ExportService{
+		userRepo:  userRepo,
+		prefRepo:  prefRepo,
+		auditRepo: auditRepo,
+		logger:    log,
+	}
+}
+
+// ExportMe exports the caller's own data in format (json by default)
+func (s *ExportService) ExportMe(ctx context.Context, format string) (*domain.ExportBundle, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.export(ctx, userID, userID, format)
+}
+
+// ExportUser exports a user's data in format (json by default). Exports span
+// every tenant of the user, so they need a platform identity
+func (s *ExportService) ExportUser(ctx context.Context, userID, format string) (*domain.ExportBundle, error) {
+	if err := validation.ValidateObjectID(userID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	identity := auth.IdentityFromContext(ctx)
+	if identity == nil {
+		return nil, errors.Unauthorized("Authentication required")
+	}
+	if !identity.IsPlatform() {
+		return nil, errors.Forbidden("Only platform administrators can export other users")
+	}
+	return s.export(ctx, userID, identity.UserID, format)
+}
+
+// export gathers and encodes the data of userID and records the export in the
+// audit log on behalf of requestedBy
+func (s *ExportService) export(ctx context.Context, userID, requestedBy, format string) (*domain.ExportBundle, error) {
+	if format == "" {
+		format = domain.ExportFormatJSON
+	}
+	if err := validation.ValidateExportFormat(format); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	export, err := s.collect(ctx, userID, requestedBy, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	export.Manifest.Format = format
+
+	bundle, err := encodeExport(export)
+	if err != nil {
+		s.logger.Error("Failed to encode user export", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to export user data")
+	}
+
+	// The export itself is recorded after gathering, so it shows up in the next one
+	entry := &domain.AuditEntry{
+		UserID:  userID,
+		Action:  domain.AuditUserExported,
+		ActorID: requestedBy,
+		Details: map[string]string{"format": format},
+	}
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry",
+			zap.String("user_id", userID),
+			zap.String("action", entry.Action),
+			zap.Error(err),
+		)
+	}
+
+	s.logger.Info("User data exported",
+		zap.String("user_id", userID),
+		zap.String("requested_by", requestedBy),
+		zap.String("format", format),
+	)
+
+	return bundle, nil
+}
+
+// collect gathers everything stored about userID
+func (s *ExportService) collect(ctx context.Context, userID, requestedBy string, now time.Time) (*domain.UserExport, error) {
+	fail := func(what string, err error) error {
+		s.logger.Error("Failed to export "+what, zap.String("user_id", userID), zap.Error(err))
+		return errors.Internal("Failed to export user data")
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, fail("user", err)
+	}
+	if user == nil {
+		return nil, errors.NotFound("User not found")
+	}
+
+	memberships, err := s.userRepo.FindTenants(ctx, userID)
+	if err != nil {
+		return nil, fail("memberships", err)
+	}
+	sort.Slice(memberships, func(i, j int) bool { return memberships[i].TenantID < memberships[j].TenantID })
+
+	export := &domain.UserExport{
+		User:        user,
+		Memberships: memberships,
+		Preferences: []*domain.UserPreferences{},
+		Consents:    []interface{}{},
+		Tags:        []domain.ExportedTags{},
+	}
+	if export.Memberships == nil {
+		export.Memberships = []*domain.UserTenant{}
+	}
+	for _, ut := range memberships {
+		prefs, err := s.prefRepo.Find(ctx, userID, ut.TenantID)
+		if err != nil {
+			return nil, fail("preferences", err)
+		}
+		if prefs != nil {
+			export.Preferences = append(export.Preferences, prefs)
+		}
+		if len(ut.Tags) > 0 {
+			export.Tags = append(export.Tags, domain.ExportedTags{TenantID: ut.TenantID, Tags: ut.Tags})
+		}
+	}
+
+	export.AuditLog, err = s.auditRepo.List(ctx, repository.AuditQuery{UserID: userID})
+	if err != nil {
+		return nil, fail("audit log", err)
+	}
+	if export.AuditLog == nil {
+		export.AuditLog = []*domain.AuditEntry{}
+	}
+
+	export.Manifest = domain.ExportManifest{
+		Version:     domain.ExportVersion,
+		UserID:      userID,
+		GeneratedAt: now,
+		RequestedBy: requestedBy,
+		Sections: []domain.ExportSection{
+			{
+				Name:        domain.ExportSectionUser,
+				Description: "The global user: identifiers and account status. The password hash is a credential and is left out",
+				Records:     1,
+			},
+			{
+				Name:        domain.ExportSectionMemberships,
+				Description: "Every tenant membership, removed ones included, with roles, names, status history, expiry and last activity",
+				Records:     len(export.Memberships),
+			},
+			{
+				Name:        domain.ExportSectionPreferences,
+				Description: "Preferences stored for each tenant",
+				Records:     len(export.Preferences),
+			},
+			{
+				Name:        domain.ExportSectionConsents,
+				Description: "Consent records. The service does not record consents, so this section is empty",
+				Records:     len(export.Consents),
+			},
+			{
+				Name:        domain.ExportSectionAuditLog,
+				Description: "Audit log entries about the user, newest first",
+				Records:     len(export.AuditLog),
+			},
+			{
+				Name:        domain.ExportSectionTags,
+				Description: "Tags on each tenant membership",
+				Records:     len(export.Tags),
+			},
+		},
+	}
+	return export, nil
+}
+
+// encodeExport encodes export in its manifest's format
+func encodeExport(export *domain.UserExport) (*domain.ExportBundle, error) {
+	name := fmt.Sprintf("user-export-%s-%s", export.Manifest.UserID, export.Manifest.GeneratedAt.Format("20060102T150405Z"))
+
+	if export.Manifest.Format == domain.ExportFormatJSON {
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &domain.ExportBundle{
+			Format:      domain.ExportFormatJSON,
+			ContentType: "application/json",
+			FileName:    name + ".json",
+			Data:        data,
+		}, nil
+	}
+
+	values := map[string]interface{}{
+		domain.ExportSectionUser:        export.User,
+		domain.ExportSectionMemberships: export.Memberships,
+		domain.ExportSectionPreferences: export.Preferences,
+		domain.ExportSectionConsents:    export.Consents,
+		domain.ExportSectionAuditLog:    export.AuditLog,
+		domain.ExportSectionTags:        export.Tags,
+	}
+	files := make([][]byte, len(export.Manifest.Sections))
+	for i := range export.Manifest.Sections {
+		section := &export.Manifest.Sections[i]
+		data, err := json.MarshalIndent(values[section.Name], "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		section.File = section.Name + ".json"
+		section.SHA256 = hex.EncodeToString(sum[:])
+		files[i] = data
+	}
+	manifest, err := json.MarshalIndent(export.Manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(file string, data []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: file, Method: zip.Deflate, Modified: export.Manifest.GeneratedAt})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	if err := write("manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	for i, section := range export.Manifest.Sections {
+		if err := write(section.File, files[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &domain.ExportBundle{
+		Format:      domain.ExportFormatZIP,
+		ContentType: "application/zip",
+		FileName:    name + ".zip",
+		Data:        buf.Bytes(),
+	}, nil
+}
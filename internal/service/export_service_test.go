@@ -0,0 +1,109 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+)
+
+func TestExportService(t *testing.T) {
+	services := newTestServices(t)
+	userRepo, prefRepo, auditRepo, users := services.userRepo, services.prefRepo, services.auditRepo, services.users
+	exports := NewExportService(userRepo, prefRepo, auditRepo, services.log)
+	ctx := platformContext()
+	bg := context.Background()
+
+	profile, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "lan@example.com", Password: "s3cret-Passw0rd", TenantID: "tenant-b"})
+	require.NoError(t, err)
+	lanID := profile.User.ID.Hex()
+	_, err = users.AddUserToTenant(ctx, lanID, "tenant-a", nil, "")
+	require.NoError(t, err)
+	_, err = users.AddUserTags(ctx, lanID, "tenant-a", []string{"vip"})
+	require.NoError(t, err)
+	require.NoError(t, prefRepo.Save(bg, &domain.UserPreferences{UserID: lanID, TenantID: "tenant-a", Language: "vi"}))
+	require.NoError(t, auditRepo.Record(bg, &domain.AuditEntry{TenantID: "tenant-c", UserID: lanID, Action: domain.AuditMembershipPurged, ActorID: domain.AuditActorPurger}))
+	other, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "hoa@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+
+	bundle, err := exports.ExportMe(memberContext(lanID, "tenant-a"), "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExportFormatJSON, bundle.Format)
+	assert.Equal(t, "application/json", bundle.ContentType)
+	assert.NotContains(t, string(bundle.Data), "s3cret")
+
+	var export domain.UserExport
+	require.NoError(t, json.Unmarshal(bundle.Data, &export))
+	assert.Equal(t, lanID, export.Manifest.UserID)
+	assert.Equal(t, lanID, export.Manifest.RequestedBy)
+	assert.Equal(t, "lan@example.com", export.User.Email)
+	require.Len(t, export.Memberships, 2)
+	assert.Equal(t, "tenant-a", export.Memberships[0].TenantID)
+	require.Len(t, export.Preferences, 1)
+	assert.Equal(t, "vi", export.Preferences[0].Language)
+	assert.Empty(t, export.Consents)
+	require.Len(t, export.AuditLog, 1)
+	assert.Equal(t, []domain.ExportedTags{{TenantID: "tenant-a", Tags: []string{"vip"}}}, export.Tags)
+	records := map[string]int{}
+	for _, section := range export.Manifest.Sections {
+		records[section.Name] = section.Records
+	}
+	assert.Equal(t, map[string]int{"user": 1, "memberships": 2, "preferences": 1, "consents": 0, "audit_log": 1, "tags": 1}, records)
+
+	// Exports are audited and show up in the next one
+	entries, err := auditRepo.List(bg, repository.AuditQuery{UserID: lanID})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, domain.AuditUserExported, entries[0].Action)
+	assert.Equal(t, lanID, entries[0].ActorID)
+
+	// ZIP bundles hold the manifest and a checksummed file per section
+	bundle, err = exports.ExportUser(ctx, lanID, domain.ExportFormatZIP)
+	require.NoError(t, err)
+	assert.Equal(t, "application/zip", bundle.ContentType)
+	zr, err := zip.NewReader(bytes.NewReader(bundle.Data), int64(len(bundle.Data)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+	}
+	var manifest domain.ExportManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Equal(t, domain.ExportFormatZIP, manifest.Format)
+	assert.Equal(t, "test-service", manifest.RequestedBy)
+	require.Len(t, manifest.Sections, 6)
+	assert.Len(t, files, 7)
+	for _, section := range manifest.Sections {
+		data, ok := files[section.File]
+		require.True(t, ok, section.File)
+		sum := sha256.Sum256(data)
+		assert.Equal(t, hex.EncodeToString(sum[:]), section.SHA256, section.Name)
+	}
+	var audit []*domain.AuditEntry
+	require.NoError(t, json.Unmarshal(files["audit_log.json"], &audit))
+	assert.Len(t, audit, 2)
+
+	// Only platform administrators export other users
+	_, err = exports.ExportUser(memberContext(lanID, "tenant-a"), other.User.ID.Hex(), "")
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = exports.ExportUser(ctx, "000000000000000000000000", "")
+	assertStatus(t, err, http.StatusNotFound)
+	_, err = exports.ExportMe(memberContext(lanID, "tenant-a"), "csv")
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = exports.ExportMe(ctx, "")
+	assertStatus(t, err, http.StatusNotFound)
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// Defaults of InactivityConfig
+const (
+	DefaultInactivityInterval  = time.Hour
+	DefaultInactivityBatchSize = 100
+)
+
+// InactivityConfig configures the InactivityJob
+type InactivityConfig struct {
+	Interval  time.Duration // How often inactivity policies are applied
+	BatchSize int           // Memberships read per query
+	DryRun    bool          // Report what would change without changing anything
+}
+
+// InactivityConfigFromEnv builds an InactivityConfig from INACTIVITY_POLICY_INTERVAL
+// (a Go duration, default 1h) and INACTIVITY_POLICY_DRY_RUN (a boolean, default false)
+func InactivityConfigFromEnv() InactivityConfig {
+	cfg := InactivityConfig{Interval: DefaultInactivityInterval, BatchSize: DefaultInactivityBatchSize}
+	if v := os.Getenv("INACTIVITY_POLICY_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Interval = d
+		}
+	}
+	if v := os.Getenv("INACTIVITY_POLICY_DRY_RUN"); v != "" {
+		if dryRun, err := strconv.ParseBool(v); err == nil {
+			cfg.DryRun = dryRun
+		}
+	}
+	return cfg
+}
+
+// InactivityJob applies the tenants' inactivity policies: active members not
+// seen for InactivityWarnDays are warned once, and those not seen for
+// InactivitySuspendDays are suspended. When a tenant warns, members are only
+// suspended once their warning is at least the gap between both thresholds
+// old. Both steps publish a membership event
+//
+// Members are only seen through opaque token verification and
+// UserService.TouchMembership; JWT traffic does not count as activity. Members
+// never seen count from when their tenant turned the thresholds on
+type InactivityJob struct {
+	userRepo   repository.UserStore
+	policyRepo repository.PolicyStore
+	events     EventPublisher
+	cfg        InactivityConfig
+	logger     *logger.Logger
+}
+
+// NewInactivityJob creates a new inactivity job
+func NewInactivityJob(userRepo repository.UserStore, policyRepo repository.PolicyStore, events EventPublisher, cfg InactivityConfig, log *logger.Logger) *InactivityJob {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInactivityInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultInactivityBatchSize
+	}
+	return &InactivityJob{
+		userRepo:   userRepo,
+		policyRepo: policyRepo,
+		events:     events,
+		cfg:        cfg,
+		logger:     log,
+	}
+}
+
+// Run applies every tenant's policy once right away and then every Interval
+// until ctx is done, logging what changed
+func (j *InactivityJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		reports, err := j.ApplyAll(ctx, time.Now(), j.cfg.DryRun)
+		for _, report := range reports {
+			if report.Changed() {
+				j.logReport(report)
+			}
+		}
+		if err != nil {
+			j.logger.Error("Failed to apply inactivity policies", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ApplyAll applies the policy of every tenant with inactivity thresholds and
+// returns a report per tenant, including those of tenants applied before an error
+func (j *InactivityJob) ApplyAll(ctx context.Context, now time.Time, dryRun bool) ([]*domain.InactivityReport, error) {
+	policies, err := j.policyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*domain.InactivityReport
+	for _, policy := range policies {
+		if !policy.EnforcesInactivity() {
+			continue
+		}
+		report, err := j.Apply(ctx, policy, now, dryRun, domain.ActorInactivityJob)
+		reports = append(reports, report)
+		if err != nil {
+			return reports, err
+		}
+	}
+	return reports, nil
+}
+
+// Apply applies policy to its tenant's members as of now on behalf of by and
+// reports the members warned and suspended. A dry run changes nothing and
+// reports what would have changed
+func (j *InactivityJob) Apply(ctx context.Context, policy *domain.MembershipPolicy, now time.Time, dryRun bool, by string) (*domain.InactivityReport, error) {
+	report := &domain.InactivityReport{
+		TenantID:    policy.TenantID,
+		DryRun:      dryRun,
+		RanAt:       now.UTC(),
+		WarnDays:    policy.InactivityWarnDays,
+		SuspendDays: policy.InactivitySuspendDays,
+		Warned:      []domain.InactiveMember{},
+		Suspended:   []domain.InactiveMember{},
+	}
+
+	// On a dry run suspended members stay active, so they are skipped explicitly
+	suspended := make(map[primitive.ObjectID]bool)
+	trackedSince := policy.InactivityTrackedSince()
+	if days := policy.InactivitySuspendDays; days > 0 {
+		// Warnings must be this old before members are suspended
+		noticeBefore := now.AddDate(0, 0, policy.InactivityWarnDays-days)
+		err := j.eachInactive(ctx, policy.TenantID, now.AddDate(0, 0, -days), trackedSince, func(ut *domain.UserTenant) error {
+			if policy.InactivityWarnDays > 0 &&
+				(!warnedSince(ut, trackedSince) || ut.InactivityWarnedAt.After(noticeBefore)) {
+				return nil
+			}
+			if !dryRun {
+				ok, err := j.suspend(ctx, ut, policy, now, by)
+				if err != nil || !ok {
+					return err
+				}
+			}
+			suspended[ut.ID] = true
+			report.Suspended = append(report.Suspended, toInactiveMember(ut, trackedSince))
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if days := policy.InactivityWarnDays; days > 0 {
+		err := j.eachInactive(ctx, policy.TenantID, now.AddDate(0, 0, -days), trackedSince, func(ut *domain.UserTenant) error {
+			if suspended[ut.ID] || warnedSince(ut, trackedSince) {
+				return nil
+			}
+			if !dryRun {
+				ok, err := j.warn(ctx, ut, policy, now)
+				if err != nil || !ok {
+					return err
+				}
+			}
+			report.Warned = append(report.Warned, toInactiveMember(ut, trackedSince))
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// eachInactive calls fn with every active member of the tenant inactive since
+// before seenBefore, counting members never seen from trackedSince
+func (j *InactivityJob) eachInactive(ctx context.Context, tenantID string, seenBefore, trackedSince time.Time, fn func(*domain.UserTenant) error) error {
+	q := repository.InactiveQuery{TenantID: tenantID, SeenBefore: seenBefore, TrackedSince: trackedSince, Limit: j.cfg.BatchSize}
+	for {
+		batch, err := j.userRepo.FindInactive(ctx, q)
+		if err != nil {
+			return err
+		}
+		for _, ut := range batch {
+			if err := fn(ut); err != nil {
+				return err
+			}
+		}
+		if len(batch) < q.Limit {
+			return nil
+		}
+		q.After = batch[len(batch)-1].ID
+	}
+}
+
+// suspend suspends one inactive member and publishes its event, reporting
+// false when the membership changed in the meantime
+func (j *InactivityJob) suspend(ctx context.Context, ut *domain.UserTenant, policy *domain.MembershipPolicy, now time.Time, by string) (bool, error) {
+	days := policy.InactivitySuspendDays
+	transition := domain.MembershipTransition{
+		From:   domain.MembershipActive,
+		To:     domain.MembershipSuspended,
+		Reason: fmt.Sprintf("inactive for %d days", days),
+		By:     by,
+		At:     now.UTC(),
+	}
+	ok, err := j.userRepo.SetStatus(ctx, ut.UserID, ut.TenantID, domain.MembershipActive, transition)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	j.publish(ctx, &domain.MembershipEvent{
+		Type:     domain.EventInactivitySuspended,
+		TenantID: ut.TenantID,
+		UserID:   ut.UserID.Hex(),
+		At:       transition.At,
+		Details: map[string]string{
+			"inactiveSince": ut.InactiveSince(policy.InactivityTrackedSince()).UTC().Format(time.RFC3339),
+			"suspendDays":   strconv.Itoa(days),
+		},
+	})
+	return true, nil
+}
+
+// warn records that one inactive member was warned and publishes its event,
+// reporting false when the membership changed in the meantime
+func (j *InactivityJob) warn(ctx context.Context, ut *domain.UserTenant, policy *domain.MembershipPolicy, now time.Time) (bool, error) {
+	ok, err := j.userRepo.MarkInactivityWarned(ctx, ut.UserID, ut.TenantID, now.UTC())
+	if err != nil || !ok {
+		return false, err
+	}
+
+	inactiveSince := ut.InactiveSince(policy.InactivityTrackedSince())
+	details := map[string]string{
+		"inactiveSince": inactiveSince.UTC().Format(time.RFC3339),
+		"warnDays":      strconv.Itoa(policy.InactivityWarnDays),
+	}
+	if policy.InactivitySuspendDays > 0 {
+		// Suspended once inactive long enough and warned long enough ago
+		suspendAt := inactiveSince.AddDate(0, 0, policy.InactivitySuspendDays)
+		if notice := now.AddDate(0, 0, policy.InactivitySuspendDays-policy.InactivityWarnDays); notice.After(suspendAt) {
+			suspendAt = notice
+		}
+		details["suspendAt"] = suspendAt.UTC().Format(time.RFC3339)
+	}
+	j.publish(ctx, &domain.MembershipEvent{
+		Type:     domain.EventInactivityWarning,
+		TenantID: ut.TenantID,
+		UserID:   ut.UserID.Hex(),
+		At:       now.UTC(),
+		Details:  details,
+	})
+	return true, nil
+}
+
+// publish publishes event, logging it when it cannot be delivered
+func (j *InactivityJob) publish(ctx context.Context, event *domain.MembershipEvent) {
+	if err := j.events.Publish(ctx, event); err != nil {
+		j.logger.Error("Failed to publish membership event",
+			zap.String("user_id", event.UserID),
+			zap.String("tenant_id", event.TenantID),
+			zap.String("event_type", event.Type),
+			zap.Error(err),
+		)
+	}
+}
+
+// logReport logs what applying a tenant's policy changed
+func (j *InactivityJob) logReport(report *domain.InactivityReport) {
+	j.logger.Info("Inactivity policy applied",
+		zap.String("tenant_id", report.TenantID),
+		zap.Bool("dry_run", report.DryRun),
+		zap.Strings("warned", inactiveUserIDs(report.Warned)),
+		zap.Strings("suspended", inactiveUserIDs(report.Suspended)),
+	)
+}
+
+// warnedSince reports whether the member was warned during their current
+// stretch of inactivity
+func warnedSince(ut *domain.UserTenant, trackedSince time.Time) bool {
+	return ut.InactivityWarnedAt != nil && !ut.InactivityWarnedAt.Before(ut.InactiveSince(trackedSince))
+}
+
+// toInactiveMember converts a membership to its report entry
+func toInactiveMember(ut *domain.UserTenant, trackedSince time.Time) domain.InactiveMember {
+	return domain.InactiveMember{
+		UserID:        ut.UserID.Hex(),
+		InactiveSince: ut.InactiveSince(trackedSince).UTC(),
+		LastSeenAt:    ut.LastSeenAt,
+	}
+}
+
+// inactiveUserIDs returns the user IDs of members
+func inactiveUserIDs(members []domain.InactiveMember) []string {
+	ids := make([]string, len(members))
+	for i, m := range members {
+		ids[i] = m.UserID
+	}
+	return ids
+}
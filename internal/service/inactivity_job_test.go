@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUserService_TouchMembership(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	lan, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "lan@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	lanID := lan.User.ID.Hex()
+	hoa, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "hoa@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	hoaID := hoa.User.ID.Hex()
+
+	ut, err := svc.TouchMembership(memberContext(lanID, "tenant-a"), lanID, "tenant-a")
+	require.NoError(t, err)
+	require.NotNil(t, ut.LastSeenAt)
+	profile, err := svc.GetUser(ctx, lanID, "tenant-a")
+	require.NoError(t, err)
+	require.NotNil(t, profile.UserTenant.LastSeenAt)
+	assert.True(t, profile.UserTenant.LastSeenAt.Equal(*ut.LastSeenAt))
+
+	// Only platform services record activity of other members
+	_, err = svc.TouchMembership(memberContext(lanID, "tenant-a"), hoaID, "tenant-a")
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = svc.TouchMembership(ctx, hoaID, "tenant-a")
+	require.NoError(t, err)
+	_, err = svc.TouchMembership(ctx, hoaID, "tenant-b")
+	assertStatus(t, err, http.StatusNotFound)
+
+	_, err = svc.SuspendUser(ctx, hoaID, "tenant-a", "")
+	require.NoError(t, err)
+	_, err = svc.TouchMembership(ctx, hoaID, "tenant-a")
+	assertStatus(t, err, http.StatusConflict)
+}
+
+func TestInactivityJob_Apply(t *testing.T) {
+	services := newTestServices(t)
+	userRepo, roleRepo, policyRepo, users := services.userRepo, services.roleRepo, services.policyRepo, services.users
+	events := &recordingPublisher{}
+	job := NewInactivityJob(userRepo, policyRepo, events, InactivityConfig{BatchSize: 1}, services.log)
+	policies := NewPolicyService(policyRepo, userRepo, roleRepo, job, services.log)
+	ctx := platformContext()
+	bg := context.Background()
+
+	create := func(email, tenantID string, roles ...string) string {
+		profile, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: email, TenantID: tenantID, Roles: roles})
+		require.NoError(t, err)
+		return profile.User.ID.Hex()
+	}
+	ownerID := create("owner@example.com", "tenant-a", "owner")
+	lanID := create("lan@example.com", "tenant-a")
+	hoaID := create("hoa@example.com", "tenant-a")
+	create("mai@example.com", "tenant-b")
+
+	_, err := policies.ApplyInactivityPolicy(ctx, "tenant-a", true)
+	assertStatus(t, err, http.StatusConflict)
+	_, err = policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{InactivityWarnDays: 60, InactivitySuspendDays: 30})
+	assertStatus(t, err, http.StatusBadRequest)
+	policy, err := policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{InactivityWarnDays: 30, InactivitySuspendDays: 60})
+	require.NoError(t, err)
+	assert.Equal(t, 30, policy.InactivityWarnDays)
+	assert.Equal(t, 60, policy.InactivitySuspendDays)
+
+	// Applying on demand needs policies:manage
+	_, err = policies.ApplyInactivityPolicy(memberContext(lanID, "tenant-a"), "tenant-a", true)
+	assertStatus(t, err, http.StatusForbidden)
+	report, err := policies.ApplyInactivityPolicy(memberContext(ownerID, "tenant-a"), "tenant-a", true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.False(t, report.Changed())
+
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, n) }
+	lanUserID, err := primitive.ObjectIDFromHex(lanID)
+	require.NoError(t, err)
+	ok, err := userRepo.Touch(bg, lanUserID, "tenant-a", days(40))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	apply := func(at time.Time, dryRun bool) *domain.InactivityReport {
+		reports, err := job.ApplyAll(bg, at, dryRun)
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		assert.Equal(t, "tenant-a", reports[0].TenantID)
+		assert.Equal(t, dryRun, reports[0].DryRun)
+		return reports[0]
+	}
+
+	// Members past both thresholds are warned before they are suspended
+	report = apply(days(61), true)
+	assert.ElementsMatch(t, []string{ownerID, hoaID}, inactiveUserIDs(report.Warned))
+	assert.Empty(t, report.Suspended)
+	assert.Empty(t, events.events)
+	report = apply(days(61), false)
+	assert.ElementsMatch(t, []string{ownerID, hoaID}, inactiveUserIDs(report.Warned))
+	require.Len(t, events.events, 2)
+	assert.Equal(t, domain.EventInactivityWarning, events.events[0].Type)
+	assert.NotEmpty(t, events.events[0].Details["suspendAt"])
+
+	// Warnings are not repeated, and suspension waits for the notice period
+	report = apply(days(75), false)
+	assert.Equal(t, []string{lanID}, inactiveUserIDs(report.Warned))
+	assert.Empty(t, report.Suspended)
+
+	report = apply(days(91), true)
+	assert.Empty(t, report.Warned)
+	assert.ElementsMatch(t, []string{ownerID, hoaID}, inactiveUserIDs(report.Suspended))
+	profile, err := users.GetUser(ctx, hoaID, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.MembershipActive, profile.UserTenant.CurrentStatus())
+
+	events.events = nil
+	report = apply(days(91), false)
+	assert.ElementsMatch(t, []string{ownerID, hoaID}, inactiveUserIDs(report.Suspended))
+	require.Len(t, events.events, 2)
+	assert.Equal(t, domain.EventInactivitySuspended, events.events[0].Type)
+
+	profile, err = users.GetUser(ctx, hoaID, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.MembershipSuspended, profile.UserTenant.Status)
+	last := profile.UserTenant.Transitions[len(profile.UserTenant.Transitions)-1]
+	assert.Equal(t, domain.ActorInactivityJob, last.By)
+	assert.Equal(t, "inactive for 60 days", last.Reason)
+
+	profile, err = users.GetUser(ctx, lanID, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.MembershipActive, profile.UserTenant.Status)
+}
+
+func TestInactivityJob_NeverSeenMembersCountFromPolicyEnabled(t *testing.T) {
+	services := newTestServices(t)
+	userRepo, roleRepo, policyRepo, users := services.userRepo, services.roleRepo, services.policyRepo, services.users
+	job := NewInactivityJob(userRepo, policyRepo, &recordingPublisher{}, InactivityConfig{}, services.log)
+	policies := NewPolicyService(policyRepo, userRepo, roleRepo, job, services.log)
+	ctx := platformContext()
+	bg := context.Background()
+
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, n) }
+
+	// A long-standing member who only ever authenticated with JWTs
+	profile, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "lan@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	lanID := profile.User.ID.Hex()
+	for _, step := range []struct {
+		from, to domain.MembershipStatus
+		at       time.Time
+	}{
+		{domain.MembershipActive, domain.MembershipSuspended, days(-401)},
+		{domain.MembershipSuspended, domain.MembershipActive, days(-400)},
+	} {
+		ok, err := userRepo.SetStatus(bg, profile.User.ID, "tenant-a", step.from,
+			domain.MembershipTransition{From: step.from, To: step.to, At: step.at})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	policy, err := policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{InactivityWarnDays: 30, InactivitySuspendDays: 60})
+	require.NoError(t, err)
+	require.NotNil(t, policy.InactivityEnabledAt)
+	enabledAt := *policy.InactivityEnabledAt
+
+	// Changing the thresholds keeps the clock running
+	policy, err = policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{InactivityWarnDays: 30, InactivitySuspendDays: 90})
+	require.NoError(t, err)
+	require.NotNil(t, policy.InactivityEnabledAt)
+	assert.True(t, policy.InactivityEnabledAt.Equal(enabledAt))
+
+	reports, err := job.ApplyAll(bg, days(1), false)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.False(t, reports[0].Changed())
+
+	reports, err = job.ApplyAll(bg, days(31), false)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, []string{lanID}, inactiveUserIDs(reports[0].Warned))
+	assert.Empty(t, reports[0].Suspended)
+	assert.True(t, reports[0].Warned[0].InactiveSince.Equal(enabledAt))
+
+	// Turning the thresholds off and on again restarts the clock
+	_, err = policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{})
+	require.NoError(t, err)
+	policy, err = policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{InactivityWarnDays: 30})
+	require.NoError(t, err)
+	require.NotNil(t, policy.InactivityEnabledAt)
+	assert.False(t, policy.InactivityEnabledAt.Before(enabledAt))
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// maxBulkTagUsers is the most memberships a single bulk tagging request may change
+const maxBulkTagUsers = 500
+
+// AddUserTags adds tags to a user's membership in a tenant, keeping the tags
+// it already carries
+func (s *UserService) AddUserTags(ctx context.Context, userID, tenantID string, tags []string) (*domain.UserTenant, error) {
+	tags, err := validation.NormalizeTags(tags)
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	return s.changeUserTags(ctx, userID, tenantID, tags, nil)
+}
+
+// RemoveUserTag removes a tag from a user's membership in a tenant
+func (s *UserService) RemoveUserTag(ctx context.Context, userID, tenantID, tag string) (*domain.UserTenant, error) {
+	tags, err := validation.NormalizeTags([]string{tag})
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	return s.changeUserTags(ctx, userID, tenantID, nil, tags)
+}
+
+// changeUserTags adds and removes tags on one membership and returns it
+func (s *UserService) changeUserTags(ctx context.Context, userID, tenantID string, add, remove []string) (*domain.UserTenant, error) {
+	if err := validation.ValidateObjectID(userID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermTagsManage); err != nil {
+		return nil, err
+	}
+
+	id, _ := primitive.ObjectIDFromHex(userID)
+	if _, err := s.tagMembers(ctx, tenantID, []primitive.ObjectID{id}, add, remove); err != nil {
+		return nil, err
+	}
+
+	_, userTenant, err := s.userRepo.FindByID(ctx, userID, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to get user")
+	}
+	if userTenant == nil {
+		return nil, errors.NotFound("User not found in this tenant")
+	}
+	return userTenant, nil
+}
+
+// BulkTagUsers adds and removes tags on the tenant memberships of several users.
+// Users without a membership in the tenant are skipped
+func (s *UserService) BulkTagUsers(ctx context.Context, tenantID string, req *domain.BulkTagRequest) (*domain.BulkTagResult, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if len(req.UserIDs) == 0 || len(req.UserIDs) > maxBulkTagUsers {
+		return nil, errors.BadRequest("user_ids must list between 1 and 500 users")
+	}
+	ids := make([]primitive.ObjectID, len(req.UserIDs))
+	for i, userID := range req.UserIDs {
+		id, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return nil, errors.BadRequest("Invalid user ID " + userID)
+		}
+		ids[i] = id
+	}
+
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		return nil, errors.BadRequest("add or remove must list at least one tag")
+	}
+	var add, remove []string
+	var err error
+	if len(req.Add) > 0 {
+		if add, err = validation.NormalizeTags(req.Add); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+	}
+	if len(req.Remove) > 0 {
+		if remove, err = validation.NormalizeTags(req.Remove); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+	}
+	for _, tag := range add {
+		if containsString(remove, tag) {
+			return nil, errors.BadRequest("tag " + tag + " is both added and removed")
+		}
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermTagsManage); err != nil {
+		return nil, err
+	}
+
+	matched, err := s.tagMembers(ctx, tenantID, ids, add, remove)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Members tagged",
+		zap.String("tenant_id", tenantID),
+		zap.Int64("matched", matched),
+		zap.Strings("added", add),
+		zap.Strings("removed", remove),
+	)
+
+	return &domain.BulkTagResult{Matched: matched}, nil
+}
+
+// ListTags returns the tenant's tag inventory: every tag in use with the
+// number of memberships carrying it, most used first
+func (s *UserService) ListTags(ctx context.Context, tenantID string) ([]domain.TagCount, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	counts, err := s.userRepo.CountTags(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to count tags", zap.Error(err))
+		return nil, errors.Internal("Failed to list tags")
+	}
+	if counts == nil {
+		counts = []domain.TagCount{}
+	}
+	return counts, nil
+}
+
+// tagMembers applies tag additions then removals and returns how many
+// memberships matched
+func (s *UserService) tagMembers(ctx context.Context, tenantID string, ids []primitive.ObjectID, add, remove []string) (int64, error) {
+	var matched int64
+	var err error
+	if len(add) > 0 {
+		if matched, err = s.userRepo.AddTags(ctx, tenantID, ids, add); err != nil {
+			s.logger.Error("Failed to add tags", zap.Error(err))
+			return 0, errors.Internal("Failed to add tags")
+		}
+	}
+	if len(remove) > 0 {
+		if matched, err = s.userRepo.RemoveTags(ctx, tenantID, ids, remove); err != nil {
+			s.logger.Error("Failed to remove tags", zap.Error(err))
+			return 0, errors.Internal("Failed to remove tags")
+		}
+	}
+	return matched, nil
+}
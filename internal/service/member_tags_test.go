@@ -0,0 +1,81 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+func TestUserService_Tags(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	ids := make(map[string]string)
+	for _, email := range []string{"lan@example.com", "hoa@example.com", "mai@example.com"} {
+		profile, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: email, TenantID: "tenant-a", LastName: "Nguyễn"})
+		require.NoError(t, err)
+		ids[email] = profile.User.ID.Hex()
+	}
+	_, err := svc.AddUserToTenant(ctx, ids["lan@example.com"], "tenant-b", nil, "")
+	require.NoError(t, err)
+
+	membership, err := svc.AddUserTags(ctx, ids["lan@example.com"], "tenant-a", []string{"VIP", "team:backend"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vip", "team:backend"}, membership.Tags)
+
+	_, err = svc.AddUserTags(ctx, ids["lan@example.com"], "tenant-a", []string{"night shift"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	_, err = svc.AddUserTags(ctx, ids["lan@example.com"], "tenant-c", []string{"vip"})
+	assertStatus(t, err, http.StatusNotFound)
+
+	result, err := svc.BulkTagUsers(ctx, "tenant-a", &domain.BulkTagRequest{
+		UserIDs: []string{ids["hoa@example.com"], ids["mai@example.com"], ids["lan@example.com"]},
+		Add:     []string{"remote"},
+		Remove:  []string{"team:backend"},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.Matched)
+
+	_, err = svc.BulkTagUsers(ctx, "tenant-a", &domain.BulkTagRequest{UserIDs: []string{ids["hoa@example.com"]}, Add: []string{"vip"}, Remove: []string{"VIP"}})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	_, err = svc.BulkTagUsers(ctx, "tenant-a", &domain.BulkTagRequest{UserIDs: []string{ids["hoa@example.com"]}})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	membership, err = svc.RemoveUserTag(ctx, ids["mai@example.com"], "tenant-a", "Remote")
+	require.NoError(t, err)
+	assert.Empty(t, membership.Tags)
+
+	tags, err := svc.ListTags(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TagCount{{Tag: "remote", Count: 2}, {Tag: "vip", Count: 1}}, tags)
+
+	tags, err = svc.ListTags(ctx, "tenant-b")
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	// List and search filter by tag within the tenant
+	users, total, err := svc.ListUsers(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", Filter: "tag:vip"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "lan@example.com", users[0].User.Email)
+
+	users, total, err = svc.SearchUsers(ctx, "tenant-a", "nguyen", "tag:remote", 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.Len(t, users, 2)
+
+	_, total, err = svc.ListUsers(ctx, &domain.ListUsersRequest{TenantID: "tenant-b", Filter: "tag:vip"})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	// Tagging requires tags:manage
+	member := memberContext(ids["mai@example.com"], "tenant-a")
+	_, err = svc.AddUserTags(member, ids["mai@example.com"], "tenant-a", []string{"vip"})
+	assertStatus(t, err, http.StatusForbidden)
+}
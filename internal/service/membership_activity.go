@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// TouchMembership records that a member was seen in the tenant now, for
+// activity the service does not observe itself. Members can touch their own
+// membership; touching anyone else's needs a platform identity
+func (s *UserService) TouchMembership(ctx context.Context, userID, tenantID string) (*domain.UserTenant, error) {
+	if err := validation.ValidateObjectID(userID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	identity := auth.IdentityFromContext(ctx)
+	if identity == nil {
+		return nil, errors.Unauthorized("Authentication required")
+	}
+	if !identity.IsPlatform() && (identity.UserID != userID || !identity.HasTenant(tenantID)) {
+		return nil, errors.Forbidden("Only platform services can record activity of other members")
+	}
+
+	_, userTenant, err := s.userRepo.FindByID(ctx, userID, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get membership", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to record activity")
+	}
+	if userTenant == nil {
+		return nil, errors.NotFound("User not found")
+	}
+
+	now := time.Now().UTC()
+	ok, err := s.userRepo.Touch(ctx, userTenant.UserID, tenantID, now)
+	if err != nil {
+		s.logger.Error("Failed to record activity", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to record activity")
+	}
+	if !ok {
+		return nil, errors.Conflict("Only active memberships record activity")
+	}
+	if userTenant.LastSeenAt == nil || now.After(*userTenant.LastSeenAt) {
+		userTenant.LastSeenAt = &now
+	}
+
+	return userTenant, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// ListExpiringUsers lists the tenant's members whose membership expires within
+// the next days, soonest first. It needs users:update:any, which lets the
+// caller extend or clear the expiries
+func (s *UserService) ListExpiringUsers(ctx context.Context, tenantID string, days, page, pageSize int) ([]*domain.UserProfile, int64, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, 0, errors.BadRequest(err.Error())
+	}
+	if err := validation.ValidateExpiringDays(days); err != nil {
+		return nil, 0, errors.BadRequest(err.Error())
+	}
+	page, pageSize, _ = validation.ValidatePagination(page, pageSize)
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermUsersUpdateAny); err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	until := now.AddDate(0, 0, days)
+	results, total, err := s.userRepo.List(ctx, repository.MemberQuery{
+		TenantID: tenantID,
+		Filter:   domain.UserFilter{ExpiresAt: domain.TimeRange{Gt: &now, Lte: &until}},
+		Sort:     domain.UserSort{Field: domain.UserSortExpiresAt},
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		s.logger.Error("Failed to list expiring users", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, 0, errors.Internal("Failed to list expiring users")
+	}
+	return toProfiles(results), total, nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// recordingPublisher keeps the events it is given
+type recordingPublisher struct {
+	events []*domain.MembershipEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *domain.MembershipEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestUserService_MembershipExpiry(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+	in := func(d time.Duration) string { return time.Now().Add(d).UTC().Format(time.RFC3339) }
+
+	create := func(email, expiresAt string, roles ...string) string {
+		profile, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: email, TenantID: "tenant-a", Roles: roles, ExpiresAt: expiresAt})
+		require.NoError(t, err)
+		return profile.User.ID.Hex()
+	}
+	ownerID := create("owner@example.com", "", "owner")
+	managerID := create("manager@example.com", "", "manager")
+	lanID := create("lan@example.com", in(10*24*time.Hour))
+	hoaID := create("hoa@example.com", in(3*24*time.Hour))
+	create("mai@example.com", in(60*24*time.Hour))
+
+	_, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "x@example.com", TenantID: "tenant-a", ExpiresAt: in(-time.Hour)})
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "x@example.com", TenantID: "tenant-a", ExpiresAt: "next week"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	ut, err := svc.AddUserToTenant(ctx, lanID, "tenant-b", nil, in(24*time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, ut.ExpiresAt)
+
+	users, total, err := svc.ListExpiringUsers(memberContext(ownerID, "tenant-a"), "tenant-a", 30, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, users, 2)
+	assert.Equal(t, "hoa@example.com", users[0].User.Email)
+	assert.Equal(t, "lan@example.com", users[1].User.Email)
+
+	_, _, err = svc.ListExpiringUsers(ctx, "tenant-a", 0, 1, 20)
+	assertStatus(t, err, http.StatusBadRequest)
+	_, _, err = svc.ListExpiringUsers(memberContext(hoaID, "tenant-a"), "tenant-a", 30, 1, 20)
+	assertStatus(t, err, http.StatusForbidden)
+
+	// Extending and clearing expiries is not self-service
+	extended := in(90 * 24 * time.Hour)
+	_, err = svc.UpdateUser(memberContext(hoaID, "tenant-a"), hoaID, "tenant-a", &domain.UpdateUserRequest{ExpiresAt: &extended})
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = svc.UpdateUser(memberContext(managerID, "tenant-a"), ownerID, "tenant-a", &domain.UpdateUserRequest{ExpiresAt: &extended})
+	assertStatus(t, err, http.StatusForbidden)
+	profile, err := svc.UpdateUser(memberContext(managerID, "tenant-a"), hoaID, "tenant-a", &domain.UpdateUserRequest{ExpiresAt: &extended})
+	require.NoError(t, err)
+	require.NotNil(t, profile.UserTenant.ExpiresAt)
+	assert.Equal(t, extended, profile.UserTenant.ExpiresAt.Format(time.RFC3339))
+
+	never := ""
+	profile, err = svc.UpdateUser(ctx, lanID, "tenant-a", &domain.UpdateUserRequest{ExpiresAt: &never})
+	require.NoError(t, err)
+	assert.Nil(t, profile.UserTenant.ExpiresAt)
+
+	users, _, err = svc.ListExpiringUsers(ctx, "tenant-a", 30, 1, 20)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestExpiryScheduler_ExpireOnce(t *testing.T) {
+	services := newTestServices(t)
+	userRepo, users := services.userRepo, services.users
+	events := &recordingPublisher{}
+	scheduler := NewExpiryScheduler(userRepo, events, ExpiryConfig{BatchSize: 1}, services.log)
+	ctx := platformContext()
+
+	create := func(email string, expiresIn time.Duration) string {
+		req := &domain.CreateUserRequest{Email: email, TenantID: "tenant-a"}
+		if expiresIn > 0 {
+			req.ExpiresAt = time.Now().Add(expiresIn).UTC().Format(time.RFC3339)
+		}
+		profile, err := users.CreateUser(ctx, req)
+		require.NoError(t, err)
+		return profile.User.ID.Hex()
+	}
+	lanID := create("lan@example.com", time.Hour)
+	hoaID := create("hoa@example.com", 2*time.Hour)
+	maiID := create("mai@example.com", 0)
+	anID := create("an@example.com", 48*time.Hour)
+	_, err := users.SuspendUser(ctx, hoaID, "tenant-a", "")
+	require.NoError(t, err)
+
+	expired, err := scheduler.ExpireOnce(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+
+	now := time.Now().Add(3 * time.Hour)
+	expired, err = scheduler.ExpireOnce(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, expired)
+
+	for _, id := range []string{lanID, hoaID} {
+		profile, err := users.GetUser(ctx, id, "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MembershipDeactivated, profile.UserTenant.Status)
+		last := profile.UserTenant.Transitions[len(profile.UserTenant.Transitions)-1]
+		assert.Equal(t, domain.ActorExpiryScheduler, last.By)
+		assert.Equal(t, "membership expired", last.Reason)
+	}
+	for _, id := range []string{maiID, anID} {
+		profile, err := users.GetUser(ctx, id, "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MembershipActive, profile.UserTenant.Status)
+	}
+
+	require.Len(t, events.events, 2)
+	assert.Equal(t, domain.EventMembershipExpired, events.events[0].Type)
+	assert.Equal(t, lanID, events.events[0].UserID)
+	assert.Equal(t, "active", events.events[0].Details["previousStatus"])
+	assert.Equal(t, "suspended", events.events[1].Details["previousStatus"])
+
+	// Deactivated memberships are not expired again
+	expired, err = scheduler.ExpireOnce(context.Background(), now)
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+}
+
+func TestUserService_ExpiredMembership(t *testing.T) {
+	services := newTestServices(t)
+	userRepo, svc := services.userRepo, services.users
+	ctx := platformContext()
+
+	profile, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "lan@example.com", TenantID: "tenant-a", Roles: []string{"manager"}})
+	require.NoError(t, err)
+	lanID := profile.User.ID.Hex()
+	other, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "hoa@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+
+	// A membership past its expiry loses access before the scheduler runs
+	past := time.Now().Add(-time.Minute)
+	profile.UserTenant.ExpiresAt = &past
+	require.NoError(t, userRepo.Update(context.Background(), nil, profile.UserTenant))
+	_, err = svc.UpdateUser(memberContext(lanID, "tenant-a"), other.User.ID.Hex(), "tenant-a", &domain.UpdateUserRequest{FirstName: "Hoa"})
+	assertStatus(t, err, http.StatusForbidden)
+
+	// Reactivating needs a new expiry first
+	_, err = svc.ChangeMembershipStatus(ctx, lanID, "tenant-a", domain.MembershipDeactivated, "")
+	require.NoError(t, err)
+	_, err = svc.ReactivateUser(ctx, lanID, "tenant-a", "")
+	assertStatus(t, err, http.StatusConflict)
+
+	never := ""
+	_, err = svc.UpdateUser(ctx, lanID, "tenant-a", &domain.UpdateUserRequest{ExpiresAt: &never})
+	require.NoError(t, err)
+	_, err = svc.ReactivateUser(ctx, lanID, "tenant-a", "")
+	require.NoError(t, err)
+}
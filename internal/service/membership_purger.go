@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Defaults of PurgerConfig
+const (
+	DefaultPurgeInterval  = time.Hour
+	DefaultPurgeBatchSize = 100
+)
+
+// PurgerConfig configures the MembershipPurger
+type PurgerConfig struct {
+	Interval  time.Duration // How often removed memberships are checked
+	BatchSize int           // Memberships read per query
+}
+
+// PurgerConfigFromEnv builds a PurgerConfig from MEMBERSHIP_PURGE_INTERVAL
+// (a Go duration, default 1h)
+func PurgerConfigFromEnv() PurgerConfig {
+	cfg := PurgerConfig{Interval: DefaultPurgeInterval, BatchSize: DefaultPurgeBatchSize}
+	if v := os.Getenv("MEMBERSHIP_PURGE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Interval = d
+		}
+	}
+	return cfg
+}
+
+// MembershipPurger hard deletes memberships that stayed removed for longer
+// than their tenant's retention window, together with the member's preferences
+// in that tenant, and then global users left without memberships. Every purge
+// is recorded in the audit log
+type MembershipPurger struct {
+	userRepo   repository.UserStore
+	prefRepo   repository.PreferencesStore
+	policyRepo repository.PolicyStore
+	auditRepo  repository.AuditStore
+	cfg        PurgerConfig
+	logger     *logger.Logger
+}
+
+// NewMembershipPurger creates a new membership purger
+func NewMembershipPurger(userRepo repository.UserStore, prefRepo repository.PreferencesStore, policyRepo repository.PolicyStore, auditRepo repository.AuditStore, cfg PurgerConfig, log *logger.Logger) *MembershipPurger {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultPurgeInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultPurgeBatchSize
+	}
+	return &MembershipPurger{
+		userRepo:   userRepo,
+		prefRepo:   prefRepo,
+		policyRepo: policyRepo,
+		auditRepo:  auditRepo,
+		cfg:        cfg,
+		logger:     log,
+	}
+}
+
+// Run purges once right away and then every Interval until ctx is done
+func (p *MembershipPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.PurgeOnce(ctx, time.Now())
+		if err != nil {
+			p.logger.Error("Failed to purge removed memberships", zap.Int("purged", purged), zap.Error(err))
+		} else if purged > 0 {
+			p.logger.Info("Purged removed memberships", zap.Int("purged", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce purges every membership whose retention window ended before now
+// and returns how many were purged
+func (p *MembershipPurger) PurgeOnce(ctx context.Context, now time.Time) (int, error) {
+	policies, err := p.policyRepo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Tenants with their own window are purged one by one, the rest together
+	purged := 0
+	var custom []string
+	for _, policy := range policies {
+		if policy.RetentionDays == 0 {
+			continue
+		}
+		custom = append(custom, policy.TenantID)
+		n, err := p.purge(ctx, repository.RemovedQuery{TenantID: policy.TenantID}, policy.RetentionDays, now)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	n, err := p.purge(ctx, repository.RemovedQuery{ExcludeTenantIDs: custom}, domain.DefaultRetentionDays, now)
+	return purged + n, err
+}
+
+// purge purges the memberships matching q removed more than retentionDays before now
+func (p *MembershipPurger) purge(ctx context.Context, q repository.RemovedQuery, retentionDays int, now time.Time) (int, error) {
+	q.Before = now.AddDate(0, 0, -retentionDays)
+	q.Limit = p.cfg.BatchSize
+
+	purged := 0
+	for {
+		due, err := p.userRepo.FindRemoved(ctx, q)
+		if err != nil {
+			return purged, err
+		}
+
+		batch := 0
+		for _, ut := range due {
+			ok, err := p.purgeMembership(ctx, ut, q.Before, retentionDays)
+			if err != nil {
+				return purged, err
+			}
+			if ok {
+				batch++
+			}
+		}
+		purged += batch
+
+		// Memberships restored since they were read are skipped, so an
+		// unproductive batch means the rest are not due either
+		if len(due) < q.Limit || batch == 0 {
+			return purged, nil
+		}
+	}
+}
+
+// purgeMembership purges one membership, reporting false when it was restored
+// or purged in the meantime. The member's preferences in the tenant are
+// deleted and the purge is recorded in the audit log as part of it, so a purge
+// whose record cannot be written is undone and retried on the next run
+func (p *MembershipPurger) purgeMembership(ctx context.Context, ut *domain.UserTenant, removedBefore time.Time, retentionDays int) (bool, error) {
+	userID := ut.UserID.Hex()
+	result, err := p.userRepo.Purge(ctx, ut.UserID, ut.TenantID, removedBefore, func(tx *repository.Tx, result *repository.PurgeResult) error {
+		if err := p.prefRepo.Delete(tx.Context(), userID, ut.TenantID); err != nil {
+			return fmt.Errorf("failed to delete preferences: %w", err)
+		}
+		entries := []*domain.AuditEntry{{
+			TenantID: ut.TenantID,
+			UserID:   userID,
+			Action:   domain.AuditMembershipPurged,
+			ActorID:  domain.AuditActorPurger,
+			Details: map[string]string{
+				"removedAt":     result.Membership.StatusChangedAt.UTC().Format(time.RFC3339),
+				"retentionDays": strconv.Itoa(retentionDays),
+			},
+		}}
+		if result.UserPurged {
+			entries = append(entries, &domain.AuditEntry{
+				UserID:  userID,
+				Action:  domain.AuditUserPurged,
+				ActorID: domain.AuditActorPurger,
+				Details: map[string]string{"lastTenantId": ut.TenantID},
+			})
+		}
+		for _, entry := range entries {
+			if err := p.auditRepo.Record(tx.Context(), entry); err != nil {
+				return fmt.Errorf("failed to record %s: %w", entry.Action, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to purge membership of %s in %s: %w", userID, ut.TenantID, err)
+	}
+	if result.Membership == nil {
+		return false, nil
+	}
+
+	p.logger.Info("Membership purged",
+		zap.String("user_id", userID),
+		zap.String("tenant_id", ut.TenantID),
+		zap.Bool("user_purged", result.UserPurged),
+	)
+	return true, nil
+}
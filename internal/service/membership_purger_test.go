@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+)
+
+func TestPolicyService_MembershipPolicy(t *testing.T) {
+	services := newTestServices(t)
+	users := services.users
+	policies := NewPolicyService(services.policyRepo, services.userRepo, services.roleRepo, nil, services.log)
+	ctx := platformContext()
+
+	policy, err := policies.GetMembershipPolicy(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultRetentionDays, policy.RetentionDays)
+
+	policy, err = policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{RetentionDays: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 7, policy.RetentionDays)
+	policy, err = policies.GetMembershipPolicy(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, 7, policy.RetentionDays)
+
+	_, err = policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{RetentionDays: -1})
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = policies.SetMembershipPolicy(ctx, "tenant-a", &domain.MembershipPolicyRequest{RetentionDays: domain.MaxRetentionDays + 1})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// Changing the policy requires policies:manage
+	profile, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "manager@example.com", TenantID: "tenant-a", Roles: []string{"manager"}})
+	require.NoError(t, err)
+	_, err = policies.SetMembershipPolicy(memberContext(profile.User.ID.Hex(), "tenant-a"), "tenant-a", &domain.MembershipPolicyRequest{})
+	assertStatus(t, err, http.StatusForbidden)
+}
+
+func TestMembershipPurger_PurgeOnce(t *testing.T) {
+	services := newTestServices(t)
+	userRepo, prefRepo, policyRepo, auditRepo, users := services.userRepo, services.prefRepo, services.policyRepo, services.auditRepo, services.users
+	purger := NewMembershipPurger(userRepo, prefRepo, policyRepo, auditRepo, PurgerConfig{BatchSize: 1}, services.log)
+	ctx := platformContext()
+
+	create := func(email, tenantID string) string {
+		profile, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: email, TenantID: tenantID})
+		require.NoError(t, err)
+		return profile.User.ID.Hex()
+	}
+	lanID := create("lan@example.com", "tenant-a")
+	_, err := users.AddUserToTenant(ctx, lanID, "tenant-b", nil, "")
+	require.NoError(t, err)
+	hoaID := create("hoa@example.com", "tenant-b")
+	maiID := create("mai@example.com", "tenant-a")
+	require.NoError(t, prefRepo.Save(context.Background(), &domain.UserPreferences{UserID: lanID, TenantID: "tenant-a", Theme: "dark"}))
+	require.NoError(t, policyRepo.Save(context.Background(), &domain.MembershipPolicy{TenantID: "tenant-b", RetentionDays: 7}))
+
+	for _, m := range []struct{ userID, tenantID string }{{lanID, "tenant-a"}, {lanID, "tenant-b"}, {hoaID, "tenant-b"}} {
+		require.NoError(t, users.DeleteUser(ctx, m.userID, m.tenantID))
+	}
+
+	// Nothing is due while the retention windows are open
+	purged, err := purger.PurgeOnce(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	// tenant-b keeps removed members for 7 days, tenant-a for the default 30
+	purged, err = purger.PurgeOnce(context.Background(), time.Now().AddDate(0, 0, 8))
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	_, err = users.GetUser(ctx, hoaID, "tenant-b")
+	assertStatus(t, err, http.StatusNotFound)
+	user, err := userRepo.FindUserByID(context.Background(), hoaID)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+
+	// A restored member is not purged
+	require.NoError(t, users.DeleteUser(ctx, maiID, "tenant-a"))
+	_, err = users.RestoreUser(ctx, maiID, "tenant-a", "")
+	require.NoError(t, err)
+
+	purged, err = purger.PurgeOnce(context.Background(), time.Now().AddDate(0, 0, 31))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	user, err = userRepo.FindUserByID(context.Background(), lanID)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+	prefs, err := prefRepo.Find(context.Background(), lanID, "tenant-a")
+	require.NoError(t, err)
+	assert.Nil(t, prefs)
+	_, err = users.GetUser(ctx, maiID, "tenant-a")
+	require.NoError(t, err)
+
+	entries, err := auditRepo.List(context.Background(), repository.AuditQuery{UserID: lanID})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, domain.AuditUserPurged, entries[0].Action)
+	assert.Equal(t, domain.AuditMembershipPurged, entries[1].Action)
+	assert.Equal(t, "tenant-a", entries[1].TenantID)
+	assert.Equal(t, "30", entries[1].Details["retentionDays"])
+	assert.Equal(t, "7", entries[2].Details["retentionDays"])
+	assert.Equal(t, domain.AuditActorPurger, entries[2].ActorID)
+}
+
+// failingAuditStore fails to record entries while err is set
+type failingAuditStore struct {
+	repository.AuditStore
+	err error
+}
+
+func (s *failingAuditStore) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.AuditStore.Record(ctx, entry)
+}
+
+func TestMembershipPurger_UnrecordedPurgeIsUndone(t *testing.T) {
+	auditRepo := &failingAuditStore{AuditStore: repository.NewInMemoryAuditRepository(), err: errors.New("audit log unavailable")}
+	services := newTestServices(t, withAuditStore(auditRepo))
+	userRepo, users := services.userRepo, services.users
+	purger := NewMembershipPurger(userRepo, services.prefRepo, services.policyRepo, auditRepo, PurgerConfig{}, services.log)
+	ctx := platformContext()
+
+	profile, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "lan@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	lanID := profile.User.ID.Hex()
+	require.NoError(t, users.DeleteUser(ctx, lanID, "tenant-a"))
+
+	// The purge is undone and retried while it cannot be recorded
+	purged, err := purger.PurgeOnce(context.Background(), time.Now().AddDate(0, 0, 31))
+	assert.ErrorIs(t, err, auditRepo.err)
+	assert.Zero(t, purged)
+	user, err := userRepo.FindUserByID(context.Background(), lanID)
+	require.NoError(t, err)
+	assert.NotNil(t, user)
+	_, ut, err := userRepo.FindByID(context.Background(), lanID, "tenant-a")
+	require.NoError(t, err)
+	require.NotNil(t, ut)
+	assert.Equal(t, domain.MembershipRemoved, ut.Status)
+
+	auditRepo.err = nil
+	purged, err = purger.PurgeOnce(context.Background(), time.Now().AddDate(0, 0, 31))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	entries, err := auditRepo.List(context.Background(), repository.AuditQuery{UserID: lanID})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
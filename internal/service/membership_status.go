@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// ChangeMembershipStatus moves a member's tenant membership to another status,
+// recording who made the change, when and why. Only the moves allowed by
+// domain.MembershipStatus.CanTransitionTo are accepted. Moving a membership to
+// or from removed needs users:delete; every other move needs users:update:any
+func (s *UserService) ChangeMembershipStatus(ctx context.Context, userID, tenantID string, to domain.MembershipStatus, reason string) (*domain.UserTenant, error) {
+	return s.changeMembershipStatus(ctx, userID, tenantID, to, reason, nil)
+}
+
+// SuspendUser suspends an active member, blocking their access to the tenant
+func (s *UserService) SuspendUser(ctx context.Context, userID, tenantID, reason string) (*domain.UserTenant, error) {
+	return s.changeMembershipStatus(ctx, userID, tenantID, domain.MembershipSuspended, reason,
+		[]domain.MembershipStatus{domain.MembershipActive})
+}
+
+// ReactivateUser makes a suspended or deactivated member active again
+func (s *UserService) ReactivateUser(ctx context.Context, userID, tenantID, reason string) (*domain.UserTenant, error) {
+	return s.changeMembershipStatus(ctx, userID, tenantID, domain.MembershipActive, reason,
+		[]domain.MembershipStatus{domain.MembershipSuspended, domain.MembershipDeactivated})
+}
+
+// RestoreUser brings a removed member back as active, keeping their roles and tags
+func (s *UserService) RestoreUser(ctx context.Context, userID, tenantID, reason string) (*domain.UserTenant, error) {
+	return s.changeMembershipStatus(ctx, userID, tenantID, domain.MembershipActive, reason,
+		[]domain.MembershipStatus{domain.MembershipRemoved})
+}
+
+// changeMembershipStatus moves a membership to status to. When from is set the
+// membership must currently be in one of those statuses
+func (s *UserService) changeMembershipStatus(ctx context.Context, userID, tenantID string, to domain.MembershipStatus, reason string, from []domain.MembershipStatus) (*domain.UserTenant, error) {
+	if err := validation.ValidateObjectID(userID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if !to.Valid() {
+		return nil, errors.BadRequest(fmt.Sprintf("unknown status %q", to))
+	}
+	if err := validation.ValidateStatusReason(reason); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	reason = validation.SanitizeString(reason)
+
+	caller, err := s.authz.authorize(ctx, tenantID, auth.PermUsersUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+	if caller.identity.UserID == userID {
+		return nil, errors.Forbidden("Cannot change the status of your own membership")
+	}
+
+	_, userTenant, err := s.userRepo.FindByID(ctx, userID, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get membership", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to change membership status")
+	}
+	if userTenant == nil {
+		return nil, errors.NotFound("User not found")
+	}
+
+	current := userTenant.CurrentStatus()
+	if (current == domain.MembershipRemoved || to == domain.MembershipRemoved) &&
+		!caller.holdsAll([]auth.Permission{auth.PermUsersDelete}) {
+		return nil, errors.Forbidden(fmt.Sprintf("Missing permission %s", auth.PermUsersDelete))
+	}
+	// Members cannot lock out anyone holding permissions they lack themselves
+	if !caller.canGrant(userTenant.Roles) {
+		return nil, errors.Forbidden("Cannot change the status of a member with more permissions than your own")
+	}
+	if current == to {
+		return nil, errors.Conflict(fmt.Sprintf("Membership is already %s", to))
+	}
+	if (from != nil && !containsStatus(from, current)) || !current.CanTransitionTo(to) {
+		return nil, errors.Conflict(fmt.Sprintf("Cannot move a %s membership to %s", current, to))
+	}
+	// The expiry scheduler would deactivate it again straight away
+	if to == domain.MembershipActive && userTenant.Expired(time.Now()) {
+		return nil, errors.Conflict("Membership has expired, extend or clear expires_at first")
+	}
+
+	transition := domain.MembershipTransition{
+		From:   current,
+		To:     to,
+		Reason: reason,
+		By:     caller.identity.UserID,
+		At:     time.Now().UTC(),
+	}
+	ok, err := s.userRepo.SetStatus(ctx, userTenant.UserID, tenantID, current, transition)
+	if err != nil {
+		s.logger.Error("Failed to change membership status", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to change membership status")
+	}
+	if !ok {
+		// Someone else changed the status since it was read
+		return nil, errors.Conflict("Membership status changed concurrently, retry")
+	}
+	userTenant.ApplyTransition(transition)
+
+	s.logger.Info("Membership status changed",
+		zap.String("user_id", userID),
+		zap.String("tenant_id", tenantID),
+		zap.String("from", string(current)),
+		zap.String("to", string(to)),
+	)
+
+	return userTenant, nil
+}
+
+// containsStatus reports whether statuses contains status
+func containsStatus(statuses []domain.MembershipStatus, status domain.MembershipStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
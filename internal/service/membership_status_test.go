@@ -0,0 +1,92 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+func TestUserService_MembershipStatus(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	create := func(email string, status domain.MembershipStatus, roles ...string) string {
+		profile, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: email, TenantID: "tenant-a", Status: status, Roles: roles})
+		require.NoError(t, err)
+		return profile.User.ID.Hex()
+	}
+	managerID := create("manager@example.com", "", "manager")
+	ownerID := create("owner@example.com", "", "owner")
+	userID := create("lan@example.com", "")
+	invitedID := create("hoa@example.com", domain.MembershipInvited)
+
+	_, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "x@example.com", TenantID: "tenant-a", Status: domain.MembershipSuspended})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	ut, err := svc.SuspendUser(ctx, userID, "tenant-a", "  Chargeback\n under review ")
+	require.NoError(t, err)
+	assert.Equal(t, domain.MembershipSuspended, ut.Status)
+	assert.False(t, ut.IsActive)
+	require.Len(t, ut.Transitions, 2)
+	last := ut.Transitions[1]
+	assert.Equal(t, domain.MembershipActive, last.From)
+	assert.Equal(t, "test-service", last.By)
+	assert.Equal(t, "Chargeback under review", last.Reason)
+
+	// Suspended members lose access to the tenant
+	_, err = svc.UpdateUser(memberContext(userID, "tenant-a"), userID, "tenant-a", &domain.UpdateUserRequest{FirstName: "Lan"})
+	assertStatus(t, err, http.StatusForbidden)
+
+	_, err = svc.SuspendUser(ctx, userID, "tenant-a", "")
+	assertStatus(t, err, http.StatusConflict)
+	_, err = svc.RestoreUser(ctx, userID, "tenant-a", "")
+	assertStatus(t, err, http.StatusConflict)
+	_, err = svc.ReactivateUser(ctx, invitedID, "tenant-a", "")
+	assertStatus(t, err, http.StatusConflict)
+	_, err = svc.ChangeMembershipStatus(ctx, invitedID, "tenant-a", domain.MembershipSuspended, "")
+	assertStatus(t, err, http.StatusConflict)
+	_, err = svc.ChangeMembershipStatus(ctx, invitedID, "tenant-a", "banned", "")
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = svc.SuspendUser(ctx, managerID, "tenant-a", string(make([]byte, 501)))
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = svc.SuspendUser(ctx, "507f1f77bcf86cd799439011", "tenant-a", "")
+	assertStatus(t, err, http.StatusNotFound)
+
+	// Listings show suspended members unless filtered out, and never removed ones
+	users, total, err := svc.ListUsers(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", Filter: "status:suspended"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Equal(t, "lan@example.com", users[0].User.Email)
+
+	ut, err = svc.ReactivateUser(ctx, userID, "tenant-a", "")
+	require.NoError(t, err)
+	assert.True(t, ut.IsActive)
+
+	require.NoError(t, svc.DeleteUser(ctx, userID, "tenant-a"))
+	require.NoError(t, svc.DeleteUser(ctx, userID, "tenant-a"))
+	_, total, err = svc.ListUsers(ctx, &domain.ListUsersRequest{TenantID: "tenant-a"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	_, err = svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "lan@example.com", TenantID: "tenant-a"})
+	assertStatus(t, err, http.StatusConflict)
+
+	// Managers may suspend members but cannot remove or restore them
+	managerCtx := memberContext(managerID, "tenant-a")
+	_, err = svc.RestoreUser(managerCtx, userID, "tenant-a", "")
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = svc.SuspendUser(managerCtx, ownerID, "tenant-a", "")
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = svc.SuspendUser(managerCtx, managerID, "tenant-a", "")
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = svc.ChangeMembershipStatus(managerCtx, invitedID, "tenant-a", domain.MembershipActive, "accepted by phone")
+	require.NoError(t, err)
+
+	ut, err = svc.RestoreUser(memberContext(ownerID, "tenant-a"), userID, "tenant-a", "removed by mistake")
+	require.NoError(t, err)
+	assert.Equal(t, domain.MembershipActive, ut.Status)
+	assert.Equal(t, []string{"user"}, ut.Roles)
+	assert.Equal(t, ownerID, ut.Transitions[len(ut.Transitions)-1].By)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// PolicyService manages each tenant's membership policy
+type PolicyService struct {
+	policyRepo repository.PolicyStore
+	inactivity *InactivityJob
+	authz      *authorizer
+	logger     *logger.Logger
+}
+
+// NewPolicyService creates a new policy service. inactivity applies inactivity
+// policies on demand
+func NewPolicyService(policyRepo repository.PolicyStore, userRepo repository.UserStore, roleRepo repository.RoleStore, inactivity *InactivityJob, log *logger.Logger) *PolicyService {
+	return &PolicyService{
+		policyRepo: policyRepo,
+		inactivity: inactivity,
+		authz:      &authorizer{userRepo: userRepo, roleRepo: roleRepo, logger: log},
+		logger:     log,
+	}
+}
+
+// GetMembershipPolicy returns the tenant's policy with the service defaults
+// filled in for anything the tenant has not set
+func (s *PolicyService) GetMembershipPolicy(ctx context.Context, tenantID string) (*domain.MembershipPolicy, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	policy, err := s.policyRepo.Find(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get membership policy", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to get membership policy")
+	}
+	if policy == nil {
+		policy = &domain.MembershipPolicy{TenantID: tenantID}
+	}
+	return withPolicyDefaults(policy), nil
+}
+
+// SetMembershipPolicy replaces the tenant's policy
+func (s *PolicyService) SetMembershipPolicy(ctx context.Context, tenantID string, req *domain.MembershipPolicyRequest) (*domain.MembershipPolicy, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if err := validation.ValidateRetentionDays(req.RetentionDays); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if err := validation.ValidateInactivityDays(req.InactivityWarnDays, req.InactivitySuspendDays); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermPoliciesManage); err != nil {
+		return nil, err
+	}
+
+	current, err := s.policyRepo.Find(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get membership policy", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to save membership policy")
+	}
+
+	policy := &domain.MembershipPolicy{
+		TenantID:              tenantID,
+		RetentionDays:         req.RetentionDays,
+		InactivityWarnDays:    req.InactivityWarnDays,
+		InactivitySuspendDays: req.InactivitySuspendDays,
+	}
+	// Members never seen count from when the thresholds were turned on, so
+	// remember that across later changes to them
+	if policy.EnforcesInactivity() {
+		if current != nil && current.EnforcesInactivity() {
+			trackedSince := current.InactivityTrackedSince()
+			policy.InactivityEnabledAt = &trackedSince
+		} else {
+			enabledAt := time.Now().UTC()
+			policy.InactivityEnabledAt = &enabledAt
+		}
+	}
+	if err := s.policyRepo.Save(ctx, policy); err != nil {
+		s.logger.Error("Failed to save membership policy", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to save membership policy")
+	}
+
+	s.logger.Info("Membership policy updated",
+		zap.String("tenant_id", tenantID),
+		zap.Int("retention_days", req.RetentionDays),
+		zap.Int("inactivity_warn_days", req.InactivityWarnDays),
+		zap.Int("inactivity_suspend_days", req.InactivitySuspendDays),
+	)
+
+	return withPolicyDefaults(policy), nil
+}
+
+// ApplyInactivityPolicy applies the tenant's inactivity thresholds right away
+// instead of waiting for the InactivityJob, and reports the members warned and
+// suspended. A dry run only reports what would change
+func (s *PolicyService) ApplyInactivityPolicy(ctx context.Context, tenantID string, dryRun bool) (*domain.InactivityReport, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	caller, err := s.authz.authorize(ctx, tenantID, auth.PermPoliciesManage)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.policyRepo.Find(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get membership policy", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to apply inactivity policy")
+	}
+	if policy == nil || !policy.EnforcesInactivity() {
+		return nil, errors.Conflict("The tenant has no inactivity thresholds")
+	}
+
+	report, err := s.inactivity.Apply(ctx, policy, time.Now(), dryRun, caller.identity.UserID)
+	if err != nil {
+		s.logger.Error("Failed to apply inactivity policy", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to apply inactivity policy")
+	}
+	if !dryRun {
+		s.inactivity.logReport(report)
+	}
+	return report, nil
+}
+
+// withPolicyDefaults fills the unset fields of policy with the service defaults
+func withPolicyDefaults(policy *domain.MembershipPolicy) *domain.MembershipPolicy {
+	if policy.RetentionDays == 0 {
+		policy.RetentionDays = domain.DefaultRetentionDays
+	}
+	return policy
+}
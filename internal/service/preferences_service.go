@@ -0,0 +1,531 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// Platform preference defaults, applied when neither the tenant nor the user has chosen a value
+const (
+	DefaultLanguage = "en"
+	DefaultTimezone = "UTC"
+	DefaultTheme    = validation.ThemeSystem
+)
+
+// PreferencesService manages users' per-tenant preferences and each tenant's defaults
+// A preference resolves to the user's value, then the tenant default, then the
+// platform default; values the tenant has locked always come from the tenant
+type PreferencesService struct {
+	prefRepo   repository.PreferencesStore
+	schemaRepo repository.SettingsSchemaStore
+	userRepo   repository.UserStore
+	authz      *authorizer
+	logger     *logger.Logger
+}
+
+// NewPreferencesService creates a new preferences service
+func NewPreferencesService(prefRepo repository.PreferencesStore, schemaRepo repository.SettingsSchemaStore, userRepo repository.UserStore, roleRepo repository.RoleStore, log *logger.Logger) *PreferencesService {
+	return &PreferencesService{
+		prefRepo:   prefRepo,
+		schemaRepo: schemaRepo,
+		userRepo:   userRepo,
+		authz:      &authorizer{userRepo: userRepo, roleRepo: roleRepo, logger: log},
+		logger:     log,
+	}
+}
+
+// GetPreferences retrieves a user's effective preferences in a tenant
+// An empty userID means the caller
+func (s *PreferencesService) GetPreferences(ctx context.Context, userID, tenantID string) (*domain.UserPreferences, error) {
+	userID, err := s.access(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	effective, err := s.effective(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return effective.Preferences, nil
+}
+
+// GetEffectivePreferences retrieves a user's resolved preferences in a tenant
+// together with the source of every value and the tenant's locks
+func (s *PreferencesService) GetEffectivePreferences(ctx context.Context, userID, tenantID string) (*domain.EffectivePreferences, error) {
+	userID, err := s.access(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.effective(ctx, userID, tenantID)
+}
+
+// ReplacePreferences replaces a user's preferences in a tenant
+// Fields left out of the request are reset to their defaults
+func (s *PreferencesService) ReplacePreferences(ctx context.Context, userID, tenantID string, req *domain.PreferencesRequest) (*domain.UserPreferences, error) {
+	userID, err := s.access(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.loadTenantDefaults(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLocks(tenant.Locked, req); err != nil {
+		return nil, err
+	}
+
+	prefs := &domain.UserPreferences{UserID: userID, TenantID: tenantID}
+	if err := applyPreferenceFields(prefs, req); err != nil {
+		return nil, err
+	}
+
+	if req.Settings != nil {
+		if err := validation.ValidateSettings(req.Settings); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		prefs.Settings = pruneNulls(req.Settings)
+		if err := s.validateUserSettings(ctx, prefs, tenant, req.Settings); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.save(ctx, prefs, tenant)
+}
+
+// PatchPreferences partially updates a user's preferences in a tenant
+// Settings are merged as a JSON merge patch (RFC 7396)
+func (s *PreferencesService) PatchPreferences(ctx context.Context, userID, tenantID string, req *domain.PreferencesRequest) (*domain.UserPreferences, error) {
+	userID, err := s.access(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.loadTenantDefaults(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLocks(tenant.Locked, req); err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.load(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		prefs = &domain.UserPreferences{UserID: userID, TenantID: tenantID}
+	}
+
+	if err := applyPreferenceFields(prefs, req); err != nil {
+		return nil, err
+	}
+
+	if req.Settings != nil {
+		if err := validation.ValidateSettings(req.Settings); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		prefs.Settings = mergePatch(prefs.Settings, req.Settings)
+		if err := validation.ValidateSettings(prefs.Settings); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		if err := s.validateUserSettings(ctx, prefs, tenant, req.Settings); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.save(ctx, prefs, tenant)
+}
+
+// GetTenantDefaults retrieves a tenant's preference defaults and locks
+// Any member who may manage their own preferences can read them
+func (s *PreferencesService) GetTenantDefaults(ctx context.Context, tenantID string) (*domain.TenantPreferences, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermUsersUpdateSelf, auth.PermUsersUpdateAny, auth.PermPreferencesManage); err != nil {
+		return nil, err
+	}
+
+	return s.loadTenantDefaults(ctx, tenantID)
+}
+
+// ReplaceTenantDefaults replaces a tenant's preference defaults and locks
+func (s *PreferencesService) ReplaceTenantDefaults(ctx context.Context, tenantID string, req *domain.TenantPreferencesRequest) (*domain.TenantPreferences, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	prefs := &domain.TenantPreferences{TenantID: tenantID, Timezone: req.Timezone, Theme: req.Theme}
+
+	if req.Language != "" {
+		tag, err := validation.ValidateLanguage(req.Language)
+		if err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		prefs.Language = tag
+	}
+
+	if req.Timezone != "" {
+		if err := validation.ValidateTimezone(req.Timezone); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+	}
+
+	if req.Theme != "" {
+		if err := validation.ValidateTheme(req.Theme); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+	}
+
+	if req.Settings != nil {
+		if err := validation.ValidateSettings(req.Settings); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		prefs.Settings = pruneNulls(req.Settings)
+	}
+
+	seen := make(map[string]bool, len(req.Locked))
+	for _, path := range req.Locked {
+		if err := validation.ValidatePreferencePath(path); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		if !seen[path] {
+			seen[path] = true
+			prefs.Locked = append(prefs.Locked, path)
+		}
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermPreferencesManage); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateNamespaces(ctx, tenantID, prefs.Settings, req.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := s.prefRepo.SaveTenantDefaults(ctx, prefs); err != nil {
+		s.logger.Error("Failed to save tenant preferences", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to save tenant preferences")
+	}
+
+	s.logger.Info("Tenant preferences updated",
+		zap.String("tenant_id", tenantID),
+		zap.Strings("locked", prefs.Locked),
+	)
+
+	return prefs, nil
+}
+
+// access resolves the target user, checks the caller may manage their
+// preferences and that the user is a member of the tenant
+func (s *PreferencesService) access(ctx context.Context, userID, tenantID string) (string, error) {
+	if userID == "" {
+		id, err := callerID(ctx)
+		if err != nil {
+			return "", err
+		}
+		userID = id
+	}
+
+	if err := validation.ValidateObjectID(userID); err != nil {
+		return "", errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return "", errors.BadRequest(err.Error())
+	}
+
+	// Preferences are part of the profile: members manage their own, and
+	// managing anyone else's needs users:update:any
+	perms := []auth.Permission{auth.PermUsersUpdateAny}
+	if identity := auth.IdentityFromContext(ctx); identity != nil && identity.UserID == userID {
+		perms = append(perms, auth.PermUsersUpdateSelf)
+	}
+	if _, err := s.authz.authorize(ctx, tenantID, perms...); err != nil {
+		return "", err
+	}
+
+	user, userTenant, err := s.userRepo.FindByID(ctx, userID, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.String("user_id", userID), zap.Error(err))
+		return "", errors.Internal("Failed to get preferences")
+	}
+	if user == nil || userTenant == nil {
+		return "", errors.NotFound("User not found in this tenant")
+	}
+
+	return userID, nil
+}
+
+func (s *PreferencesService) load(ctx context.Context, userID, tenantID string) (*domain.UserPreferences, error) {
+	prefs, err := s.prefRepo.Find(ctx, userID, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get preferences", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to get preferences")
+	}
+	if prefs != nil {
+		prefs.Settings = plainSettings(prefs.Settings)
+	}
+	return prefs, nil
+}
+
+// loadTenantDefaults returns the tenant's defaults, or empty defaults if none were saved
+func (s *PreferencesService) loadTenantDefaults(ctx context.Context, tenantID string) (*domain.TenantPreferences, error) {
+	prefs, err := s.prefRepo.FindTenantDefaults(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get tenant preferences", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to get preferences")
+	}
+	if prefs == nil {
+		return &domain.TenantPreferences{TenantID: tenantID}, nil
+	}
+	prefs.Settings = plainSettings(prefs.Settings)
+	return prefs, nil
+}
+
+func (s *PreferencesService) effective(ctx context.Context, userID, tenantID string) (*domain.EffectivePreferences, error) {
+	stored, err := s.load(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		stored = &domain.UserPreferences{UserID: userID, TenantID: tenantID}
+	}
+
+	tenant, err := s.loadTenantDefaults(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return resolvePreferences(stored, tenant), nil
+}
+
+func (s *PreferencesService) save(ctx context.Context, prefs *domain.UserPreferences, tenant *domain.TenantPreferences) (*domain.UserPreferences, error) {
+	if err := s.prefRepo.Save(ctx, prefs); err != nil {
+		s.logger.Error("Failed to save preferences", zap.String("user_id", prefs.UserID), zap.Error(err))
+		return nil, errors.Internal("Failed to save preferences")
+	}
+
+	s.logger.Info("Preferences updated",
+		zap.String("user_id", prefs.UserID),
+		zap.String("tenant_id", prefs.TenantID),
+	)
+
+	return resolvePreferences(prefs, tenant).Preferences, nil
+}
+
+// applyPreferenceFields validates and applies the language, timezone and theme
+// of a request; nil fields are left as they are and empty strings reset a field
+func applyPreferenceFields(prefs *domain.UserPreferences, req *domain.PreferencesRequest) error {
+	if req.Language != nil {
+		prefs.Language = ""
+		if *req.Language != "" {
+			tag, err := validation.ValidateLanguage(*req.Language)
+			if err != nil {
+				return errors.BadRequest(err.Error())
+			}
+			prefs.Language = tag
+		}
+	}
+
+	if req.Timezone != nil {
+		prefs.Timezone = ""
+		if *req.Timezone != "" {
+			if err := validation.ValidateTimezone(*req.Timezone); err != nil {
+				return errors.BadRequest(err.Error())
+			}
+			prefs.Timezone = *req.Timezone
+		}
+	}
+
+	if req.Theme != nil {
+		prefs.Theme = ""
+		if *req.Theme != "" {
+			if err := validation.ValidateTheme(*req.Theme); err != nil {
+				return errors.BadRequest(err.Error())
+			}
+			prefs.Theme = *req.Theme
+		}
+	}
+
+	return nil
+}
+
+// checkLocks rejects a write that sets a preference the tenant has locked
+// Clearing a locked value is allowed, since locked values always resolve to
+// the tenant's default anyway
+func checkLocks(locked []string, req *domain.PreferencesRequest) error {
+	if len(locked) == 0 {
+		return nil
+	}
+	fields := []struct {
+		path  string
+		value *string
+	}{
+		{"language", req.Language},
+		{"timezone", req.Timezone},
+		{"theme", req.Theme},
+	}
+	for _, f := range fields {
+		if f.value != nil && *f.value != "" && isLocked(locked, f.path) {
+			return lockedError(f.path)
+		}
+	}
+	return checkSettingLocks(locked, req.Settings, "settings")
+}
+
+func checkSettingLocks(locked []string, settings map[string]interface{}, prefix string) error {
+	for key, value := range settings {
+		path := prefix + "." + key
+		if value == nil {
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if err := checkSettingLocks(locked, nested, path); err != nil {
+				return err
+			}
+			continue
+		}
+		// A scalar replaces everything below it, including locked children
+		for _, l := range locked {
+			if l == path || strings.HasPrefix(path, l+".") || strings.HasPrefix(l, path+".") {
+				return lockedError(l)
+			}
+		}
+	}
+	return nil
+}
+
+func lockedError(path string) error {
+	return errors.Forbidden(fmt.Sprintf("Preference %q is locked by the tenant", path))
+}
+
+// isLocked reports whether path, or any path containing it, is locked
+func isLocked(locked []string, path string) bool {
+	for _, l := range locked {
+		if l == path || strings.HasPrefix(path, l+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePreferences layers the user's values over the tenant defaults over
+// the platform defaults, recording where each value came from
+func resolvePreferences(stored *domain.UserPreferences, tenant *domain.TenantPreferences) *domain.EffectivePreferences {
+	prefs := *stored
+	sources := make(map[string]string)
+
+	pick := func(path, user, tenantDefault, platform string) string {
+		if user != "" && !isLocked(tenant.Locked, path) {
+			sources[path] = domain.PreferenceSourceUser
+			return user
+		}
+		if tenantDefault != "" {
+			sources[path] = domain.PreferenceSourceTenant
+			return tenantDefault
+		}
+		sources[path] = domain.PreferenceSourcePlatform
+		return platform
+	}
+	prefs.Language = pick("language", stored.Language, tenant.Language, DefaultLanguage)
+	prefs.Timezone = pick("timezone", stored.Timezone, tenant.Timezone, DefaultTimezone)
+	prefs.Theme = pick("theme", stored.Theme, tenant.Theme, DefaultTheme)
+
+	prefs.Settings = map[string]interface{}{}
+	overlaySettings(prefs.Settings, tenant.Settings, "settings", domain.PreferenceSourceTenant, sources, nil)
+	overlaySettings(prefs.Settings, stored.Settings, "settings", domain.PreferenceSourceUser, sources, tenant.Locked)
+
+	locked := tenant.Locked
+	if locked == nil {
+		locked = []string{}
+	}
+	return &domain.EffectivePreferences{Preferences: &prefs, Sources: sources, Locked: locked}
+}
+
+// overlaySettings deep-merges src into dst, skipping locked paths and
+// recording source for every leaf value it writes
+func overlaySettings(dst, src map[string]interface{}, prefix, source string, sources map[string]string, locked []string) {
+	for key, value := range src {
+		path := prefix + "." + key
+		if isLocked(locked, path) {
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			existing, ok := dst[key].(map[string]interface{})
+			if !ok {
+				clearSources(sources, path)
+				existing = make(map[string]interface{}, len(nested))
+				dst[key] = existing
+			}
+			overlaySettings(existing, nested, path, source, sources, locked)
+			continue
+		}
+		clearSources(sources, path)
+		dst[key] = value
+		sources[path] = source
+	}
+}
+
+// clearSources forgets the sources of path and everything below it
+func clearSources(sources map[string]string, path string) {
+	for p := range sources {
+		if p == path || strings.HasPrefix(p, path+".") {
+			delete(sources, p)
+		}
+	}
+}
+
+// mergePatch applies a JSON merge patch to target and returns the result
+// Null values remove keys and nested objects are merged recursively
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = make(map[string]interface{}, len(patch))
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			existing, _ := target[key].(map[string]interface{})
+			target[key] = mergePatch(existing, nested)
+			continue
+		}
+		target[key] = value
+	}
+	return target
+}
+
+// pruneNulls drops null-valued keys so a replaced settings document never stores them
+func pruneNulls(settings map[string]interface{}) map[string]interface{} {
+	return mergePatch(nil, settings)
+}
+
+// plainSettings converts settings decoded from storage (which may contain
+// driver-specific map and array types) to plain JSON values
+func plainSettings(settings map[string]interface{}) map[string]interface{} {
+	if settings == nil {
+		return nil
+	}
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return settings
+	}
+	var plain map[string]interface{}
+	if err := json.Unmarshal(raw, &plain); err != nil {
+		return settings
+	}
+	return plain
+}
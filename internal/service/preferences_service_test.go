@@ -0,0 +1,293 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestPreferencesService(t *testing.T) {
+	services := newTestServices(t)
+	users, prefs := services.users, services.preferences()
+	admin := platformContext()
+
+	profile, err := users.CreateUser(admin, &domain.CreateUserRequest{Email: "lan@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	userID := profile.User.ID.Hex()
+	other, err := users.CreateUser(admin, &domain.CreateUserRequest{Email: "other@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	ctx := memberContext(userID, "tenant-a")
+
+	// Nothing stored yet: defaults
+	got, err := prefs.GetPreferences(ctx, "", "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLanguage, got.Language)
+	assert.Equal(t, DefaultTimezone, got.Timezone)
+	assert.Equal(t, DefaultTheme, got.Theme)
+	assert.Empty(t, got.Settings)
+
+	got, err = prefs.ReplacePreferences(ctx, "", "tenant-a", &domain.PreferencesRequest{
+		Language: strPtr("vi-vn"),
+		Timezone: strPtr("Asia/Ho_Chi_Minh"),
+		Settings: map[string]interface{}{
+			"notifications": map[string]interface{}{"email": true, "sms": false},
+			"dashboard":     map[string]interface{}{"layout": "grid"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "vi-VN", got.Language)
+	assert.Equal(t, "Asia/Ho_Chi_Minh", got.Timezone)
+	assert.Equal(t, DefaultTheme, got.Theme)
+
+	// PATCH leaves unset fields alone and merges settings
+	got, err = prefs.PatchPreferences(ctx, userID, "tenant-a", &domain.PreferencesRequest{
+		Theme: strPtr("dark"),
+		Settings: map[string]interface{}{
+			"notifications": map[string]interface{}{"sms": true},
+			"dashboard":     nil,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "vi-VN", got.Language)
+	assert.Equal(t, "dark", got.Theme)
+	assert.Equal(t, map[string]interface{}{
+		"notifications": map[string]interface{}{"email": true, "sms": true},
+	}, got.Settings)
+
+	// An empty string resets a field to its default
+	got, err = prefs.PatchPreferences(ctx, "", "tenant-a", &domain.PreferencesRequest{Timezone: strPtr("")})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimezone, got.Timezone)
+	assert.Equal(t, "dark", got.Theme)
+
+	// PUT resets everything not in the request
+	got, err = prefs.ReplacePreferences(ctx, "", "tenant-a", &domain.PreferencesRequest{Theme: strPtr("light")})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLanguage, got.Language)
+	assert.Equal(t, "light", got.Theme)
+	assert.Empty(t, got.Settings)
+
+	// Preferences are per tenant
+	_, err = users.AddUserToTenant(admin, userID, "tenant-b", nil, "")
+	require.NoError(t, err)
+	got, err = prefs.GetPreferences(platformContext(), userID, "tenant-b")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTheme, got.Theme)
+
+	tests := []struct {
+		name   string
+		req    *domain.PreferencesRequest
+		status int
+	}{
+		{"invalid language", &domain.PreferencesRequest{Language: strPtr("not a language")}, http.StatusBadRequest},
+		{"invalid timezone", &domain.PreferencesRequest{Timezone: strPtr("Mars/Olympus")}, http.StatusBadRequest},
+		{"invalid theme", &domain.PreferencesRequest{Theme: strPtr("neon")}, http.StatusBadRequest},
+		{"invalid settings key", &domain.PreferencesRequest{Settings: map[string]interface{}{"$where": 1}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := prefs.PatchPreferences(ctx, "", "tenant-a", tt.req)
+			assertStatus(t, err, tt.status)
+		})
+	}
+
+	// Plain members cannot manage someone else's preferences
+	_, err = prefs.GetPreferences(ctx, other.User.ID.Hex(), "tenant-a")
+	assertStatus(t, err, http.StatusForbidden)
+
+	_, err = prefs.GetPreferences(admin, userID, "tenant-c")
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestPreferencesService_TenantDefaults(t *testing.T) {
+	services := newTestServices(t)
+	users, prefs := services.users, services.preferences()
+	admin := platformContext()
+
+	owner, err := users.CreateUser(admin, &domain.CreateUserRequest{Email: "owner@example.com", TenantID: "tenant-a", Roles: []string{"owner"}})
+	require.NoError(t, err)
+	member, err := users.CreateUser(admin, &domain.CreateUserRequest{Email: "member@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	ownerCtx := memberContext(owner.User.ID.Hex(), "tenant-a")
+	memberCtx := memberContext(member.User.ID.Hex(), "tenant-a")
+
+	// The member chose a language and some settings before the tenant set defaults
+	_, err = prefs.ReplacePreferences(memberCtx, "", "tenant-a", &domain.PreferencesRequest{
+		Language: strPtr("en-GB"),
+		Settings: map[string]interface{}{
+			"notifications": map[string]interface{}{"email": false},
+			"dashboard":     map[string]interface{}{"layout": "list"},
+		},
+	})
+	require.NoError(t, err)
+
+	// Managing defaults needs preferences:manage
+	req := &domain.TenantPreferencesRequest{
+		Language: "vi",
+		Timezone: "Asia/Ho_Chi_Minh",
+		Settings: map[string]interface{}{
+			"notifications": map[string]interface{}{"email": true, "sms": true},
+			"dashboard":     map[string]interface{}{"layout": "grid", "density": "compact"},
+		},
+		Locked: []string{"timezone", "settings.notifications", "timezone"},
+	}
+	_, err = prefs.ReplaceTenantDefaults(memberCtx, "tenant-a", req)
+	assertStatus(t, err, http.StatusForbidden)
+	defaults, err := prefs.ReplaceTenantDefaults(ownerCtx, "tenant-a", req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"timezone", "settings.notifications"}, defaults.Locked)
+
+	defaults, err = prefs.GetTenantDefaults(memberCtx, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, "vi", defaults.Language)
+
+	// Effective values resolve user > tenant > platform, and locks beat the user
+	effective, err := prefs.GetEffectivePreferences(memberCtx, "", "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, "en-GB", effective.Preferences.Language)
+	assert.Equal(t, "Asia/Ho_Chi_Minh", effective.Preferences.Timezone)
+	assert.Equal(t, DefaultTheme, effective.Preferences.Theme)
+	assert.Equal(t, map[string]interface{}{
+		"notifications": map[string]interface{}{"email": true, "sms": true},
+		"dashboard":     map[string]interface{}{"layout": "list", "density": "compact"},
+	}, effective.Preferences.Settings)
+	assert.Equal(t, map[string]string{
+		"language":                     domain.PreferenceSourceUser,
+		"timezone":                     domain.PreferenceSourceTenant,
+		"theme":                        domain.PreferenceSourcePlatform,
+		"settings.notifications.email": domain.PreferenceSourceTenant,
+		"settings.notifications.sms":   domain.PreferenceSourceTenant,
+		"settings.dashboard.layout":    domain.PreferenceSourceUser,
+		"settings.dashboard.density":   domain.PreferenceSourceTenant,
+	}, effective.Sources)
+	assert.Equal(t, []string{"timezone", "settings.notifications"}, effective.Locked)
+
+	// Writes to locked preferences are rejected; clearing them is allowed
+	tests := []struct {
+		name   string
+		req    *domain.PreferencesRequest
+		status int
+	}{
+		{"locked field", &domain.PreferencesRequest{Timezone: strPtr("Europe/Paris")}, http.StatusForbidden},
+		{"locked settings child", &domain.PreferencesRequest{Settings: map[string]interface{}{
+			"notifications": map[string]interface{}{"sms": false},
+		}}, http.StatusForbidden},
+		{"scalar over locked settings", &domain.PreferencesRequest{Settings: map[string]interface{}{"notifications": "off"}}, http.StatusForbidden},
+		{"clear locked field", &domain.PreferencesRequest{Timezone: strPtr("")}, http.StatusOK},
+		{"clear locked settings", &domain.PreferencesRequest{Settings: map[string]interface{}{"notifications": nil}}, http.StatusOK},
+		{"unlocked field", &domain.PreferencesRequest{Theme: strPtr("dark")}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := prefs.PatchPreferences(memberCtx, "", "tenant-a", tt.req)
+			if tt.status == http.StatusOK {
+				require.NoError(t, err)
+				return
+			}
+			assertStatus(t, err, tt.status)
+		})
+	}
+
+	_, err = prefs.ReplaceTenantDefaults(ownerCtx, "tenant-a", &domain.TenantPreferencesRequest{Locked: []string{"settings.$bad"}})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestPreferencesService_SettingsSchemas(t *testing.T) {
+	services := newTestServices(t)
+	users, prefs := services.users, services.preferences()
+	admin := platformContext()
+
+	owner, err := users.CreateUser(admin, &domain.CreateUserRequest{Email: "owner@example.com", TenantID: "tenant-a", Roles: []string{"owner"}})
+	require.NoError(t, err)
+	member, err := users.CreateUser(admin, &domain.CreateUserRequest{Email: "member@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	ownerCtx := memberContext(owner.User.ID.Hex(), "tenant-a")
+	memberCtx := memberContext(member.User.ID.Hex(), "tenant-a")
+
+	notifications := map[string]interface{}{
+		"type":                 "object",
+		"required":             []interface{}{"email"},
+		"properties":           map[string]interface{}{"email": map[string]interface{}{"type": "boolean"}},
+		"additionalProperties": false,
+	}
+
+	// Platform schemas are reserved for platform callers
+	_, err = prefs.PutSettingsSchema(ownerCtx, "tenant-a", domain.SettingsSchemaLevelPlatform, "notifications", notifications)
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = prefs.PutSettingsSchema(admin, "tenant-a", domain.SettingsSchemaLevelPlatform, "notifications", notifications)
+	require.NoError(t, err)
+
+	_, err = prefs.PutSettingsSchema(ownerCtx, "tenant-a", "", "dashboard", map[string]interface{}{"type": "object", "anyOf": []interface{}{}})
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = prefs.PutSettingsSchema(memberCtx, "tenant-a", "", "dashboard", map[string]interface{}{"type": "object"})
+	assertStatus(t, err, http.StatusForbidden)
+	_, err = prefs.PutSettingsSchema(ownerCtx, "tenant-a", "", "dashboard", map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"layout": map[string]interface{}{"enum": []interface{}{"grid", "list"}}},
+	})
+	require.NoError(t, err)
+
+	schemas, err := prefs.ListSettingsSchemas(memberCtx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, schemas, 2)
+	assert.Equal(t, "", schemas[0].TenantID)
+	assert.Equal(t, "tenant-a", schemas[1].TenantID)
+
+	// Invalid writes are rejected with every violation
+	_, err = prefs.PatchPreferences(memberCtx, "", "tenant-a", &domain.PreferencesRequest{Settings: map[string]interface{}{
+		"notifications": map[string]interface{}{"email": "yes", "push": true},
+		"dashboard":     map[string]interface{}{"layout": "table"},
+		"free-form":     map[string]interface{}{"anything": 1},
+	}})
+	assertStatus(t, err, http.StatusBadRequest)
+	var settingsErr *SettingsValidationError
+	require.ErrorAs(t, err, &settingsErr)
+	paths := make([]string, len(settingsErr.Violations))
+	for i, v := range settingsErr.Violations {
+		paths[i] = v.Path
+	}
+	assert.Equal(t, []string{
+		"/settings/dashboard/layout",
+		"/settings/notifications/email",
+		"/settings/notifications/push",
+	}, paths)
+
+	// Tenant defaults can supply required values
+	_, err = prefs.ReplaceTenantDefaults(ownerCtx, "tenant-a", &domain.TenantPreferencesRequest{
+		Settings: map[string]interface{}{"notifications": map[string]interface{}{"email": 1}},
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = prefs.ReplaceTenantDefaults(ownerCtx, "tenant-a", &domain.TenantPreferencesRequest{
+		Settings: map[string]interface{}{"notifications": map[string]interface{}{"email": true}},
+	})
+	require.NoError(t, err)
+	_, err = prefs.PatchPreferences(memberCtx, "", "tenant-a", &domain.PreferencesRequest{Settings: map[string]interface{}{
+		"notifications": map[string]interface{}{},
+		"dashboard":     map[string]interface{}{"layout": "list"},
+	}})
+	require.NoError(t, err)
+
+	// A tenant schema replaces the platform schema for that tenant
+	_, err = prefs.PutSettingsSchema(ownerCtx, "tenant-a", domain.SettingsSchemaLevelTenant, "notifications", map[string]interface{}{"type": "object"})
+	require.NoError(t, err)
+	schema, err := prefs.GetSettingsSchema(memberCtx, "tenant-a", "notifications")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", schema.TenantID)
+	_, err = prefs.PatchPreferences(memberCtx, "", "tenant-a", &domain.PreferencesRequest{Settings: map[string]interface{}{
+		"notifications": map[string]interface{}{"push": true},
+	}})
+	require.NoError(t, err)
+
+	require.NoError(t, prefs.DeleteSettingsSchema(ownerCtx, "tenant-a", "", "notifications"))
+	assertStatus(t, prefs.DeleteSettingsSchema(ownerCtx, "tenant-a", "", "notifications"), http.StatusNotFound)
+	schema, err = prefs.GetSettingsSchema(memberCtx, "tenant-a", "notifications")
+	require.NoError(t, err)
+	assert.Equal(t, "", schema.TenantID)
+}
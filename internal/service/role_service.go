@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// builtinDisplayNames are the display names of the built-in roles
+var builtinDisplayNames = map[string]string{
+	auth.RoleOwner:   "Owner",
+	auth.RoleAdmin:   "Admin",
+	auth.RoleManager: "Manager",
+	auth.RoleUser:    "User",
+}
+
+// RoleService manages each tenant's custom role catalog
+type RoleService struct {
+	roleRepo repository.RoleStore
+	userRepo repository.UserStore
+	authz    *authorizer
+	logger   *logger.Logger
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(roleRepo repository.RoleStore, userRepo repository.UserStore, log *logger.Logger) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		authz:    &authorizer{userRepo: userRepo, roleRepo: roleRepo, logger: log},
+		logger:   log,
+	}
+}
+
+// ListRoles lists the built-in roles followed by the tenant's custom roles
+func (s *RoleService) ListRoles(ctx context.Context, tenantID string) ([]*domain.Role, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	custom, err := s.roleRepo.List(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to list roles", zap.Error(err))
+		return nil, errors.Internal("Failed to list roles")
+	}
+
+	roles := make([]*domain.Role, 0, len(auth.BuiltinRoles)+len(custom))
+	for _, key := range []string{auth.RoleOwner, auth.RoleAdmin, auth.RoleManager, auth.RoleUser} {
+		roles = append(roles, builtinRole(tenantID, key))
+	}
+	return append(roles, custom...), nil
+}
+
+// GetRole retrieves a built-in or custom role by key
+func (s *RoleService) GetRole(ctx context.Context, tenantID, key string) (*domain.Role, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if auth.IsBuiltinRole(key) {
+		return builtinRole(tenantID, key), nil
+	}
+
+	role, err := s.roleRepo.FindByKey(ctx, tenantID, key)
+	if err != nil {
+		s.logger.Error("Failed to get role", zap.String("key", key), zap.Error(err))
+		return nil, errors.Internal("Failed to get role")
+	}
+	if role == nil {
+		return nil, errors.NotFound("Role not found")
+	}
+	return role, nil
+}
+
+// CreateRole adds a custom role to the tenant's catalog
+func (s *RoleService) CreateRole(ctx context.Context, tenantID string, req *domain.CreateRoleRequest) (*domain.Role, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateRoleKey(req.Key); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if auth.IsBuiltinRole(req.Key) {
+		return nil, errors.Conflict(fmt.Sprintf("%q is a built-in role", req.Key))
+	}
+
+	if err := validation.ValidateRoleDisplayName(req.DisplayName); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateRoleDescription(req.Description); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	perms, err := parsePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, err := s.authz.authorize(ctx, tenantID, auth.PermRolesManage)
+	if err != nil {
+		return nil, err
+	}
+	if !caller.holdsAll(perms) {
+		return nil, errors.Forbidden("Cannot define a role with more permissions than your own")
+	}
+
+	role := &domain.Role{
+		TenantID:    tenantID,
+		Key:         req.Key,
+		DisplayName: validation.SanitizeName(req.DisplayName),
+		Description: validation.SanitizeString(req.Description),
+		Permissions: permissionStrings(perms),
+	}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		if stderrors.Is(err, repository.ErrRoleExists) {
+			return nil, errors.Conflict("Role already exists in this tenant")
+		}
+		s.logger.Error("Failed to create role", zap.Error(err))
+		return nil, errors.Internal("Failed to create role")
+	}
+
+	s.logger.Info("Role created",
+		zap.String("tenant_id", tenantID),
+		zap.String("key", role.Key),
+	)
+
+	return role, nil
+}
+
+// UpdateRole changes a custom role's display name, description or permissions
+// Members holding the role pick up permission changes immediately
+func (s *RoleService) UpdateRole(ctx context.Context, tenantID, key string, req *domain.UpdateRoleRequest) (*domain.Role, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if auth.IsBuiltinRole(key) {
+		return nil, errors.BadRequest("Built-in roles cannot be modified")
+	}
+
+	caller, err := s.authz.authorize(ctx, tenantID, auth.PermRolesManage)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.FindByKey(ctx, tenantID, key)
+	if err != nil {
+		s.logger.Error("Failed to get role", zap.String("key", key), zap.Error(err))
+		return nil, errors.Internal("Failed to update role")
+	}
+	if role == nil {
+		return nil, errors.NotFound("Role not found")
+	}
+
+	if req.DisplayName != nil {
+		if err := validation.ValidateRoleDisplayName(*req.DisplayName); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		role.DisplayName = validation.SanitizeName(*req.DisplayName)
+	}
+
+	if req.Description != nil {
+		if err := validation.ValidateRoleDescription(*req.Description); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		role.Description = validation.SanitizeString(*req.Description)
+	}
+
+	if req.Permissions != nil {
+		perms, err := parsePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if !caller.holdsAll(perms) {
+			return nil, errors.Forbidden("Cannot define a role with more permissions than your own")
+		}
+		role.Permissions = permissionStrings(perms)
+	}
+
+	if err := s.roleRepo.Update(ctx, role); err != nil {
+		s.logger.Error("Failed to update role", zap.Error(err))
+		return nil, errors.Internal("Failed to update role")
+	}
+
+	return role, nil
+}
+
+// DeleteRole removes a custom role; roles still assigned to members cannot be deleted
+func (s *RoleService) DeleteRole(ctx context.Context, tenantID, key string) error {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return errors.BadRequest(err.Error())
+	}
+
+	if auth.IsBuiltinRole(key) {
+		return errors.BadRequest("Built-in roles cannot be deleted")
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermRolesManage); err != nil {
+		return err
+	}
+
+	role, err := s.roleRepo.FindByKey(ctx, tenantID, key)
+	if err != nil {
+		s.logger.Error("Failed to get role", zap.String("key", key), zap.Error(err))
+		return errors.Internal("Failed to delete role")
+	}
+	if role == nil {
+		return errors.NotFound("Role not found")
+	}
+
+	assigned, err := s.userRepo.CountMembersWithRole(ctx, tenantID, key)
+	if err != nil {
+		s.logger.Error("Failed to count role assignments", zap.String("key", key), zap.Error(err))
+		return errors.Internal("Failed to delete role")
+	}
+	if assigned > 0 {
+		return errors.Conflict(fmt.Sprintf("Role is still assigned to %d member(s)", assigned))
+	}
+
+	if err := s.roleRepo.Delete(ctx, tenantID, key); err != nil {
+		s.logger.Error("Failed to delete role", zap.Error(err))
+		return errors.Internal("Failed to delete role")
+	}
+
+	s.logger.Info("Role deleted",
+		zap.String("tenant_id", tenantID),
+		zap.String("key", key),
+	)
+
+	return nil
+}
+
+// builtinRole describes a built-in role as a catalog entry
+func builtinRole(tenantID, key string) *domain.Role {
+	return &domain.Role{
+		TenantID:    tenantID,
+		Key:         key,
+		DisplayName: builtinDisplayNames[key],
+		Permissions: permissionStrings(auth.BuiltinRoles[key]),
+	}
+}
+
+// parsePermissions validates and de-duplicates a list of permission names
+func parsePermissions(names []string) ([]auth.Permission, error) {
+	seen := make(map[auth.Permission]bool, len(names))
+	perms := make([]auth.Permission, 0, len(names))
+	for _, name := range names {
+		p := auth.Permission(name)
+		if !auth.IsValidPermission(p) {
+			return nil, errors.BadRequest(fmt.Sprintf("unknown permission %q", name))
+		}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	return perms, nil
+}
+
+func permissionStrings(perms []auth.Permission) []string {
+	result := make([]string, len(perms))
+	for i, p := range perms {
+		result[i] = string(p)
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+func TestRoleService_CRUD(t *testing.T) {
+	ctx := platformContext()
+	roles := newTestServices(t).roles()
+
+	role, err := roles.CreateRole(ctx, "tenant-a", &domain.CreateRoleRequest{
+		Key:         "accountant",
+		DisplayName: "  Kế   toán ",
+		Description: "Books and payroll",
+		Permissions: []string{"users:update:self", "users:update:self"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Kế toán", role.DisplayName)
+	assert.Equal(t, []string{"users:update:self"}, role.Permissions)
+
+	_, err = roles.CreateRole(ctx, "tenant-a", &domain.CreateRoleRequest{Key: "accountant", DisplayName: "Again"})
+	assertStatus(t, err, http.StatusConflict)
+
+	_, err = roles.CreateRole(ctx, "tenant-a", &domain.CreateRoleRequest{Key: "admin", DisplayName: "Admin"})
+	assertStatus(t, err, http.StatusConflict)
+
+	_, err = roles.CreateRole(ctx, "tenant-a", &domain.CreateRoleRequest{Key: "bad key", DisplayName: "Bad"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	_, err = roles.CreateRole(ctx, "tenant-a", &domain.CreateRoleRequest{Key: "pilot", DisplayName: "Pilot", Permissions: []string{"planes:fly"}})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	name := "Warehouse Lead"
+	role, err = roles.UpdateRole(ctx, "tenant-a", "accountant", &domain.UpdateRoleRequest{DisplayName: &name, Permissions: []string{"users:create"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Warehouse Lead", role.DisplayName)
+	assert.Equal(t, "Books and payroll", role.Description)
+	assert.Equal(t, []string{"users:create"}, role.Permissions)
+
+	_, err = roles.UpdateRole(ctx, "tenant-a", "owner", &domain.UpdateRoleRequest{DisplayName: &name})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	list, err := roles.ListRoles(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, list, 5)
+	assert.Equal(t, "owner", list[0].Key)
+	assert.Equal(t, "accountant", list[4].Key)
+
+	_, err = roles.GetRole(ctx, "tenant-b", "accountant")
+	assertStatus(t, err, http.StatusNotFound)
+
+	require.NoError(t, roles.DeleteRole(ctx, "tenant-a", "accountant"))
+	assertStatus(t, roles.DeleteRole(ctx, "tenant-a", "accountant"), http.StatusNotFound)
+}
+
+func TestRoleService_Assignments(t *testing.T) {
+	ctx := platformContext()
+	services := newTestServices(t)
+	users, roles := services.users, services.roles()
+
+	_, err := roles.CreateRole(ctx, "tenant-a", &domain.CreateRoleRequest{
+		Key:         "warehouse-lead",
+		DisplayName: "Warehouse Lead",
+		Permissions: []string{"users:create", "users:update:self", "users:update:any"},
+	})
+	require.NoError(t, err)
+
+	// Unknown roles are rejected on create, add and update
+	_, err = users.CreateUser(ctx, &domain.CreateUserRequest{Email: "x@example.com", TenantID: "tenant-a", Roles: []string{"janitor"}})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	lead, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "lead@example.com", TenantID: "tenant-a", Roles: []string{"warehouse-lead"}})
+	require.NoError(t, err)
+	leadID := lead.User.ID.Hex()
+
+	member, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "member@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	memberID := member.User.ID.Hex()
+
+	_, err = users.UpdateUser(ctx, memberID, "tenant-a", &domain.UpdateUserRequest{Roles: []string{"janitor"}})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	_, err = users.AddUserToTenant(ctx, memberID, "tenant-b", []string{"warehouse-lead"}, "")
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// Members cannot change their own roles
+	memberCtx := memberContext(memberID, "tenant-a")
+	_, err = users.UpdateUser(memberCtx, memberID, "tenant-a", &domain.UpdateUserRequest{Roles: []string{"warehouse-lead"}})
+	assertStatus(t, err, http.StatusForbidden)
+
+	// Custom role permissions are enforced
+	leadCtx := memberContext(leadID, "tenant-a")
+	_, err = users.UpdateUser(leadCtx, memberID, "tenant-a", &domain.UpdateUserRequest{FirstName: "Renamed"})
+	assert.NoError(t, err)
+	assertStatus(t, users.DeleteUser(leadCtx, memberID, "tenant-a"), http.StatusForbidden)
+
+	// A lead can hand out their own role but not admin
+	_, err = users.UpdateUser(leadCtx, memberID, "tenant-a", &domain.UpdateUserRequest{Roles: []string{"warehouse-lead"}})
+	assert.NoError(t, err)
+	_, err = users.UpdateUser(leadCtx, memberID, "tenant-a", &domain.UpdateUserRequest{Roles: []string{"admin"}})
+	assertStatus(t, err, http.StatusForbidden)
+
+	check, err := users.CheckPermission(ctx, leadID, "tenant-a", "users:update:any")
+	require.NoError(t, err)
+	assert.True(t, check.Allowed)
+
+	// Assigned roles cannot be deleted; roles:manage is required
+	assertStatus(t, roles.DeleteRole(leadCtx, "tenant-a", "warehouse-lead"), http.StatusForbidden)
+	assertStatus(t, roles.DeleteRole(ctx, "tenant-a", "warehouse-lead"), http.StatusConflict)
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// SegmentService manages each tenant's saved member segments and evaluates them
+// against current memberships
+type SegmentService struct {
+	segmentRepo repository.SegmentStore
+	userRepo    repository.UserStore
+	authz       *authorizer
+	logger      *logger.Logger
+}
+
+// NewSegmentService creates a new segment service
+func NewSegmentService(segmentRepo repository.SegmentStore, userRepo repository.UserStore, roleRepo repository.RoleStore, log *logger.Logger) *SegmentService {
+	return &SegmentService{
+		segmentRepo: segmentRepo,
+		userRepo:    userRepo,
+		authz:       &authorizer{userRepo: userRepo, roleRepo: roleRepo, logger: log},
+		logger:      log,
+	}
+}
+
+// ListSegments lists the tenant's segments ordered by key
+func (s *SegmentService) ListSegments(ctx context.Context, tenantID string) ([]*domain.Segment, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	segments, err := s.segmentRepo.List(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to list segments", zap.Error(err))
+		return nil, errors.Internal("Failed to list segments")
+	}
+	if segments == nil {
+		segments = []*domain.Segment{}
+	}
+	return segments, nil
+}
+
+// GetSegment retrieves a segment by key
+func (s *SegmentService) GetSegment(ctx context.Context, tenantID, key string) (*domain.Segment, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	segment, err := s.segmentRepo.FindByKey(ctx, tenantID, key)
+	if err != nil {
+		s.logger.Error("Failed to get segment", zap.String("key", key), zap.Error(err))
+		return nil, errors.Internal("Failed to get segment")
+	}
+	if segment == nil {
+		return nil, errors.NotFound("Segment not found")
+	}
+	return segment, nil
+}
+
+// CreateSegment saves a named filter for the tenant
+func (s *SegmentService) CreateSegment(ctx context.Context, tenantID string, req *domain.CreateSegmentRequest) (*domain.Segment, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateSegmentKey(req.Key); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateSegmentName(req.Name); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateRoleDescription(req.Description); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := validation.ParseUserFilter(req.Filter); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermSegmentsManage); err != nil {
+		return nil, err
+	}
+
+	segment := &domain.Segment{
+		TenantID:    tenantID,
+		Key:         req.Key,
+		Name:        validation.SanitizeName(req.Name),
+		Description: validation.SanitizeString(req.Description),
+		Filter:      req.Filter,
+	}
+	if err := s.segmentRepo.Create(ctx, segment); err != nil {
+		if stderrors.Is(err, repository.ErrSegmentExists) {
+			return nil, errors.Conflict("Segment already exists in this tenant")
+		}
+		s.logger.Error("Failed to create segment", zap.Error(err))
+		return nil, errors.Internal("Failed to create segment")
+	}
+
+	s.logger.Info("Segment created",
+		zap.String("tenant_id", tenantID),
+		zap.String("key", segment.Key),
+	)
+
+	return segment, nil
+}
+
+// UpdateSegment changes a segment's name, description or filter
+func (s *SegmentService) UpdateSegment(ctx context.Context, tenantID, key string, req *domain.UpdateSegmentRequest) (*domain.Segment, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermSegmentsManage); err != nil {
+		return nil, err
+	}
+
+	segment, err := s.GetSegment(ctx, tenantID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if err := validation.ValidateSegmentName(*req.Name); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		segment.Name = validation.SanitizeName(*req.Name)
+	}
+
+	if req.Description != nil {
+		if err := validation.ValidateRoleDescription(*req.Description); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		segment.Description = validation.SanitizeString(*req.Description)
+	}
+
+	if req.Filter != nil {
+		if _, err := validation.ParseUserFilter(*req.Filter); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		segment.Filter = *req.Filter
+	}
+
+	if err := s.segmentRepo.Update(ctx, segment); err != nil {
+		s.logger.Error("Failed to update segment", zap.Error(err))
+		return nil, errors.Internal("Failed to update segment")
+	}
+
+	return segment, nil
+}
+
+// DeleteSegment removes a segment
+func (s *SegmentService) DeleteSegment(ctx context.Context, tenantID, key string) error {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermSegmentsManage); err != nil {
+		return err
+	}
+
+	if _, err := s.GetSegment(ctx, tenantID, key); err != nil {
+		return err
+	}
+
+	if err := s.segmentRepo.Delete(ctx, tenantID, key); err != nil {
+		s.logger.Error("Failed to delete segment", zap.Error(err))
+		return errors.Internal("Failed to delete segment")
+	}
+
+	s.logger.Info("Segment deleted",
+		zap.String("tenant_id", tenantID),
+		zap.String("key", key),
+	)
+
+	return nil
+}
+
+// EvaluateSegment lists the segment's current members with cursor pagination,
+// newest members first. An empty cursor starts at the first page; the total is
+// only counted when includeTotal is set
+func (s *SegmentService) EvaluateSegment(ctx context.Context, tenantID, key, cursor string, pageSize int, includeTotal bool) (*domain.UserPage, error) {
+	q, err := s.segmentQuery(ctx, tenantID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	q.Sort = domain.DefaultUserSort
+	return pageAfter(q, cursor, pageSize, includeTotal, func(q repository.MemberQuery) ([]*repository.UserWithTenant, int64, error) {
+		results, total, err := s.userRepo.List(ctx, q)
+		if err != nil {
+			s.logger.Error("Failed to evaluate segment", zap.String("key", key), zap.Error(err))
+			return nil, 0, errors.Internal("Failed to evaluate segment")
+		}
+		return results, total, nil
+	})
+}
+
+// CountSegment counts the segment's current members
+func (s *SegmentService) CountSegment(ctx context.Context, tenantID, key string) (int64, error) {
+	q, err := s.segmentQuery(ctx, tenantID, key)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.userRepo.Count(ctx, q)
+	if err != nil {
+		s.logger.Error("Failed to count segment", zap.String("key", key), zap.Error(err))
+		return 0, errors.Internal("Failed to count segment")
+	}
+	return count, nil
+}
+
+// CheckSegmentMembership reports whether a user currently belongs to the segment
+func (s *SegmentService) CheckSegmentMembership(ctx context.Context, tenantID, key, userID string) (*domain.SegmentMembership, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.BadRequest("Invalid user ID")
+	}
+
+	q, err := s.segmentQuery(ctx, tenantID, key)
+	if err != nil {
+		return nil, err
+	}
+	q.UserID = id
+
+	count, err := s.userRepo.Count(ctx, q)
+	if err != nil {
+		s.logger.Error("Failed to check segment membership", zap.String("key", key), zap.Error(err))
+		return nil, errors.Internal("Failed to check segment membership")
+	}
+	return &domain.SegmentMembership{SegmentKey: key, UserID: userID, Member: count > 0}, nil
+}
+
+// segmentQuery loads a segment and compiles its filter into a member query
+func (s *SegmentService) segmentQuery(ctx context.Context, tenantID, key string) (repository.MemberQuery, error) {
+	segment, err := s.GetSegment(ctx, tenantID, key)
+	if err != nil {
+		return repository.MemberQuery{}, err
+	}
+
+	// Filters are validated when saved; one that no longer parses is a server fault
+	filter, err := validation.ParseUserFilter(segment.Filter)
+	if err != nil {
+		s.logger.Error("Stored segment filter is invalid", zap.String("key", key), zap.Error(err))
+		return repository.MemberQuery{}, errors.Internal("Segment filter is invalid")
+	}
+	return repository.MemberQuery{TenantID: tenantID, Filter: filter}, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+func TestSegmentService_CRUD(t *testing.T) {
+	ctx := platformContext()
+	segments := newTestServices(t).segments()
+
+	segment, err := segments.CreateSegment(ctx, "tenant-a", &domain.CreateSegmentRequest{
+		Key:    "corp-admins",
+		Name:   "  Corp   admins ",
+		Filter: "emailDomain:corp.vn,role:admin",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Corp admins", segment.Name)
+
+	_, err = segments.CreateSegment(ctx, "tenant-a", &domain.CreateSegmentRequest{Key: "corp-admins", Name: "Again"})
+	assertStatus(t, err, http.StatusConflict)
+
+	_, err = segments.CreateSegment(ctx, "tenant-a", &domain.CreateSegmentRequest{Key: "bad key", Name: "Bad"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	_, err = segments.CreateSegment(ctx, "tenant-a", &domain.CreateSegmentRequest{Key: "broken", Name: "Broken", Filter: "state:active"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	filter := "isActive:true"
+	segment, err = segments.UpdateSegment(ctx, "tenant-a", "corp-admins", &domain.UpdateSegmentRequest{Filter: &filter})
+	require.NoError(t, err)
+	assert.Equal(t, "Corp admins", segment.Name)
+	assert.Equal(t, "isActive:true", segment.Filter)
+
+	bad := "role:"
+	_, err = segments.UpdateSegment(ctx, "tenant-a", "corp-admins", &domain.UpdateSegmentRequest{Filter: &bad})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	list, err := segments.ListSegments(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	_, err = segments.GetSegment(ctx, "tenant-b", "corp-admins")
+	assertStatus(t, err, http.StatusNotFound)
+
+	require.NoError(t, segments.DeleteSegment(ctx, "tenant-a", "corp-admins"))
+	assertStatus(t, segments.DeleteSegment(ctx, "tenant-a", "corp-admins"), http.StatusNotFound)
+}
+
+func TestSegmentService_Evaluate(t *testing.T) {
+	ctx := platformContext()
+	services := newTestServices(t)
+	users, segments := services.users, services.segments()
+
+	ids := make(map[string]string)
+	for _, email := range []string{"lan@corp.vn", "hoa@example.com", "mai@corp.vn", "tuan@corp.vn"} {
+		profile, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: email, TenantID: "tenant-a"})
+		require.NoError(t, err)
+		ids[email] = profile.User.ID.Hex()
+	}
+	_, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "ngoc@corp.vn", TenantID: "tenant-b"})
+	require.NoError(t, err)
+
+	_, err = segments.CreateSegment(ctx, "tenant-a", &domain.CreateSegmentRequest{Key: "corp", Name: "Corp staff", Filter: "emailDomain:corp.vn"})
+	require.NoError(t, err)
+
+	count, err := segments.CountSegment(ctx, "tenant-a", "corp")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+
+	first, err := segments.EvaluateSegment(ctx, "tenant-a", "corp", "", 2, true)
+	require.NoError(t, err)
+	require.Len(t, first.Users, 2)
+	assert.Equal(t, "tuan@corp.vn", first.Users[0].User.Email)
+	require.NotNil(t, first.Total)
+	assert.EqualValues(t, 3, *first.Total)
+
+	last, err := segments.EvaluateSegment(ctx, "tenant-a", "corp", first.NextCursor, 2, false)
+	require.NoError(t, err)
+	require.Len(t, last.Users, 1)
+	assert.Equal(t, "lan@corp.vn", last.Users[0].User.Email)
+	assert.Empty(t, last.NextCursor)
+
+	membership, err := segments.CheckSegmentMembership(ctx, "tenant-a", "corp", ids["mai@corp.vn"])
+	require.NoError(t, err)
+	assert.True(t, membership.Member)
+
+	membership, err = segments.CheckSegmentMembership(ctx, "tenant-a", "corp", ids["hoa@example.com"])
+	require.NoError(t, err)
+	assert.False(t, membership.Member)
+
+	_, err = segments.CheckSegmentMembership(ctx, "tenant-a", "corp", "not-an-id")
+	assertStatus(t, err, http.StatusBadRequest)
+
+	_, err = segments.CountSegment(ctx, "tenant-a", "missing")
+	assertStatus(t, err, http.StatusNotFound)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// callerID returns the authenticated caller's user ID
+func callerID(ctx context.Context) (string, error) {
+	identity := auth.IdentityFromContext(ctx)
+	if identity == nil {
+		return "", errors.Unauthorized("Authentication required")
+	}
+	if validation.ValidateObjectID(identity.UserID) != nil {
+		// Service tokens do not belong to a user
+		return "", errors.NotFound("User not found")
+	}
+	return identity.UserID, nil
+}
+
+// GetMe retrieves the caller's own profile in a tenant
+func (s *UserService) GetMe(ctx context.Context, tenantID string) (*domain.UserProfile, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, userID, tenantID)
+}
+
+// GetMyTenants lists every tenant membership of the caller
+func (s *UserService) GetMyTenants(ctx context.Context) ([]*domain.UserTenant, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUserTenants(ctx, userID)
+}
+
+// UpdateMe updates the fields of the caller's profile that they own
+// Roles, status and identifiers are not part of UpdateMeRequest and can only
+// be changed by an administrator through UpdateUser
+func (s *UserService) UpdateMe(ctx context.Context, tenantID string, req *domain.UpdateMeRequest) (*domain.UserProfile, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if req.FirstName != "" {
+		if err := validation.ValidateName(req.FirstName, "first_name"); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		req.FirstName = validation.SanitizeName(req.FirstName)
+	}
+
+	if req.LastName != "" {
+		if err := validation.ValidateName(req.LastName, "last_name"); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		req.LastName = validation.SanitizeName(req.LastName)
+	}
+
+	if err := validation.ValidatePhone(req.Phone); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateAvatarURL(req.AvatarURL); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermUsersUpdateSelf, auth.PermUsersUpdateAny); err != nil {
+		return nil, err
+	}
+
+	user, userTenant, err := s.userRepo.FindByID(ctx, userID, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to update profile")
+	}
+	if user == nil || userTenant == nil {
+		return nil, errors.NotFound("User not found in this tenant")
+	}
+
+	var u *domain.User
+	if req.Phone != "" || req.AvatarURL != "" {
+		if req.Phone != "" {
+			user.Phone = req.Phone
+		}
+		if req.AvatarURL != "" {
+			user.AvatarURL = req.AvatarURL
+		}
+		u = user
+	}
+
+	var ut *domain.UserTenant
+	if req.FirstName != "" || req.LastName != "" {
+		if req.FirstName != "" {
+			userTenant.FirstName = req.FirstName
+		}
+		if req.LastName != "" {
+			userTenant.LastName = req.LastName
+		}
+		ut = userTenant
+	}
+
+	if u == nil && ut == nil {
+		return &domain.UserProfile{User: user, UserTenant: userTenant}, nil
+	}
+
+	if err := s.userRepo.Update(ctx, u, ut); err != nil {
+		s.logger.Error("Failed to update profile", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to update profile")
+	}
+
+	return &domain.UserProfile{User: user, UserTenant: userTenant}, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+// SettingsValidationError reports settings values that do not match the schema
+// of their namespace. It unwraps to a 400 AppError
+type SettingsValidationError struct {
+	*errors.AppError
+	Violations []validation.SchemaViolation
+}
+
+// Unwrap returns the underlying AppError
+func (e *SettingsValidationError) Unwrap() error {
+	return e.AppError
+}
+
+// ListSettingsSchemas lists the platform schemas followed by the tenant's own schemas
+func (s *PreferencesService) ListSettingsSchemas(ctx context.Context, tenantID string) ([]*domain.SettingsSchema, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermUsersUpdateSelf, auth.PermUsersUpdateAny, auth.PermPreferencesManage); err != nil {
+		return nil, err
+	}
+
+	platform, err := s.schemaRepo.List(ctx, "")
+	if err != nil {
+		s.logger.Error("Failed to list settings schemas", zap.Error(err))
+		return nil, errors.Internal("Failed to list settings schemas")
+	}
+	tenant, err := s.schemaRepo.List(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to list settings schemas", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil, errors.Internal("Failed to list settings schemas")
+	}
+	return append(platform, tenant...), nil
+}
+
+// GetSettingsSchema retrieves the schema that applies to a namespace in the tenant:
+// the tenant's own schema if it registered one, otherwise the platform schema
+func (s *PreferencesService) GetSettingsSchema(ctx context.Context, tenantID, namespace string) (*domain.SettingsSchema, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateSettingsNamespace(namespace); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if _, err := s.authz.authorize(ctx, tenantID, auth.PermUsersUpdateSelf, auth.PermUsersUpdateAny, auth.PermPreferencesManage); err != nil {
+		return nil, err
+	}
+
+	for _, owner := range []string{tenantID, ""} {
+		schema, err := s.schemaRepo.Find(ctx, owner, namespace)
+		if err != nil {
+			s.logger.Error("Failed to get settings schema", zap.String("namespace", namespace), zap.Error(err))
+			return nil, errors.Internal("Failed to get settings schema")
+		}
+		if schema != nil {
+			return schema, nil
+		}
+	}
+	return nil, errors.NotFound("Settings schema not found")
+}
+
+// PutSettingsSchema registers or replaces the schema of a namespace at the
+// platform or tenant level. Values already stored are not re-validated; the
+// schema applies to subsequent writes
+func (s *PreferencesService) PutSettingsSchema(ctx context.Context, tenantID, level, namespace string, doc map[string]interface{}) (*domain.SettingsSchema, error) {
+	owner, err := s.schemaOwner(ctx, tenantID, level, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := validation.CompileJSONSchema(doc); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.BadRequest("schema must be valid JSON")
+	}
+
+	schema := &domain.SettingsSchema{TenantID: owner, Namespace: namespace, Schema: string(encoded)}
+	if err := s.schemaRepo.Save(ctx, schema); err != nil {
+		s.logger.Error("Failed to save settings schema", zap.String("namespace", namespace), zap.Error(err))
+		return nil, errors.Internal("Failed to save settings schema")
+	}
+
+	s.logger.Info("Settings schema registered",
+		zap.String("tenant_id", owner),
+		zap.String("namespace", namespace),
+	)
+
+	return schema, nil
+}
+
+// DeleteSettingsSchema removes the schema of a namespace at the platform or tenant level
+func (s *PreferencesService) DeleteSettingsSchema(ctx context.Context, tenantID, level, namespace string) error {
+	owner, err := s.schemaOwner(ctx, tenantID, level, namespace)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.schemaRepo.Delete(ctx, owner, namespace)
+	if err != nil {
+		s.logger.Error("Failed to delete settings schema", zap.String("namespace", namespace), zap.Error(err))
+		return errors.Internal("Failed to delete settings schema")
+	}
+	if !deleted {
+		return errors.NotFound("Settings schema not found")
+	}
+
+	s.logger.Info("Settings schema deleted",
+		zap.String("tenant_id", owner),
+		zap.String("namespace", namespace),
+	)
+
+	return nil
+}
+
+// schemaOwner validates a schema write and returns the tenant ID the schema is
+// stored under: empty for platform schemas, which only platform callers may
+// manage, and the tenant for tenant schemas, which need preferences:manage
+func (s *PreferencesService) schemaOwner(ctx context.Context, tenantID, level, namespace string) (string, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return "", errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateSettingsNamespace(namespace); err != nil {
+		return "", errors.BadRequest(err.Error())
+	}
+
+	switch level {
+	case "", domain.SettingsSchemaLevelTenant:
+		if _, err := s.authz.authorize(ctx, tenantID, auth.PermPreferencesManage); err != nil {
+			return "", err
+		}
+		return tenantID, nil
+	case domain.SettingsSchemaLevelPlatform:
+		c, err := s.authz.authorize(ctx, tenantID, auth.PermPreferencesManage)
+		if err != nil {
+			return "", err
+		}
+		if !c.isPlatform() {
+			return "", errors.Forbidden("Platform schemas can only be managed by platform administrators")
+		}
+		return "", nil
+	}
+	return "", errors.BadRequest(fmt.Sprintf("level must be %s or %s", domain.SettingsSchemaLevelPlatform, domain.SettingsSchemaLevelTenant))
+}
+
+// validateUserSettings validates the namespaces a user write touched against
+// their schemas. The resolved settings are checked, so tenant defaults can
+// supply required values and locked values are the tenant's
+func (s *PreferencesService) validateUserSettings(ctx context.Context, prefs *domain.UserPreferences, tenant *domain.TenantPreferences, written map[string]interface{}) error {
+	effective := resolvePreferences(prefs, tenant).Preferences.Settings
+	return s.validateNamespaces(ctx, prefs.TenantID, effective, written)
+}
+
+// validateNamespaces validates settings[ns] for every namespace set in written
+// Namespaces without a schema, or removed by the write, are not checked
+func (s *PreferencesService) validateNamespaces(ctx context.Context, tenantID string, settings, written map[string]interface{}) error {
+	if len(written) == 0 {
+		return nil
+	}
+
+	schemas, err := s.schemasFor(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	namespaces := make([]string, 0, len(written))
+	for ns, value := range written {
+		if value != nil {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+
+	var violations []validation.SchemaViolation
+	for _, ns := range namespaces {
+		schema, ok := schemas[ns]
+		if !ok {
+			continue
+		}
+		value, ok := settings[ns]
+		if !ok {
+			continue
+		}
+
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(schema.Schema), &doc); err != nil {
+			s.logger.Error("Stored settings schema is not valid JSON", zap.String("namespace", ns), zap.Error(err))
+			return errors.Internal("Failed to validate settings")
+		}
+		compiled, err := validation.CompileJSONSchema(doc)
+		if err != nil {
+			s.logger.Error("Stored settings schema does not compile", zap.String("namespace", ns), zap.Error(err))
+			return errors.Internal("Failed to validate settings")
+		}
+		violations = append(violations, compiled.Validate(value, "/settings/"+ns)...)
+	}
+
+	if len(violations) > 0 {
+		return &SettingsValidationError{
+			AppError:   errors.BadRequest(fmt.Sprintf("Settings do not match their schema (%d violation(s))", len(violations))),
+			Violations: violations,
+		}
+	}
+	return nil
+}
+
+// schemasFor returns the schema that applies to each namespace in the tenant
+func (s *PreferencesService) schemasFor(ctx context.Context, tenantID string) (map[string]*domain.SettingsSchema, error) {
+	result := make(map[string]*domain.SettingsSchema)
+	for _, owner := range []string{"", tenantID} {
+		schemas, err := s.schemaRepo.List(ctx, owner)
+		if err != nil {
+			s.logger.Error("Failed to load settings schemas", zap.String("tenant_id", owner), zap.Error(err))
+			return nil, errors.Internal("Failed to validate settings")
+		}
+		// Tenant schemas are loaded last and replace platform schemas
+		for _, schema := range schemas {
+			result[schema.Namespace] = schema
+		}
+	}
+	return result, nil
+}
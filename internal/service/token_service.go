@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTokenTTL is used when an issue request does not set a TTL
+	DefaultTokenTTL = 24 * time.Hour
+
+	// MaxTokenTTL caps the lifetime of opaque tokens
+	MaxTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenService issues and verifies opaque access tokens
+type TokenService struct {
+	tokenStore repository.TokenStore
+	userRepo   repository.UserStore
+	logger     *logger.Logger
+}
+
+// NewTokenService creates a new token service
+func NewTokenService(tokenStore repository.TokenStore, userRepo repository.UserStore, log *logger.Logger) *TokenService {
+	return &TokenService{
+		tokenStore: tokenStore,
+		userRepo:   userRepo,
+		logger:     log,
+	}
+}
+
+// IssueToken creates a new opaque token and returns the raw value
+// The raw token is never stored and cannot be retrieved again. Platform
+// callers can issue tokens for any user and attach claims to them; anyone
+// else only for themselves, without claims, and only for tenants their own
+// token grants access to
+func (s *TokenService) IssueToken(ctx context.Context, req *domain.IssueTokenRequest) (string, *domain.AccessToken, error) {
+	if err := validation.ValidateObjectID(req.UserID); err != nil {
+		return "", nil, errors.BadRequest(err.Error())
+	}
+
+	identity := auth.IdentityFromContext(ctx)
+	if identity == nil {
+		return "", nil, errors.Unauthorized("Authentication required")
+	}
+	tenantIDs := req.TenantIDs
+	if !identity.IsPlatform() {
+		if identity.UserID != req.UserID {
+			return "", nil, errors.Forbidden("Only platform services can issue tokens for other users")
+		}
+		// Claims are passed on to downstream services as trusted
+		if len(req.Claims) > 0 {
+			return "", nil, errors.Forbidden("Only platform services can set token claims")
+		}
+		// A self-issued token is never wider than the one it was issued with
+		if len(tenantIDs) == 0 {
+			tenantIDs = identity.TenantIDs
+		}
+		for _, tenantID := range tenantIDs {
+			if !identity.HasTenant(tenantID) {
+				return "", nil, errors.Forbidden("Access to tenant " + tenantID + " is not allowed")
+			}
+		}
+	}
+
+	for _, tenantID := range tenantIDs {
+		if err := validation.ValidateTenantID(tenantID); err != nil {
+			return "", nil, errors.BadRequest(err.Error())
+		}
+	}
+
+	if req.Role != "" {
+		if err := validation.ValidateRoles([]string{req.Role}); err != nil {
+			return "", nil, errors.BadRequest(err.Error())
+		}
+	}
+
+	ttl := req.TTL
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
+	}
+	if ttl > MaxTokenTTL {
+		return "", nil, errors.BadRequest("ttl exceeds the maximum of 30 days")
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, req.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.String("user_id", req.UserID), zap.Error(err))
+		return "", nil, errors.Internal("Failed to issue token")
+	}
+	if user == nil || !user.IsActive {
+		return "", nil, errors.NotFound("User not found")
+	}
+
+	// Tokens may only be scoped to tenants the user is an active member of
+	memberships, err := s.activeMemberships(ctx, req.UserID)
+	if err != nil {
+		return "", nil, errors.Internal("Failed to issue token")
+	}
+	for _, tenantID := range tenantIDs {
+		if _, ok := memberships[tenantID]; !ok {
+			return "", nil, errors.BadRequest("User is not an active member of tenant " + tenantID)
+		}
+	}
+
+	raw, err := generateToken()
+	if err != nil {
+		s.logger.Error("Failed to generate token", zap.Error(err))
+		return "", nil, errors.Internal("Failed to issue token")
+	}
+
+	token := &domain.AccessToken{
+		TokenHash: hashToken(raw),
+		UserID:    user.ID,
+		TenantIDs: tenantIDs,
+		Role:      req.Role,
+		Claims:    req.Claims,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenStore.Create(ctx, token); err != nil {
+		s.logger.Error("Failed to store token", zap.Error(err))
+		return "", nil, errors.Internal("Failed to issue token")
+	}
+
+	s.logger.Info("Token issued",
+		zap.String("user_id", req.UserID),
+		zap.Strings("tenant_ids", tenantIDs),
+	)
+
+	return raw, token, nil
+}
+
+// RevokeToken revokes an opaque token
+func (s *TokenService) RevokeToken(ctx context.Context, raw string) error {
+	if raw == "" {
+		return errors.BadRequest("token is required")
+	}
+
+	found, err := s.tokenStore.Revoke(ctx, hashToken(raw))
+	if err != nil {
+		s.logger.Error("Failed to revoke token", zap.Error(err))
+		return errors.Internal("Failed to revoke token")
+	}
+	if !found {
+		return errors.NotFound("Token not found")
+	}
+	return nil
+}
+
+// VerifyToken verifies an opaque token against the token store and the
+// user's current memberships. Tokens of deactivated users, and tokens whose
+// tenants are no longer active memberships, are reported as invalid.
+// When tenantID is set the token must grant access to that tenant.
+func (s *TokenService) VerifyToken(ctx context.Context, raw, tenantID string) (*domain.TokenVerification, error) {
+	invalid := &domain.TokenVerification{Valid: false}
+	if raw == "" {
+		return invalid, nil
+	}
+
+	token, err := s.tokenStore.FindByHash(ctx, hashToken(raw))
+	if err != nil {
+		s.logger.Error("Failed to look up token", zap.Error(err))
+		return nil, errors.Internal("Failed to verify token")
+	}
+	if token == nil || token.RevokedAt != nil || !time.Now().Before(token.ExpiresAt) {
+		return invalid, nil
+	}
+
+	userID := token.UserID.Hex()
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to verify token")
+	}
+	if user == nil || !user.IsActive {
+		return invalid, nil
+	}
+
+	memberships, err := s.activeMemberships(ctx, userID)
+	if err != nil {
+		return nil, errors.Internal("Failed to verify token")
+	}
+
+	// Restrict to the tenants the token was scoped to
+	allowed := make(map[string]*domain.UserTenant, len(memberships))
+	if len(token.TenantIDs) == 0 {
+		allowed = memberships
+	} else {
+		for _, id := range token.TenantIDs {
+			if ut, ok := memberships[id]; ok {
+				allowed[id] = ut
+			}
+		}
+	}
+	if len(allowed) == 0 {
+		return invalid, nil
+	}
+
+	result := &domain.TokenVerification{
+		Valid:     true,
+		UserID:    userID,
+		TenantIDs: make([]string, 0, len(allowed)),
+		Role:      token.Role,
+		Claims:    make(map[string]string, len(token.Claims)+1),
+	}
+	for id := range allowed {
+		result.TenantIDs = append(result.TenantIDs, id)
+	}
+	sort.Strings(result.TenantIDs)
+	for k, v := range token.Claims {
+		result.Claims[k] = v
+	}
+
+	if tenantID != "" {
+		ut, ok := allowed[tenantID]
+		if !ok {
+			return invalid, nil
+		}
+		// A role-scoped token is only valid while the member still holds that role
+		if token.Role != "" && !containsString(ut.Roles, token.Role) {
+			return invalid, nil
+		}
+		if token.Role != "" {
+			result.Claims["roles"] = token.Role
+		} else {
+			if len(ut.Roles) > 0 {
+				result.Role = ut.Roles[0]
+			}
+			result.Claims["roles"] = strings.Join(ut.Roles, ",")
+		}
+		s.touch(ctx, ut)
+	}
+
+	return result, nil
+}
+
+// touch records that the member of ut was seen, at most once per
+// domain.LastSeenResolution. Failures are logged, the token stays valid
+func (s *TokenService) touch(ctx context.Context, ut *domain.UserTenant) {
+	now := time.Now().UTC()
+	if ut.LastSeenAt != nil && now.Sub(*ut.LastSeenAt) < domain.LastSeenResolution {
+		return
+	}
+	if _, err := s.userRepo.Touch(ctx, ut.UserID, ut.TenantID, now); err != nil {
+		s.logger.Warn("Failed to record membership activity",
+			zap.String("user_id", ut.UserID.Hex()),
+			zap.String("tenant_id", ut.TenantID),
+			zap.Error(err),
+		)
+	}
+}
+
+// activeMemberships returns the user's active memberships keyed by tenant ID
+func (s *TokenService) activeMemberships(ctx context.Context, userID string) (map[string]*domain.UserTenant, error) {
+	tenants, err := s.userRepo.FindTenants(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user tenants", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	active := make(map[string]*domain.UserTenant, len(tenants))
+	for _, ut := range tenants {
+		if ut.Active() {
+			active[ut.TenantID] = ut
+		}
+	}
+	return active, nil
+}
+
+// generateToken returns a random URL-safe token with 256 bits of entropy
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 hash of a raw token
+// Tokens are high-entropy random values, so a fast hash is sufficient
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+// memoryTokenStore keeps tokens in a map keyed by hash
+type memoryTokenStore struct {
+	tokens map[string]*domain.AccessToken
+}
+
+func (s *memoryTokenStore) Create(ctx context.Context, token *domain.AccessToken) error {
+	s.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (s *memoryTokenStore) FindByHash(ctx context.Context, tokenHash string) (*domain.AccessToken, error) {
+	return s.tokens[tokenHash], nil
+}
+
+func (s *memoryTokenStore) Revoke(ctx context.Context, tokenHash string) (bool, error) {
+	_, ok := s.tokens[tokenHash]
+	delete(s.tokens, tokenHash)
+	return ok, nil
+}
+
+func TestTokenService_IssueToken(t *testing.T) {
+	services := newTestServices(t)
+	users := services.users
+	tokens := NewTokenService(&memoryTokenStore{tokens: map[string]*domain.AccessToken{}}, services.userRepo, services.log)
+	ctx := platformContext()
+
+	owner, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "owner@example.com", TenantID: "tenant-a", Roles: []string{"owner"}})
+	require.NoError(t, err)
+	ownerID := owner.User.ID.Hex()
+	lan, err := users.CreateUser(ctx, &domain.CreateUserRequest{Email: "lan@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	lanID := lan.User.ID.Hex()
+	_, err = users.AddUserToTenant(ctx, lanID, "tenant-b", nil, "")
+	require.NoError(t, err)
+
+	// Members cannot issue tokens for anyone else
+	_, _, err = tokens.IssueToken(memberContext(lanID, "tenant-a"), &domain.IssueTokenRequest{UserID: ownerID, TenantIDs: []string{"tenant-a"}})
+	assertStatus(t, err, http.StatusForbidden)
+	_, _, err = tokens.IssueToken(context.Background(), &domain.IssueTokenRequest{UserID: lanID})
+	assertStatus(t, err, http.StatusUnauthorized)
+
+	// Nor attach claims to their own token
+	_, _, err = tokens.IssueToken(memberContext(lanID, "tenant-a"), &domain.IssueTokenRequest{UserID: lanID, Claims: map[string]string{"plan": "enterprise"}})
+	assertStatus(t, err, http.StatusForbidden)
+
+	// Nor widen their own token to other tenants
+	_, _, err = tokens.IssueToken(memberContext(lanID, "tenant-a"), &domain.IssueTokenRequest{UserID: lanID, TenantIDs: []string{"tenant-b"}})
+	assertStatus(t, err, http.StatusForbidden)
+	raw, token, err := tokens.IssueToken(memberContext(lanID, "tenant-a"), &domain.IssueTokenRequest{UserID: lanID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant-a"}, token.TenantIDs)
+	result, err := tokens.VerifyToken(context.Background(), raw, "tenant-b")
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+
+	// Platform callers issue tokens for any member
+	raw, _, err = tokens.IssueToken(ctx, &domain.IssueTokenRequest{UserID: ownerID, TenantIDs: []string{"tenant-a"}, Claims: map[string]string{"plan": "enterprise"}})
+	require.NoError(t, err)
+	result, err = tokens.VerifyToken(context.Background(), raw, "tenant-a")
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, ownerID, result.UserID)
+	assert.Equal(t, "enterprise", result.Claims["plan"])
+}
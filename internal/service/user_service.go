@@ -2,25 +2,32 @@ package service
 
 import (
 	"context"
+	stderrors "errors"
+	"strings"
+	"time"
 
 	"github.com/vhvplatform/go-shared/errors"
 	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
 	"github.com/vhvplatform/go-user-service/internal/domain"
 	"github.com/vhvplatform/go-user-service/internal/repository"
 	"github.com/vhvplatform/go-user-service/internal/validation"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // UserService handles user business logic
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo repository.UserStore
+	authz    *authorizer
 	logger   *logger.Logger
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo *repository.UserRepository, log *logger.Logger) *UserService {
+func NewUserService(userRepo repository.UserStore, roleRepo repository.RoleStore, log *logger.Logger) *UserService {
 	return &UserService{
 		userRepo: userRepo,
+		authz:    &authorizer{userRepo: userRepo, roleRepo: roleRepo, logger: log},
 		logger:   log,
 	}
 }
@@ -54,6 +61,54 @@ func (s *UserService) CreateUser(ctx context.Context, req *domain.CreateUserRequ
 		return nil, errors.BadRequest(err.Error())
 	}
 
+	if err := validation.ValidateUsername(req.Username); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateDocumentNumber(req.DocumentNumber); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidatePassword(req.Password); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateRoles(req.Roles); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	// New members start out invited, pending approval or active
+	status := req.Status
+	switch status {
+	case "":
+		status = domain.MembershipActive
+	case domain.MembershipInvited, domain.MembershipPending, domain.MembershipActive:
+	default:
+		return nil, errors.BadRequest("status must be one of invited, pending, active")
+	}
+
+	expiresAt, err := validation.ParseExpiresAt(req.ExpiresAt, time.Now())
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	caller, err := s.authz.authorize(ctx, req.TenantID, auth.PermUsersCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare UserTenant roles
+	roles := req.Roles
+	if len(roles) == 0 {
+		roles = []string{auth.RoleUser} // Default role
+	}
+	if err := s.authz.validateAssignment(ctx, caller, req.TenantID, roles); err != nil {
+		return nil, err
+	}
+	if !caller.canGrant(roles) {
+		return nil, errors.Forbidden("Cannot assign roles with more permissions than your own")
+	}
+
 	// Check if user already exists in this tenant
 	existingUser, existingTenant, err := s.userRepo.FindByEmail(ctx, req.Email, req.TenantID)
 	if err != nil {
@@ -61,18 +116,30 @@ func (s *UserService) CreateUser(ctx context.Context, req *domain.CreateUserRequ
 		return nil, errors.Internal("Failed to create user")
 	}
 	if existingTenant != nil {
+		if existingTenant.CurrentStatus() == domain.MembershipRemoved {
+			return nil, errors.Conflict("User was removed from this tenant, restore them instead")
+		}
 		return nil, errors.Conflict("User already exists in this tenant")
 	}
 
 	// Prepare User (Global)
 	user := &domain.User{
-		Email: req.Email,
-		Phone: req.Phone,
+		Email:          req.Email,
+		Username:       req.Username,
+		DocumentNumber: req.DocumentNumber,
+		Phone:          req.Phone,
 		// AvatarURL: ... (not in creation request usually, or maybe it is)
 		IsActive: true,
 	}
 	if existingUser != nil {
 		user = existingUser // Use existing user identity
+	} else if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			s.logger.Error("Failed to hash password", zap.Error(err))
+			return nil, errors.Internal("Failed to create user")
+		}
+		user.PasswordHash = string(hash)
 	}
 
 	// Prepare UserTenant
@@ -80,11 +147,18 @@ func (s *UserService) CreateUser(ctx context.Context, req *domain.CreateUserRequ
 		TenantID:  req.TenantID,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
-		IsActive:  true,
-		Roles:     []string{"user"}, // Default role
+		Roles:     roles,
+		ExpiresAt: expiresAt,
 	}
+	userTenant.ApplyTransition(domain.MembershipTransition{To: status, By: caller.identity.UserID, At: time.Now().UTC()})
 
 	if err := s.userRepo.Create(ctx, user, userTenant); err != nil {
+		switch {
+		case stderrors.Is(err, repository.ErrUserTenantExists):
+			return nil, errors.Conflict("User already exists in this tenant")
+		case stderrors.Is(err, repository.ErrIdentifierTaken):
+			return nil, errors.Conflict("Username or document number is already in use")
+		}
 		s.logger.Error("Failed to create user", zap.Error(err))
 		return nil, errors.Internal("Failed to create user")
 	}
@@ -120,50 +194,426 @@ func (s *UserService) GetUser(ctx context.Context, id, tenantID string) (*domain
 	return &domain.UserProfile{User: user, UserTenant: userTenant}, nil
 }
 
-// ListUsers lists users for a tenant with pagination
-func (s *UserService) ListUsers(ctx context.Context, tenantID string, page, pageSize int) ([]*domain.UserProfile, int64, error) {
-	// Validate input
+// GetUserByIdentifier retrieves a global user by email, username, phone or document number
+// When tenantID is set, the user must belong to that tenant and only that membership is returned
+// Only platform callers may look users up across tenants, and the password hash is
+// only returned to callers carrying auth.RoleCredentialsReader
+func (s *UserService) GetUserByIdentifier(ctx context.Context, identifier, identifierType, tenantID string) (*domain.User, []*domain.UserTenant, error) {
+	identity := auth.IdentityFromContext(ctx)
+	if identity == nil {
+		return nil, nil, errors.Unauthorized("Authentication required")
+	}
+	if !identity.IsPlatform() {
+		return nil, nil, errors.Forbidden("Only platform services can look up users by identifier")
+	}
+
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return nil, nil, errors.BadRequest("identifier is required")
+	}
+
+	if err := validation.ValidateIdentifierType(identifierType); err != nil {
+		return nil, nil, errors.BadRequest(err.Error())
+	}
+
+	if tenantID != "" {
+		if err := validation.ValidateTenantID(tenantID); err != nil {
+			return nil, nil, errors.BadRequest(err.Error())
+		}
+	}
+
+	// Emails are unambiguous, so detect them when no type is given
+	if identifierType == "" && strings.Contains(identifier, "@") {
+		identifierType = validation.IdentifierEmail
+	}
+
+	user, err := s.userRepo.FindByIdentifier(ctx, identifier, identifierType)
+	if err != nil {
+		s.logger.Error("Failed to get user by identifier", zap.Error(err))
+		return nil, nil, errors.Internal("Failed to get user")
+	}
+	if user == nil {
+		return nil, nil, errors.NotFound("User not found")
+	}
+
+	tenants, err := s.userRepo.FindTenants(ctx, user.ID.Hex())
+	if err != nil {
+		s.logger.Error("Failed to get user tenants", zap.String("user_id", user.ID.Hex()), zap.Error(err))
+		return nil, nil, errors.Internal("Failed to get user")
+	}
+
+	if tenantID != "" {
+		var filtered []*domain.UserTenant
+		for _, ut := range tenants {
+			if ut.TenantID == tenantID {
+				filtered = append(filtered, ut)
+			}
+		}
+		if len(filtered) == 0 {
+			return nil, nil, errors.NotFound("User not found in this tenant")
+		}
+		tenants = filtered
+	}
+
+	if !identity.HasRole(auth.RoleCredentialsReader) {
+		user.PasswordHash = ""
+	}
+	return user, tenants, nil
+}
+
+// GetUserTenants lists the tenant memberships of a user, active or not
+// Users and platform callers see every membership; anyone else only sees
+// memberships in tenants their token grants access to
+func (s *UserService) GetUserTenants(ctx context.Context, userID string) ([]*domain.UserTenant, error) {
+	if err := validation.ValidateObjectID(userID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	identity := auth.IdentityFromContext(ctx)
+	if identity == nil {
+		return nil, errors.Unauthorized("Authentication required")
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to get user tenants")
+	}
+	if user == nil {
+		return nil, errors.NotFound("User not found")
+	}
+
+	tenants, err := s.userRepo.FindTenants(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user tenants", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.Internal("Failed to get user tenants")
+	}
+
+	if identity.IsPlatform() || identity.UserID == userID {
+		return tenants, nil
+	}
+	visible := make([]*domain.UserTenant, 0, len(tenants))
+	for _, ut := range tenants {
+		if identity.HasTenant(ut.TenantID) {
+			visible = append(visible, ut)
+		}
+	}
+	return visible, nil
+}
+
+// AddUserToTenant links an existing global user to a tenant with the given roles
+// and optional RFC 3339 expiry. A previously removed membership is restored
+// with the new roles and expiry
+func (s *UserService) AddUserToTenant(ctx context.Context, userID, tenantID string, roles []string, expiresAt string) (*domain.UserTenant, error) {
+	if err := validation.ValidateObjectID(userID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
 	if err := validation.ValidateTenantID(tenantID); err != nil {
-		return nil, 0, errors.BadRequest(err.Error())
+		return nil, errors.BadRequest(err.Error())
 	}
-	page, pageSize, _ = validation.ValidatePagination(page, pageSize)
 
-	results, total, err := s.userRepo.List(ctx, tenantID, page, pageSize)
+	if err := validation.ValidateRoles(roles); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if len(roles) == 0 {
+		roles = []string{auth.RoleUser} // Default role
+	}
+
+	expiry, err := validation.ParseExpiresAt(expiresAt, time.Now())
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	caller, err := s.authz.authorize(ctx, tenantID, auth.PermUsersCreate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authz.validateAssignment(ctx, caller, tenantID, roles); err != nil {
+		return nil, err
+	}
+	if !caller.canGrant(roles) {
+		return nil, errors.Forbidden("Cannot assign roles with more permissions than your own")
+	}
+
+	user, userTenant, err := s.userRepo.FindByID(ctx, userID, tenantID)
+	if err != nil {
+		s.logger.Error("Failed to check existing membership", zap.Error(err))
+		return nil, errors.Internal("Failed to add user to tenant")
+	}
+
+	if userTenant != nil {
+		// Suspended, deactivated and not yet accepted memberships keep their status
+		if userTenant.CurrentStatus() != domain.MembershipRemoved {
+			return nil, errors.Conflict("User already exists in this tenant")
+		}
+		restore := domain.MembershipTransition{
+			From:   domain.MembershipRemoved,
+			To:     domain.MembershipActive,
+			Reason: "added to tenant",
+			By:     caller.identity.UserID,
+			At:     time.Now().UTC(),
+		}
+		ok, err := s.userRepo.Restore(ctx, userTenant.UserID, tenantID, roles, expiry, restore)
+		if err != nil {
+			s.logger.Error("Failed to restore membership", zap.Error(err))
+			return nil, errors.Internal("Failed to add user to tenant")
+		}
+		if !ok {
+			return nil, errors.Conflict("User already exists in this tenant")
+		}
+		userTenant.ApplyTransition(restore)
+		userTenant.Roles = roles
+		userTenant.ExpiresAt = expiry
+		return userTenant, nil
+	}
+
+	if user == nil {
+		user, err = s.userRepo.FindUserByID(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to get user", zap.String("user_id", userID), zap.Error(err))
+			return nil, errors.Internal("Failed to add user to tenant")
+		}
+		if user == nil {
+			return nil, errors.NotFound("User not found")
+		}
+	}
+
+	userTenant = &domain.UserTenant{
+		UserID:    user.ID,
+		TenantID:  tenantID,
+		Roles:     roles,
+		ExpiresAt: expiry,
+	}
+	userTenant.ApplyTransition(domain.MembershipTransition{To: domain.MembershipActive, By: caller.identity.UserID, At: time.Now().UTC()})
+	if err := s.userRepo.AddToTenant(ctx, userTenant); err != nil {
+		if stderrors.Is(err, repository.ErrUserTenantExists) {
+			return nil, errors.Conflict("User already exists in this tenant")
+		}
+		s.logger.Error("Failed to add user to tenant", zap.Error(err))
+		return nil, errors.Internal("Failed to add user to tenant")
+	}
+
+	s.logger.Info("User added to tenant",
+		zap.String("user_id", userID),
+		zap.String("tenant_id", tenantID),
+	)
+
+	return userTenant, nil
+}
+
+// UpdateUserTenantRoles replaces a member's roles in a tenant
+func (s *UserService) UpdateUserTenantRoles(ctx context.Context, userID, tenantID string, roles []string) (*domain.UserTenant, error) {
+	if roles == nil {
+		roles = []string{}
+	}
+	profile, err := s.UpdateUser(ctx, userID, tenantID, &domain.UpdateUserRequest{Roles: roles})
+	if err != nil {
+		return nil, err
+	}
+	return profile.UserTenant, nil
+}
+
+// RemoveUserFromTenant removes a user's membership in a tenant (soft delete)
+func (s *UserService) RemoveUserFromTenant(ctx context.Context, userID, tenantID string) error {
+	return s.DeleteUser(ctx, userID, tenantID)
+}
+
+// ListUsers lists users for a tenant with pagination, filtering and sorting
+func (s *UserService) ListUsers(ctx context.Context, req *domain.ListUsersRequest) ([]*domain.UserProfile, int64, error) {
+	q, err := listQuery(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	q.Page, q.PageSize, _ = validation.ValidatePagination(req.Page, req.PageSize)
+
+	results, total, err := s.userRepo.List(ctx, q)
 	if err != nil {
 		s.logger.Error("Failed to list users", zap.Error(err))
 		return nil, 0, errors.Internal("Failed to list users")
 	}
+	return toProfiles(results), total, nil
+}
 
-	profiles := make([]*domain.UserProfile, len(results))
-	for i, r := range results {
-		profiles[i] = &domain.UserProfile{
-			User:       &r.User,
-			UserTenant: &r.UserTenant,
+// ListUsersAfter lists users for a tenant with cursor pagination. An empty
+// req.Cursor starts at the first page; the total is only counted when
+// req.IncludeTotal is set
+func (s *UserService) ListUsersAfter(ctx context.Context, req *domain.ListUsersRequest) (*domain.UserPage, error) {
+	q, err := listQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return pageAfter(q, req.Cursor, req.PageSize, req.IncludeTotal, func(q repository.MemberQuery) ([]*repository.UserWithTenant, int64, error) {
+		results, total, err := s.userRepo.List(ctx, q)
+		if err != nil {
+			s.logger.Error("Failed to list users", zap.Error(err))
+			return nil, 0, errors.Internal("Failed to list users")
 		}
+		return results, total, nil
+	})
+}
+
+// listQuery validates a list request's tenant, filter and sort
+func listQuery(req *domain.ListUsersRequest) (repository.MemberQuery, error) {
+	if err := validation.ValidateTenantID(req.TenantID); err != nil {
+		return repository.MemberQuery{}, errors.BadRequest(err.Error())
+	}
+
+	filter, err := validation.ParseUserFilter(req.Filter)
+	if err != nil {
+		return repository.MemberQuery{}, errors.BadRequest(err.Error())
 	}
-	return profiles, total, nil
+
+	order, err := validation.ParseUserSort(req.Sort)
+	if err != nil {
+		return repository.MemberQuery{}, errors.BadRequest(err.Error())
+	}
+
+	return repository.MemberQuery{TenantID: req.TenantID, Filter: filter, Sort: order}, nil
 }
 
-// SearchUsers searches users by query
-func (s *UserService) SearchUsers(ctx context.Context, tenantID, query string, page, pageSize int) ([]*domain.UserProfile, int64, error) {
-	// Validate input
-	if err := validation.ValidateTenantID(tenantID); err != nil {
-		return nil, 0, errors.BadRequest(err.Error())
+// CountUserFacets counts the members a listing matches, or a search when query
+// is not empty, by each facet in req.Facets with req.Filter applied. It returns
+// nil when no facets are requested
+func (s *UserService) CountUserFacets(ctx context.Context, req *domain.ListUsersRequest, query string) (domain.UserFacets, error) {
+	facets, err := validation.ParseUserFacets(req.Facets)
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	q, err := listQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	if query != "" {
+		if query, err = validateSearch(req.TenantID, query); err != nil {
+			return nil, err
+		}
+	}
+	if len(facets) == 0 {
+		return nil, nil
 	}
 
-	if err := validation.ValidateSearchQuery(query); err != nil {
-		return nil, 0, errors.BadRequest(err.Error())
+	counts, err := s.userRepo.Facets(ctx, q, query, facets)
+	if err != nil {
+		s.logger.Error("Failed to count user facets", zap.Error(err))
+		return nil, errors.Internal("Failed to count user facets")
 	}
+	return counts, nil
+}
 
-	query = validation.SanitizeString(query)
+// SearchUsers searches users by query, best matches first, restricted to
+// members matching filter (ListUsers syntax) when it is not empty
+func (s *UserService) SearchUsers(ctx context.Context, tenantID, query, filter string, page, pageSize int) ([]*domain.UserProfile, int64, error) {
+	query, err := validateSearch(tenantID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := validation.ParseUserFilter(filter)
+	if err != nil {
+		return nil, 0, errors.BadRequest(err.Error())
+	}
 	page, pageSize, _ = validation.ValidatePagination(page, pageSize)
 
-	results, total, err := s.userRepo.Search(ctx, tenantID, query, page, pageSize)
+	results, total, err := s.userRepo.Search(ctx, repository.MemberQuery{TenantID: tenantID, Filter: f, Page: page, PageSize: pageSize}, query)
 	if err != nil {
 		s.logger.Error("Failed to search users", zap.Error(err))
 		return nil, 0, errors.Internal("Failed to search users")
 	}
+	return toProfiles(results), total, nil
+}
+
+// SearchUsersAfter searches users by query with cursor pagination, best matches
+// first, restricted to members matching filter when it is not empty
+func (s *UserService) SearchUsersAfter(ctx context.Context, tenantID, query, filter, cursor string, pageSize int, includeTotal bool) (*domain.UserPage, error) {
+	query, err := validateSearch(tenantID, query)
+	if err != nil {
+		return nil, err
+	}
+	f, err := validation.ParseUserFilter(filter)
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	q := repository.MemberQuery{TenantID: tenantID, Filter: f, Sort: domain.RelevanceUserSort}
+	return pageAfter(q, cursor, pageSize, includeTotal, func(q repository.MemberQuery) ([]*repository.UserWithTenant, int64, error) {
+		results, total, err := s.userRepo.Search(ctx, q, query)
+		if err != nil {
+			s.logger.Error("Failed to search users", zap.Error(err))
+			return nil, 0, errors.Internal("Failed to search users")
+		}
+		return results, total, nil
+	})
+}
+
+// AutocompleteUsers suggests active members whose names or email start with
+// query, for member pickers. A single character is enough, and at most limit
+// suggestions (see validation.ValidateAutocompleteLimit) are returned
+func (s *UserService) AutocompleteUsers(ctx context.Context, tenantID, query string, limit int) ([]*domain.MemberSuggestion, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+	if err := validation.ValidateAutocompleteQuery(query); err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
 
+	suggestions, err := s.userRepo.Suggest(ctx, tenantID, validation.SanitizeString(query), validation.ValidateAutocompleteLimit(limit))
+	if err != nil {
+		s.logger.Error("Failed to autocomplete users", zap.Error(err))
+		return nil, errors.Internal("Failed to autocomplete users")
+	}
+	return suggestions, nil
+}
+
+// validateSearch validates a search request and returns the sanitized query
+func validateSearch(tenantID, query string) (string, error) {
+	if err := validation.ValidateTenantID(tenantID); err != nil {
+		return "", errors.BadRequest(err.Error())
+	}
+
+	if err := validation.ValidateSearchQuery(query); err != nil {
+		return "", errors.BadRequest(err.Error())
+	}
+
+	return validation.SanitizeString(query), nil
+}
+
+// pageAfter fetches the page of q following cursor. One extra member is
+// requested to tell whether another page exists without counting
+func pageAfter(q repository.MemberQuery, cursor string, pageSize int, includeTotal bool, fetch func(repository.MemberQuery) ([]*repository.UserWithTenant, int64, error)) (*domain.UserPage, error) {
+	_, pageSize, _ = validation.ValidatePagination(1, pageSize)
+
+	q.PageSize = pageSize + 1
+	q.SkipTotal = !includeTotal
+	if cursor != "" {
+		after, err := decodeMemberCursor(cursor, q.Sort)
+		if err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		q.After = after
+	} else {
+		q.Page = 1
+	}
+
+	results, total, err := fetch(q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.UserPage{}
+	if len(results) > pageSize {
+		results = results[:pageSize]
+		page.NextCursor = encodeMemberCursor(repository.Position(results[pageSize-1], q.Sort), q.Sort)
+	}
+	page.Users = toProfiles(results)
+	if includeTotal {
+		page.Total = &total
+	}
+	return page, nil
+}
+
+// toProfiles converts joined memberships into user profiles
+func toProfiles(results []*repository.UserWithTenant) []*domain.UserProfile {
 	profiles := make([]*domain.UserProfile, len(results))
 	for i, r := range results {
 		profiles[i] = &domain.UserProfile{
@@ -171,7 +621,7 @@ func (s *UserService) SearchUsers(ctx context.Context, tenantID, query string, p
 			UserTenant: &r.UserTenant,
 		}
 	}
-	return profiles, total, nil
+	return profiles
 }
 
 // UpdateUser updates a user
@@ -185,6 +635,42 @@ func (s *UserService) UpdateUser(ctx context.Context, id, tenantID string, req *
 		return nil, errors.BadRequest(err.Error())
 	}
 
+	// Members may update their own profile; updating anyone else needs users:update:any
+	perms := []auth.Permission{auth.PermUsersUpdateAny}
+	if identity := auth.IdentityFromContext(ctx); identity != nil && identity.UserID == id {
+		perms = append(perms, auth.PermUsersUpdateSelf)
+	}
+	// Changing roles or the membership's expiry is never a self-service action
+	if req.Roles != nil {
+		perms = []auth.Permission{auth.PermUsersUpdateAny}
+		if err := validation.ValidateRoles(req.Roles); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		if len(req.Roles) == 0 {
+			return nil, errors.BadRequest("roles must not be empty")
+		}
+	}
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		perms = []auth.Permission{auth.PermUsersUpdateAny}
+		var err error
+		if expiresAt, err = validation.ParseExpiresAt(*req.ExpiresAt, time.Now()); err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+	}
+	caller, err := s.authz.authorize(ctx, tenantID, perms...)
+	if err != nil {
+		return nil, err
+	}
+	if req.Roles != nil {
+		if err := s.authz.validateAssignment(ctx, caller, tenantID, req.Roles); err != nil {
+			return nil, err
+		}
+		if !caller.canGrant(req.Roles) {
+			return nil, errors.Forbidden("Cannot assign roles with more permissions than your own")
+		}
+	}
+
 	// Validate fields... (omitted detailed sanitization repetition for brevity, assume similar to Create)
 	if req.FirstName != "" {
 		validation.SanitizeName(req.FirstName)
@@ -199,6 +685,14 @@ func (s *UserService) UpdateUser(ctx context.Context, id, tenantID string, req *
 	if user == nil || userTenant == nil {
 		return nil, errors.NotFound("User not found")
 	}
+	// Members cannot demote anyone holding permissions they lack themselves
+	if req.Roles != nil && !caller.canGrant(userTenant.Roles) {
+		return nil, errors.Forbidden("Cannot change the roles of a member with more permissions than your own")
+	}
+	// An expiry locks a member out like a suspension does
+	if req.ExpiresAt != nil && !caller.canGrant(userTenant.Roles) {
+		return nil, errors.Forbidden("Cannot change the expiry of a member with more permissions than your own")
+	}
 
 	// Update Fields
 	// Global fields
@@ -222,6 +716,14 @@ func (s *UserService) UpdateUser(ctx context.Context, id, tenantID string, req *
 		userTenant.LastName = req.LastName
 		tenantUpdated = true
 	}
+	if req.Roles != nil {
+		userTenant.Roles = req.Roles
+		tenantUpdated = true
+	}
+	if req.ExpiresAt != nil {
+		userTenant.ExpiresAt = expiresAt
+		tenantUpdated = true
+	}
 
 	var u *domain.User
 	if userUpdated {
@@ -240,7 +742,8 @@ func (s *UserService) UpdateUser(ctx context.Context, id, tenantID string, req *
 	return &domain.UserProfile{User: user, UserTenant: userTenant}, nil
 }
 
-// DeleteUser deletes a user
+// DeleteUser removes a user from the tenant. The membership is kept as
+// removed, hidden from listings, until it is restored
 func (s *UserService) DeleteUser(ctx context.Context, id, tenantID string) error {
 	// Validate input
 	if err := validation.ValidateObjectID(id); err != nil {
@@ -251,6 +754,11 @@ func (s *UserService) DeleteUser(ctx context.Context, id, tenantID string) error
 		return errors.BadRequest(err.Error())
 	}
 
+	caller, err := s.authz.authorize(ctx, tenantID, auth.PermUsersDelete)
+	if err != nil {
+		return err
+	}
+
 	// Check if user exists (or just delete blindly, but we usually check first)
 	_, userTenant, err := s.userRepo.FindByID(ctx, id, tenantID)
 	if err != nil {
@@ -259,11 +767,27 @@ func (s *UserService) DeleteUser(ctx context.Context, id, tenantID string) error
 	if userTenant == nil {
 		return errors.NotFound("User not found")
 	}
+	if !caller.canGrant(userTenant.Roles) {
+		return errors.Forbidden("Cannot remove a member with more permissions than your own")
+	}
+	current := userTenant.CurrentStatus()
+	if current == domain.MembershipRemoved {
+		return nil
+	}
 
-	if err := s.userRepo.Delete(ctx, id, tenantID); err != nil {
+	ok, err := s.userRepo.SetStatus(ctx, userTenant.UserID, tenantID, current, domain.MembershipTransition{
+		From: current,
+		To:   domain.MembershipRemoved,
+		By:   caller.identity.UserID,
+		At:   time.Now().UTC(),
+	})
+	if err != nil {
 		s.logger.Error("Failed to delete user", zap.Error(err))
 		return errors.Internal("Failed to delete user")
 	}
+	if !ok {
+		return errors.Conflict("Membership status changed concurrently, retry")
+	}
 
 	return nil
 }
@@ -0,0 +1,436 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vhvplatform/go-shared/errors"
+	"github.com/vhvplatform/go-shared/logger"
+	"github.com/vhvplatform/go-user-service/internal/auth"
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"github.com/vhvplatform/go-user-service/internal/repository"
+	"github.com/vhvplatform/go-user-service/internal/validation"
+)
+
+func newTestUserService(t *testing.T) *UserService {
+	t.Helper()
+	return newTestServices(t).users
+}
+
+// testServices wires a UserService and the stores other services under test
+// share with it
+type testServices struct {
+	log         *logger.Logger
+	userRepo    *repository.InMemoryUserRepository
+	roleRepo    *repository.InMemoryRoleRepository
+	prefRepo    *repository.InMemoryPreferencesRepository
+	schemaRepo  *repository.InMemorySettingsSchemaRepository
+	segmentRepo *repository.InMemorySegmentRepository
+	policyRepo  *repository.InMemoryPolicyRepository
+	auditRepo   repository.AuditStore
+	users       *UserService
+}
+
+// testOption replaces one of the stores of testServices
+type testOption func(*testServices)
+
+// withAuditStore records audit entries in store instead of an in-memory log
+func withAuditStore(store repository.AuditStore) testOption {
+	return func(s *testServices) {
+		s.auditRepo = store
+	}
+}
+
+func newTestServices(t *testing.T, opts ...testOption) *testServices {
+	t.Helper()
+	log, err := logger.New("error")
+	require.NoError(t, err)
+	s := &testServices{
+		log:         log,
+		userRepo:    repository.NewInMemoryUserRepository(),
+		roleRepo:    repository.NewInMemoryRoleRepository(),
+		prefRepo:    repository.NewInMemoryPreferencesRepository(),
+		schemaRepo:  repository.NewInMemorySettingsSchemaRepository(),
+		segmentRepo: repository.NewInMemorySegmentRepository(),
+		policyRepo:  repository.NewInMemoryPolicyRepository(),
+		auditRepo:   repository.NewInMemoryAuditRepository(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.users = NewUserService(s.userRepo, s.roleRepo, log)
+	return s
+}
+
+func (s *testServices) roles() *RoleService {
+	return NewRoleService(s.roleRepo, s.userRepo, s.log)
+}
+
+func (s *testServices) preferences() *PreferencesService {
+	return NewPreferencesService(s.prefRepo, s.schemaRepo, s.userRepo, s.roleRepo, s.log)
+}
+
+func (s *testServices) segments() *SegmentService {
+	return NewSegmentService(s.segmentRepo, s.userRepo, s.roleRepo, s.log)
+}
+
+// platformContext returns a context authenticated as an internal service,
+// which is trusted with every permission in every tenant
+func platformContext() context.Context {
+	return auth.WithIdentity(context.Background(), &auth.Identity{
+		UserID:    "test-service",
+		TenantIDs: []string{auth.AllTenants},
+	})
+}
+
+func assertStatus(t *testing.T, err error, statusCode int) {
+	t.Helper()
+	require.Error(t, err)
+	assert.Equal(t, statusCode, errors.FromError(err).StatusCode)
+}
+
+func TestUserService_CreateUser(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	profile, err := svc.CreateUser(ctx, &domain.CreateUserRequest{
+		Email:     "john@example.com",
+		TenantID:  "tenant-a",
+		FirstName: "  John  ",
+		LastName:  "Doe",
+		Password:  "correct-horse",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "John", profile.UserTenant.FirstName)
+	assert.Equal(t, []string{"user"}, profile.UserTenant.Roles)
+	assert.NotEmpty(t, profile.User.PasswordHash)
+	assert.NotEqual(t, "correct-horse", profile.User.PasswordHash)
+
+	_, err = svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "john@example.com", TenantID: "tenant-a"})
+	assertStatus(t, err, http.StatusConflict)
+
+	_, err = svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "not-an-email", TenantID: "tenant-a"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// The same email in another tenant links the existing global user
+	other, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "john@example.com", TenantID: "tenant-b", Roles: []string{"admin"}})
+	require.NoError(t, err)
+	assert.Equal(t, profile.User.ID, other.User.ID)
+	assert.Equal(t, []string{"admin"}, other.UserTenant.Roles)
+}
+
+func TestUserService_AddUserToTenant(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	profile, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "ann@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	userID := profile.User.ID.Hex()
+
+	_, err = svc.AddUserToTenant(ctx, userID, "tenant-a", nil, "")
+	assertStatus(t, err, http.StatusConflict)
+
+	ut, err := svc.AddUserToTenant(ctx, userID, "tenant-b", []string{"manager"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manager"}, ut.Roles)
+
+	// A removed membership is restored rather than duplicated
+	require.NoError(t, svc.RemoveUserFromTenant(ctx, userID, "tenant-b"))
+	ut, err = svc.AddUserToTenant(ctx, userID, "tenant-b", []string{"user"}, "")
+	require.NoError(t, err)
+	assert.True(t, ut.IsActive)
+	assert.Equal(t, []string{"user"}, ut.Roles)
+
+	tenants, err := svc.GetUserTenants(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, tenants, 2)
+
+	_, err = svc.AddUserToTenant(ctx, "507f1f77bcf86cd799439011", "tenant-a", nil, "")
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestUserService_GetUserByIdentifier(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	created, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "bob@example.com", Username: "bob", Password: "s3cret-pass", TenantID: "tenant-a"})
+	require.NoError(t, err)
+
+	user, tenants, err := svc.GetUserByIdentifier(ctx, "bob@example.com", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user.Username)
+	assert.Len(t, tenants, 1)
+	assert.Empty(t, user.PasswordHash)
+
+	credentials := auth.WithIdentity(context.Background(), &auth.Identity{
+		UserID:    "auth-service",
+		TenantIDs: []string{auth.AllTenants},
+		Roles:     []string{auth.RoleCredentialsReader},
+	})
+	user, _, err = svc.GetUserByIdentifier(credentials, "bob", "username", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, user.PasswordHash)
+
+	_, _, err = svc.GetUserByIdentifier(memberContext(created.User.ID.Hex(), "tenant-a", "owner"), "bob", "username", "tenant-a")
+	assertStatus(t, err, http.StatusForbidden)
+
+	_, _, err = svc.GetUserByIdentifier(context.Background(), "bob", "username", "")
+	assertStatus(t, err, http.StatusUnauthorized)
+
+	_, _, err = svc.GetUserByIdentifier(ctx, "bob", "username", "tenant-b")
+	assertStatus(t, err, http.StatusNotFound)
+
+	_, _, err = svc.GetUserByIdentifier(ctx, "bob", "nickname", "")
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestUserService_Memberships(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	owner, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "owner@example.com", TenantID: "tenant-a", Roles: []string{"owner"}})
+	require.NoError(t, err)
+	profile, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "kim@example.com", TenantID: "tenant-a"})
+	require.NoError(t, err)
+	userID := profile.User.ID.Hex()
+	_, err = svc.AddUserToTenant(ctx, userID, "tenant-b", []string{"manager"}, "")
+	require.NoError(t, err)
+
+	ownerCtx := memberContext(owner.User.ID.Hex(), "tenant-a")
+
+	ut, err := svc.UpdateUserTenantRoles(ownerCtx, userID, "tenant-a", []string{"manager"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manager"}, ut.Roles)
+
+	_, err = svc.UpdateUserTenantRoles(ownerCtx, userID, "tenant-a", nil)
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// The user sees every membership, a tenant owner only their own tenant's
+	tenants, err := svc.GetUserTenants(memberContext(userID, "tenant-a"), userID)
+	require.NoError(t, err)
+	assert.Len(t, tenants, 2)
+
+	tenants, err = svc.GetUserTenants(ownerCtx, userID)
+	require.NoError(t, err)
+	require.Len(t, tenants, 1)
+	assert.Equal(t, "tenant-a", tenants[0].TenantID)
+	assert.False(t, tenants[0].JoinedAt.IsZero())
+
+	// Removing needs users:delete in the membership's tenant
+	assertStatus(t, svc.RemoveUserFromTenant(ownerCtx, userID, "tenant-b"), http.StatusForbidden)
+	require.NoError(t, svc.RemoveUserFromTenant(ownerCtx, userID, "tenant-a"))
+
+	tenants, err = svc.GetUserTenants(ctx, userID)
+	require.NoError(t, err)
+	for _, ut := range tenants {
+		assert.Equal(t, ut.TenantID == "tenant-b", ut.IsActive)
+	}
+}
+
+func TestUserService_Me(t *testing.T) {
+	svc := newTestUserService(t)
+
+	profile, err := svc.CreateUser(platformContext(), &domain.CreateUserRequest{Email: "me@example.com", TenantID: "tenant-a", FirstName: "Old"})
+	require.NoError(t, err)
+	userID := profile.User.ID.Hex()
+	ctx := memberContext(userID, "tenant-a")
+
+	me, err := svc.GetMe(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, "me@example.com", me.User.Email)
+
+	me, err = svc.UpdateMe(ctx, "tenant-a", &domain.UpdateMeRequest{
+		FirstName: " Mai ",
+		Phone:     "+84901234567",
+		AvatarURL: "https://cdn.example.com/me.png",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Mai", me.UserTenant.FirstName)
+	assert.Equal(t, "+84901234567", me.User.Phone)
+	assert.Equal(t, []string{"user"}, me.UserTenant.Roles)
+
+	_, err = svc.UpdateMe(ctx, "tenant-a", &domain.UpdateMeRequest{AvatarURL: "javascript:alert(1)"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	tenants, err := svc.GetMyTenants(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tenants, 1)
+
+	_, err = svc.GetMe(context.Background(), "tenant-a")
+	assertStatus(t, err, http.StatusUnauthorized)
+
+	// Service tokens have no profile of their own
+	_, err = svc.GetMe(platformContext(), "tenant-a")
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestUserService_ListUsersAfter(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	for _, name := range []string{"An", "Binh", "Chi", "Dung", "Giang"} {
+		_, err := svc.CreateUser(ctx, &domain.CreateUserRequest{
+			Email:     strings.ToLower(name) + "@example.com",
+			TenantID:  "tenant-a",
+			FirstName: name,
+			LastName:  "Nguyen",
+		})
+		require.NoError(t, err)
+	}
+
+	first, err := svc.ListUsersAfter(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", PageSize: 2, IncludeTotal: true})
+	require.NoError(t, err)
+	require.Len(t, first.Users, 2)
+	assert.Equal(t, "giang@example.com", first.Users[0].User.Email)
+	require.NotNil(t, first.Total)
+	assert.EqualValues(t, 5, *first.Total)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := svc.ListUsersAfter(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", PageSize: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Users, 2)
+	assert.Equal(t, "chi@example.com", second.Users[0].User.Email)
+	assert.Nil(t, second.Total)
+
+	last, err := svc.ListUsersAfter(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", PageSize: 2, Cursor: second.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, last.Users, 1)
+	assert.Equal(t, "an@example.com", last.Users[0].User.Email)
+	assert.Empty(t, last.NextCursor)
+
+	// Search pages the same way, ignoring diacritics
+	found, err := svc.SearchUsersAfter(ctx, "tenant-a", "Nguyễn", "", "", 3, false)
+	require.NoError(t, err)
+	assert.Len(t, found.Users, 3)
+	found, err = svc.SearchUsersAfter(ctx, "tenant-a", "nguyen", "", found.NextCursor, 3, false)
+	require.NoError(t, err)
+	assert.Len(t, found.Users, 2)
+	assert.Empty(t, found.NextCursor)
+
+	_, err = svc.ListUsersAfter(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", PageSize: 2, Cursor: "not-a-cursor"})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// A cursor only continues the sort it was issued for
+	byName, err := svc.ListUsersAfter(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", PageSize: 2, Sort: "name"})
+	require.NoError(t, err)
+	assert.Equal(t, "An", byName.Users[0].UserTenant.FirstName)
+	byName, err = svc.ListUsersAfter(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", PageSize: 2, Sort: "name", Cursor: byName.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, "Chi", byName.Users[0].UserTenant.FirstName)
+	_, err = svc.ListUsersAfter(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", PageSize: 2, Sort: "-name", Cursor: byName.NextCursor})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestUserService_ListUsersFilterAndSort(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	for _, email := range []string{"lan@corp.vn", "hoa@example.com", "mai@corp.vn"} {
+		_, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: email, TenantID: "tenant-a"})
+		require.NoError(t, err)
+	}
+	_, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "boss@corp.vn", TenantID: "tenant-a", Roles: []string{"admin"}, Phone: "+84901234567"})
+	require.NoError(t, err)
+
+	users, total, err := svc.ListUsers(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", Filter: "emailDomain:CORP.vn, role:user", Sort: "email"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, users, 2)
+	assert.Equal(t, "lan@corp.vn", users[0].User.Email)
+	assert.Equal(t, "mai@corp.vn", users[1].User.Email)
+
+	users, _, err = svc.ListUsers(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", Filter: "hasPhone:true,joinedAt>=2020-01-01"})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "boss@corp.vn", users[0].User.Email)
+
+	for _, req := range []*domain.ListUsersRequest{
+		{TenantID: "tenant-a", Filter: "status:banned"},
+		{TenantID: "tenant-a", Filter: "isActive:yes"},
+		{TenantID: "tenant-a", Filter: "joinedAt:2024-01-01"},
+		{TenantID: "tenant-a", Filter: "role:admin,role:user"},
+		{TenantID: "tenant-a", Sort: "-phone"},
+	} {
+		_, _, err := svc.ListUsers(ctx, req)
+		assertStatus(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestUserService_SearchUsers(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	lan, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "lan@corp.vn", TenantID: "tenant-a", FirstName: "Lan", LastName: "Phạm", Phone: "+84901234567"})
+	require.NoError(t, err)
+	_, err = svc.CreateUser(ctx, &domain.CreateUserRequest{Email: "phamlan@example.com", TenantID: "tenant-a", FirstName: "Hoa", LastName: "Lê"})
+	require.NoError(t, err)
+
+	for _, query := range []string{"lan@corp.vn", "0901 234 567", lan.User.ID.Hex()} {
+		users, total, err := svc.SearchUsers(ctx, "tenant-a", query, "", 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total, query)
+		require.Len(t, users, 1)
+		assert.Equal(t, "lan@corp.vn", users[0].User.Email)
+	}
+
+	// The email prefix outranks the name match
+	users, _, err := svc.SearchUsers(ctx, "tenant-a", "pham", "", 1, 20)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "phamlan@example.com", users[0].User.Email)
+}
+
+func TestUserService_AutocompleteUsers(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	for i := 0; i < validation.MaxAutocompleteLimit+1; i++ {
+		_, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: fmt.Sprintf("user%d@example.com", i), TenantID: "tenant-a", FirstName: "Minh", LastName: "Nguyễn"})
+		require.NoError(t, err)
+	}
+
+	// A single character is enough, and the limit defaults and is capped
+	suggestions, err := svc.AutocompleteUsers(ctx, "tenant-a", "m", 0)
+	require.NoError(t, err)
+	assert.Len(t, suggestions, validation.DefaultAutocompleteLimit)
+	assert.Equal(t, "Minh Nguyễn", suggestions[0].DisplayName)
+
+	suggestions, err = svc.AutocompleteUsers(ctx, "tenant-a", "nguyen", 100)
+	require.NoError(t, err)
+	assert.Len(t, suggestions, validation.MaxAutocompleteLimit)
+
+	_, err = svc.AutocompleteUsers(ctx, "tenant-a", " ", 5)
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestUserService_CountUserFacets(t *testing.T) {
+	ctx := platformContext()
+	svc := newTestUserService(t)
+
+	for _, email := range []string{"lan@corp.vn", "hoa@corp.vn", "mai@example.com"} {
+		_, err := svc.CreateUser(ctx, &domain.CreateUserRequest{Email: email, TenantID: "tenant-a", FirstName: "Lan"})
+		require.NoError(t, err)
+	}
+
+	facets, err := svc.CountUserFacets(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", Filter: "emailDomain:corp.vn", Facets: "emailDomain,isActive"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.FacetCount{{Value: "corp.vn", Count: 2}}, facets[domain.UserFacetEmailDomain])
+	assert.Equal(t, []domain.FacetCount{{Value: "true", Count: 2}}, facets[domain.UserFacetIsActive])
+
+	facets, err = svc.CountUserFacets(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", Facets: "emailDomain"}, "mai@")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.FacetCount{{Value: "example.com", Count: 1}}, facets[domain.UserFacetEmailDomain])
+
+	// Facets are opt-in
+	facets, err = svc.CountUserFacets(ctx, &domain.ListUsersRequest{TenantID: "tenant-a"}, "")
+	require.NoError(t, err)
+	assert.Nil(t, facets)
+
+	_, err = svc.CountUserFacets(ctx, &domain.ListUsersRequest{TenantID: "tenant-a", Facets: "state"}, "")
+	assertStatus(t, err, http.StatusBadRequest)
+}
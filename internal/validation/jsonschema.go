@@ -0,0 +1,438 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSchemaSize is the largest encoded size accepted for a settings schema
+const maxSchemaSize = 64 * 1024
+
+// JSONSchema is a compiled JSON Schema
+// It supports the subset of draft 2020-12 needed to describe settings:
+// type, enum, const, properties, required, additionalProperties,
+// minProperties, maxProperties, items, minItems, maxItems, uniqueItems,
+// minLength, maxLength, pattern (RE2 syntax), minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum and multipleOf. Annotations such as
+// title and description are accepted and ignored; any other keyword is rejected
+// when the schema is compiled rather than silently skipped
+type JSONSchema struct {
+	rejectAll bool // The boolean schema false
+
+	types    []string
+	enum     []interface{}
+	constVal interface{}
+	hasConst bool
+
+	properties    map[string]*JSONSchema
+	required      []string
+	additional    *JSONSchema
+	minProperties *int
+	maxProperties *int
+
+	items       *JSONSchema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+}
+
+// SchemaViolation describes a value that does not match its schema
+type SchemaViolation struct {
+	Path    string `json:"path"` // JSON Pointer to the offending value, e.g. "/notifications/email"
+	Message string `json:"message"`
+}
+
+// schemaTypes are the type names understood by the "type" keyword
+var schemaTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true,
+	"number": true, "integer": true, "string": true,
+}
+
+// schemaAnnotations are keywords that carry no validation semantics
+var schemaAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "deprecated": true, "readOnly": true,
+	"writeOnly": true, "format": true,
+}
+
+// CompileJSONSchema compiles a JSON Schema document
+// The document must be plain JSON values, as produced by encoding/json
+func CompileJSONSchema(doc map[string]interface{}) (*JSONSchema, error) {
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("schema must be valid JSON")
+	}
+	if len(encoded) > maxSchemaSize {
+		return nil, fmt.Errorf("schema is too large (max %d bytes)", maxSchemaSize)
+	}
+	return compileSchema(doc, "")
+}
+
+func compileSchema(node interface{}, path string) (*JSONSchema, error) {
+	switch v := node.(type) {
+	case bool:
+		return &JSONSchema{rejectAll: !v}, nil
+	case map[string]interface{}:
+		return compileSchemaObject(v, path)
+	}
+	return nil, schemaError(path, "a schema must be an object or a boolean")
+}
+
+func compileSchemaObject(doc map[string]interface{}, path string) (*JSONSchema, error) {
+	s := &JSONSchema{}
+
+	// Compile keywords in a stable order so errors are deterministic
+	keywords := make([]string, 0, len(doc))
+	for k := range doc {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		value := doc[keyword]
+		at := path + "/" + keyword
+		var err error
+		switch keyword {
+		case "type":
+			s.types, err = compileTypes(value, at)
+		case "enum":
+			values, ok := value.([]interface{})
+			if !ok || len(values) == 0 {
+				err = schemaError(at, "enum must be a non-empty array")
+			}
+			s.enum = values
+		case "const":
+			s.constVal, s.hasConst = value, true
+		case "properties":
+			props, ok := value.(map[string]interface{})
+			if !ok {
+				err = schemaError(at, "properties must be an object")
+				break
+			}
+			s.properties = make(map[string]*JSONSchema, len(props))
+			for name, sub := range props {
+				if s.properties[name], err = compileSchema(sub, at+"/"+escapePointer(name)); err != nil {
+					break
+				}
+			}
+		case "required":
+			s.required, err = compileStrings(value, at)
+		case "additionalProperties":
+			s.additional, err = compileSchema(value, at)
+		case "items":
+			s.items, err = compileSchema(value, at)
+		case "uniqueItems":
+			b, ok := value.(bool)
+			if !ok {
+				err = schemaError(at, "uniqueItems must be a boolean")
+			}
+			s.uniqueItems = b
+		case "pattern":
+			str, ok := value.(string)
+			if !ok {
+				err = schemaError(at, "pattern must be a string")
+				break
+			}
+			if s.pattern, err = regexp.Compile(str); err != nil {
+				err = schemaError(at, fmt.Sprintf("pattern is not a valid regular expression: %v", err))
+			}
+		case "minProperties":
+			s.minProperties, err = compileCount(value, at)
+		case "maxProperties":
+			s.maxProperties, err = compileCount(value, at)
+		case "minItems":
+			s.minItems, err = compileCount(value, at)
+		case "maxItems":
+			s.maxItems, err = compileCount(value, at)
+		case "minLength":
+			s.minLength, err = compileCount(value, at)
+		case "maxLength":
+			s.maxLength, err = compileCount(value, at)
+		case "minimum":
+			s.minimum, err = compileNumber(value, at)
+		case "maximum":
+			s.maximum, err = compileNumber(value, at)
+		case "exclusiveMinimum":
+			s.exclusiveMinimum, err = compileNumber(value, at)
+		case "exclusiveMaximum":
+			s.exclusiveMaximum, err = compileNumber(value, at)
+		case "multipleOf":
+			s.multipleOf, err = compileNumber(value, at)
+			if err == nil && *s.multipleOf <= 0 {
+				err = schemaError(at, "multipleOf must be greater than 0")
+			}
+		default:
+			if !schemaAnnotations[keyword] {
+				err = schemaError(at, fmt.Sprintf("unsupported keyword %q", keyword))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func compileTypes(value interface{}, path string) ([]string, error) {
+	var types []string
+	switch v := value.(type) {
+	case string:
+		types = []string{v}
+	case []interface{}:
+		var err error
+		if types, err = compileStrings(v, path); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, schemaError(path, "type must be a string or an array of strings")
+	}
+	for _, t := range types {
+		if !schemaTypes[t] {
+			return nil, schemaError(path, fmt.Sprintf("unknown type %q", t))
+		}
+	}
+	return types, nil
+}
+
+func compileStrings(value interface{}, path string) ([]string, error) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, schemaError(path, "must be an array of strings")
+	}
+	result := make([]string, len(values))
+	for i, v := range values {
+		if result[i], ok = v.(string); !ok {
+			return nil, schemaError(path, "must be an array of strings")
+		}
+	}
+	return result, nil
+}
+
+func compileCount(value interface{}, path string) (*int, error) {
+	n, ok := value.(float64)
+	if !ok || n < 0 || n != math.Trunc(n) {
+		return nil, schemaError(path, "must be a non-negative integer")
+	}
+	count := int(n)
+	return &count, nil
+}
+
+func compileNumber(value interface{}, path string) (*float64, error) {
+	n, ok := value.(float64)
+	if !ok {
+		return nil, schemaError(path, "must be a number")
+	}
+	return &n, nil
+}
+
+func schemaError(path, message string) error {
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Errorf("schema at %q: %s", path, message)
+}
+
+// Validate checks value against the schema and returns every violation
+// path is the JSON Pointer of value, used as the prefix of violation paths
+func (s *JSONSchema) Validate(value interface{}, path string) []SchemaViolation {
+	var violations []SchemaViolation
+	s.validate(value, path, &violations)
+	return violations
+}
+
+func (s *JSONSchema) validate(value interface{}, path string, out *[]SchemaViolation) {
+	report := func(format string, args ...interface{}) {
+		p := path
+		if p == "" {
+			p = "/"
+		}
+		*out = append(*out, SchemaViolation{Path: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.rejectAll {
+		report("value is not allowed")
+		return
+	}
+
+	if len(s.types) > 0 && !matchesType(value, s.types) {
+		report("must be of type %s, got %s", strings.Join(s.types, " or "), jsonType(value))
+		return
+	}
+
+	if s.hasConst && !jsonEqual(value, s.constVal) {
+		report("must be %s", encodeJSON(s.constVal))
+	}
+
+	if s.enum != nil {
+		found := false
+		for _, e := range s.enum {
+			if jsonEqual(value, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report("must be one of %s", encodeJSON(s.enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, path, out, report)
+	case []interface{}:
+		s.validateArray(v, path, out, report)
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			report("must be at least %d characters", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			report("must be at most %d characters", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("must match pattern %q", s.pattern.String())
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			report("must be >= %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			report("must be <= %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+			report("must be > %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+			report("must be < %v", *s.exclusiveMaximum)
+		}
+		if s.multipleOf != nil {
+			if q := v / *s.multipleOf; q != math.Trunc(q) {
+				report("must be a multiple of %v", *s.multipleOf)
+			}
+		}
+	}
+}
+
+func (s *JSONSchema) validateObject(v map[string]interface{}, path string, out *[]SchemaViolation, report func(string, ...interface{})) {
+	for _, name := range s.required {
+		if _, ok := v[name]; !ok {
+			report("missing required property %q", name)
+		}
+	}
+	if s.minProperties != nil && len(v) < *s.minProperties {
+		report("must have at least %d properties", *s.minProperties)
+	}
+	if s.maxProperties != nil && len(v) > *s.maxProperties {
+		report("must have at most %d properties", *s.maxProperties)
+	}
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		at := path + "/" + escapePointer(name)
+		if sub, ok := s.properties[name]; ok {
+			sub.validate(v[name], at, out)
+			continue
+		}
+		if s.additional != nil {
+			if s.additional.rejectAll {
+				*out = append(*out, SchemaViolation{Path: at, Message: "property is not allowed"})
+				continue
+			}
+			s.additional.validate(v[name], at, out)
+		}
+	}
+}
+
+func (s *JSONSchema) validateArray(v []interface{}, path string, out *[]SchemaViolation, report func(string, ...interface{})) {
+	if s.minItems != nil && len(v) < *s.minItems {
+		report("must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(v) > *s.maxItems {
+		report("must have at most %d items", *s.maxItems)
+	}
+	if s.uniqueItems {
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if jsonEqual(v[i], v[j]) {
+					report("items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+	if s.items != nil {
+		for i, item := range v {
+			s.items.validate(item, fmt.Sprintf("%s/%d", path, i), out)
+		}
+	}
+}
+
+func matchesType(value interface{}, types []string) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func encodeJSON(v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
+
+// escapePointer escapes a key for use as a JSON Pointer segment (RFC 6901)
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func mustJSON(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatalf("invalid JSON %s: %v", raw, err)
+	}
+	return doc
+}
+
+func TestCompileJSONSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{
+			name:    "object schema",
+			schema:  `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"Notifications","type":"object","properties":{"email":{"type":"boolean"}},"additionalProperties":false}`,
+			wantErr: false,
+		},
+		{
+			name:    "type list",
+			schema:  `{"type":["string","null"],"maxLength":10}`,
+			wantErr: false,
+		},
+		{
+			name:    "unknown type",
+			schema:  `{"type":"date"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported keyword",
+			schema:  `{"oneOf":[{"type":"string"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid nested schema",
+			schema:  `{"properties":{"email":{"minLength":-1}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			schema:  `{"pattern":"(unclosed"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileJSONSchema(mustJSON(t, tt.schema))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompileJSONSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema, err := CompileJSONSchema(mustJSON(t, `{
+		"type": "object",
+		"required": ["email"],
+		"properties": {
+			"email": {"type": "boolean"},
+			"digest": {"enum": ["daily", "weekly"]},
+			"quiet_hours": {"type": "integer", "minimum": 0, "maximum": 23},
+			"channels": {"type": "array", "items": {"type": "string", "minLength": 2}, "uniqueItems": true}
+		},
+		"additionalProperties": false
+	}`))
+	if err != nil {
+		t.Fatalf("CompileJSONSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  []SchemaViolation
+	}{
+		{
+			name:  "valid",
+			value: `{"email": true, "digest": "daily", "quiet_hours": 22, "channels": ["sms", "push"]}`,
+			want:  nil,
+		},
+		{
+			name:  "wrong type at root",
+			value: `{"x": []}`,
+			want: []SchemaViolation{
+				{Path: "/settings/notifications", Message: `missing required property "email"`},
+				{Path: "/settings/notifications/x", Message: "property is not allowed"},
+			},
+		},
+		{
+			name:  "nested violations",
+			value: `{"email": "yes", "digest": "hourly", "quiet_hours": 24.5, "channels": ["a", "sms", "sms"]}`,
+			want: []SchemaViolation{
+				{Path: "/settings/notifications/channels", Message: "items 1 and 2 are equal"},
+				{Path: "/settings/notifications/channels/0", Message: "must be at least 2 characters"},
+				{Path: "/settings/notifications/digest", Message: `must be one of ["daily","weekly"]`},
+				{Path: "/settings/notifications/email", Message: "must be of type boolean, got string"},
+				{Path: "/settings/notifications/quiet_hours", Message: "must be of type integer, got number"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schema.Validate(mustJSON(t, tt.value), "/settings/notifications")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeSearchText folds text for diacritic-insensitive matching: it lower
+// cases it, strips combining marks (so "Nguyễn Văn" becomes "nguyen van"),
+// folds đ/Đ to d and collapses everything but letters and digits to single spaces
+func NormalizeSearchText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == 'đ' || r == 'Đ' {
+			return 'd'
+		}
+		return r
+	}, s)
+
+	// Transformers keep state, so each call builds its own chain
+	stripMarks := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if folded, _, err := transform.String(stripMarks, s); err == nil {
+		s = folded
+	}
+
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Kinds of search query told apart by DetectSearchKind
+const (
+	SearchKindID    = "id"
+	SearchKindEmail = "email"
+	SearchKindPhone = "phone"
+	SearchKindText  = "text"
+)
+
+var (
+	objectIDRegex   = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+	phoneQueryRegex = regexp.MustCompile(`^\+?\(?[0-9][0-9 ().-]*$`)
+)
+
+// minPhoneFragment is the fewest digits searched as a phone number
+const minPhoneFragment = 3
+
+// DetectSearchKind tells what a search query looks like: a user ID (24 hex
+// digits), an email or email fragment (contains '@'), a full or partial phone
+// number (digits with an optional leading '+' and spaces, dots, dashes or
+// parentheses, at least 3 digits) or free text
+func DetectSearchKind(query string) string {
+	query = strings.TrimSpace(query)
+	switch {
+	case objectIDRegex.MatchString(query):
+		return SearchKindID
+	case strings.Contains(query, "@"):
+		return SearchKindEmail
+	case phoneQueryRegex.MatchString(query) && len(PhoneDigits(query)) >= minPhoneFragment:
+		return SearchKindPhone
+	}
+	return SearchKindText
+}
+
+// PhoneDigits returns the digits of a phone number or fragment
+func PhoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+}
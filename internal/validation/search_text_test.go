@@ -0,0 +1,51 @@
+package validation
+
+import "testing"
+
+func TestNormalizeSearchText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "Nguyễn Văn An", want: "nguyen van an"},
+		{input: "ĐẶNG Thị Đào", want: "dang thi dao"},
+		{input: "  Lê--Hoàng   Phúc ", want: "le hoang phuc"},
+		{input: "Trần Thị Ngọc Ánh", want: "tran thi ngoc anh"},
+		{input: "José O'Neil", want: "jose o neil"},
+		{input: "!!", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NormalizeSearchText(tt.input); got != tt.want {
+				t.Errorf("NormalizeSearchText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectSearchKind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "65a1b2c3d4e5f6a7b8c9d0e1", want: SearchKindID},
+		{query: "an@example.com", want: SearchKindEmail},
+		{query: "@corp.vn", want: SearchKindEmail},
+		{query: "+84901234567", want: SearchKindPhone},
+		{query: "(090) 123-4567", want: SearchKindPhone},
+		{query: "4567", want: SearchKindPhone},
+		{query: "45", want: SearchKindText},
+		{query: "Nguyễn Văn An", want: SearchKindText},
+		{query: "65a1b2c3", want: SearchKindText},
+		{query: "+84 abc", want: SearchKindText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := DetectSearchKind(tt.query); got != tt.want {
+				t.Errorf("DetectSearchKind(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+var (
+	filterFieldRegex = regexp.MustCompile(`^([a-zA-Z]+)(>=|<=|>|<|:)(.*)$`)
+	emailDomainRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+)
+
+// maxFilterLength is the longest filter expression accepted
+const maxFilterLength = 1024
+
+// Filter fields accepted by ParseUserFilter
+const (
+	FilterIsActive    = "isActive"
+	FilterStatus      = "status"
+	FilterRole        = "role"
+	FilterTag         = "tag"
+	FilterJoinedAt    = "joinedAt"
+	FilterCreatedAt   = "createdAt"
+	FilterExpiresAt   = "expiresAt"
+	FilterEmailDomain = "emailDomain"
+	FilterHasPhone    = "hasPhone"
+)
+
+// ParseUserFilter parses a user listing filter: comma separated clauses of a
+// field, an operator and a value, e.g.
+//
+//	isActive:true,role:admin,tag:vip,joinedAt>=2024-01-01,emailDomain:example.com
+//
+// isActive, status, role, tag, emailDomain and hasPhone take ":"; joinedAt,
+// createdAt and expiresAt take >, >=, < and <= with an RFC 3339 timestamp or a
+// YYYY-MM-DD date (UTC)
+func ParseUserFilter(expr string) (domain.UserFilter, error) {
+	var filter domain.UserFilter
+	if strings.TrimSpace(expr) == "" {
+		return filter, nil
+	}
+	if len(expr) > maxFilterLength {
+		return filter, fmt.Errorf("filter is too long (max %d characters)", maxFilterLength)
+	}
+
+	seen := make(map[string]bool)
+	for _, clause := range strings.Split(expr, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			return filter, fmt.Errorf("filter contains an empty clause")
+		}
+		m := filterFieldRegex.FindStringSubmatch(clause)
+		if m == nil {
+			return filter, fmt.Errorf("filter clause %q must be <field><operator><value>, e.g. isActive:true", clause)
+		}
+		field, op, value := m[1], m[2], strings.TrimSpace(m[3])
+		if value == "" {
+			return filter, fmt.Errorf("filter field %q has no value", field)
+		}
+
+		key := field
+		if field == FilterJoinedAt || field == FilterCreatedAt || field == FilterExpiresAt {
+			key = field + op
+		}
+		if seen[key] {
+			return filter, fmt.Errorf("filter field %q is given more than once", field+strings.TrimPrefix(op, ":"))
+		}
+		seen[key] = true
+
+		var err error
+		switch field {
+		case FilterIsActive:
+			filter.IsActive, err = parseFilterBool(field, op, value)
+		case FilterHasPhone:
+			filter.HasPhone, err = parseFilterBool(field, op, value)
+		case FilterStatus:
+			filter.Status = domain.MembershipStatus(strings.ToLower(value))
+			if err = requireEquality(field, op); err == nil && !filter.Status.Valid() {
+				err = fmt.Errorf("filter field %q has an unknown status %q", field, value)
+			}
+		case FilterRole:
+			if err = requireEquality(field, op); err == nil && !roleRegex.MatchString(value) {
+				err = fmt.Errorf("filter field %q has an invalid role %q", field, value)
+			}
+			filter.Role = value
+		case FilterTag:
+			value = strings.ToLower(value)
+			if err = requireEquality(field, op); err == nil && !tagRegex.MatchString(value) {
+				err = fmt.Errorf("filter field %q has an invalid tag %q", field, value)
+			}
+			filter.Tag = value
+		case FilterEmailDomain:
+			value = strings.ToLower(strings.TrimPrefix(value, "@"))
+			if err = requireEquality(field, op); err == nil && (len(value) > 253 || !emailDomainRegex.MatchString(value)) {
+				err = fmt.Errorf("filter field %q has an invalid domain %q", field, value)
+			}
+			filter.EmailDomain = value
+		case FilterJoinedAt:
+			err = parseFilterTime(&filter.JoinedAt, field, op, value)
+		case FilterCreatedAt:
+			err = parseFilterTime(&filter.CreatedAt, field, op, value)
+		case FilterExpiresAt:
+			err = parseFilterTime(&filter.ExpiresAt, field, op, value)
+		default:
+			err = fmt.Errorf("unknown filter field %q (supported: %s, %s, %s, %s, %s, %s, %s, %s, %s)", field,
+				FilterIsActive, FilterStatus, FilterRole, FilterTag, FilterJoinedAt, FilterCreatedAt, FilterExpiresAt, FilterEmailDomain, FilterHasPhone)
+		}
+		if err != nil {
+			return domain.UserFilter{}, err
+		}
+	}
+
+	return filter, nil
+}
+
+// ParseUserSort parses a user listing sort: a field, prefixed with "-" for
+// descending order. An empty sort lists the newest members first
+func ParseUserSort(expr string) (domain.UserSort, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return domain.DefaultUserSort, nil
+	}
+
+	sort := domain.UserSort{Field: strings.TrimPrefix(expr, "-"), Desc: strings.HasPrefix(expr, "-")}
+	switch sort.Field {
+	case domain.UserSortName, domain.UserSortEmail, domain.UserSortJoinedAt, domain.UserSortCreatedAt:
+		return sort, nil
+	}
+	return domain.UserSort{}, fmt.Errorf("unknown sort field %q (supported: %s, %s, %s, %s; prefix with '-' for descending)", sort.Field,
+		domain.UserSortName, domain.UserSortEmail, domain.UserSortJoinedAt, domain.UserSortCreatedAt)
+}
+
+// ParseUserFacets parses a comma separated list of facets to count, e.g.
+// "role,isActive,status,tag,joinedMonth,emailDomain". An empty list counts none
+func ParseUserFacets(expr string) ([]string, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	var facets []string
+	seen := make(map[string]bool)
+	for _, facet := range strings.Split(expr, ",") {
+		facet = strings.TrimSpace(facet)
+		switch facet {
+		case domain.UserFacetRole, domain.UserFacetIsActive, domain.UserFacetStatus, domain.UserFacetTag, domain.UserFacetJoinedMonth, domain.UserFacetEmailDomain:
+		default:
+			return nil, fmt.Errorf("unknown facet %q (supported: %s, %s, %s, %s, %s, %s)", facet,
+				domain.UserFacetRole, domain.UserFacetIsActive, domain.UserFacetStatus, domain.UserFacetTag, domain.UserFacetJoinedMonth, domain.UserFacetEmailDomain)
+		}
+		if seen[facet] {
+			return nil, fmt.Errorf("facet %q is given more than once", facet)
+		}
+		seen[facet] = true
+		facets = append(facets, facet)
+	}
+	return facets, nil
+}
+
+func requireEquality(field, op string) error {
+	if op != ":" {
+		return fmt.Errorf("filter field %q only supports ':'", field)
+	}
+	return nil
+}
+
+func parseFilterBool(field, op, value string) (*bool, error) {
+	if err := requireEquality(field, op); err != nil {
+		return nil, err
+	}
+	switch value {
+	case "true":
+		b := true
+		return &b, nil
+	case "false":
+		b := false
+		return &b, nil
+	}
+	return nil, fmt.Errorf("filter field %q must be true or false", field)
+}
+
+func parseFilterTime(r *domain.TimeRange, field, op, value string) error {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", value)
+	}
+	if err != nil {
+		return fmt.Errorf("filter field %q must be an RFC 3339 timestamp or a YYYY-MM-DD date", field)
+	}
+
+	switch op {
+	case ">":
+		r.Gt = &t
+	case ">=":
+		r.Gte = &t
+	case "<":
+		r.Lt = &t
+	case "<=":
+		r.Lte = &t
+	default:
+		return fmt.Errorf("filter field %q only supports >, >=, < and <=", field)
+	}
+	return nil
+}
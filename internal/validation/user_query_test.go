@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+)
+
+func TestParseUserFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{name: "empty", expr: ""},
+		{name: "every field", expr: "isActive:true,status:Suspended,role:billing:read,joinedAt>=2024-01-01,joinedAt<2025-01-01T00:00:00Z,createdAt>2023-06-01,expiresAt<=2026-12-31,emailDomain:@Example.COM,hasPhone:false"},
+		{name: "spaces around clauses", expr: " isActive:false , hasPhone:true "},
+		{name: "unknown field", expr: "state:active", wantErr: `unknown filter field "state"`},
+		{name: "empty clause", expr: "isActive:true,,role:admin", wantErr: "empty clause"},
+		{name: "no operator", expr: "isActive", wantErr: "must be <field><operator><value>"},
+		{name: "missing value", expr: "role:", wantErr: "has no value"},
+		{name: "range on boolean", expr: "isActive>true", wantErr: "only supports ':'"},
+		{name: "equality on date", expr: "joinedAt:2024-01-01", wantErr: "only supports >, >=, < and <="},
+		{name: "bad boolean", expr: "hasPhone:1", wantErr: "must be true or false"},
+		{name: "bad date", expr: "createdAt<yesterday", wantErr: "RFC 3339"},
+		{name: "bad status", expr: "status:banned", wantErr: "unknown status"},
+		{name: "bad role", expr: "role:a b", wantErr: "invalid role"},
+		{name: "bad tag", expr: "tag:-vip", wantErr: "invalid tag"},
+		{name: "bad domain", expr: "emailDomain:localhost", wantErr: "invalid domain"},
+		{name: "duplicate field", expr: "role:admin,role:user", wantErr: "more than once"},
+		{name: "duplicate bound", expr: "joinedAt>=2024-01-01,joinedAt>=2024-02-01", wantErr: "more than once"},
+		{name: "too long", expr: "role:" + strings.Repeat("a", maxFilterLength), wantErr: "too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseUserFilter(tt.expr)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ParseUserFilter() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseUserFilter() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	filter, err := ParseUserFilter("isActive:true,role:admin,tag:Team:Backend,joinedAt>=2024-01-01,joinedAt<2024-02-01,emailDomain:Corp.VN")
+	if err != nil {
+		t.Fatalf("ParseUserFilter() error = %v", err)
+	}
+	if filter.IsActive == nil || !*filter.IsActive || filter.Role != "admin" || filter.Tag != "team:backend" || filter.EmailDomain != "corp.vn" {
+		t.Errorf("ParseUserFilter() = %+v", filter)
+	}
+	if !filter.JoinedAt.Contains(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) || filter.JoinedAt.Contains(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseUserFilter() joinedAt range = %+v", filter.JoinedAt)
+	}
+}
+
+func TestParseUserSort(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    domain.UserSort
+		wantErr bool
+	}{
+		{expr: "", want: domain.DefaultUserSort},
+		{expr: "name", want: domain.UserSort{Field: domain.UserSortName}},
+		{expr: "-email", want: domain.UserSort{Field: domain.UserSortEmail, Desc: true}},
+		{expr: "createdAt", want: domain.UserSort{Field: domain.UserSortCreatedAt}},
+		{expr: "phone", wantErr: true},
+		{expr: "--name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseUserSort(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUserSort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseUserSort() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUserFacets(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    []string
+		wantErr string
+	}{
+		{expr: ""},
+		{expr: "role, joinedMonth", want: []string{domain.UserFacetRole, domain.UserFacetJoinedMonth}},
+		{expr: "role,isActive,status,tag,joinedMonth,emailDomain", want: []string{"role", "isActive", "status", "tag", "joinedMonth", "emailDomain"}},
+		{expr: "state", wantErr: `unknown facet "state"`},
+		{expr: "role,", wantErr: `unknown facet ""`},
+		{expr: "role,role", wantErr: "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseUserFacets(tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseUserFacets() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseUserFacets() error = %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseUserFacets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,37 @@
 package validation
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
+	"time"
+	_ "time/tzdata" // Validate time zones without relying on the host's zoneinfo
 	"unicode"
+	"unicode/utf8"
+
+	"github.com/vhvplatform/go-user-service/internal/domain"
+	"golang.org/x/text/language"
 )
 
 var (
-	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	phoneRegex = regexp.MustCompile(`^\+?[1-9]\d{5,13}$`) // E.164 format: 7-15 digits total (first digit 1-9, then 5-13 more)
+	emailRegex          = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	phoneRegex          = regexp.MustCompile(`^\+?[1-9]\d{5,13}$`) // E.164 format: 7-15 digits total (first digit 1-9, then 5-13 more)
+	usernameRegex       = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{2,63}$`)
+	documentNumberRegex = regexp.MustCompile(`^[a-zA-Z0-9-]{4,32}$`)
+	roleRegex           = regexp.MustCompile(`^[a-zA-Z0-9:_-]{1,64}$`)
+	segmentKeyRegex     = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+	tagRegex            = regexp.MustCompile(`^[a-z0-9][a-z0-9:._-]{0,63}$`)
+	namespaceRegex      = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+)
+
+// Identifier types accepted by GetUserByIdentifier
+const (
+	IdentifierEmail          = "email"
+	IdentifierUsername       = "username"
+	IdentifierPhone          = "phone"
+	IdentifierDocumentNumber = "document_number"
 )
 
 // ValidateEmail validates email format
@@ -61,6 +83,360 @@ func ValidatePhone(phone string) error {
 	return nil
 }
 
+// ValidateAvatarURL validates an avatar URL (absolute http or https URL)
+func ValidateAvatarURL(avatarURL string) error {
+	if avatarURL == "" {
+		return nil // Avatar is optional
+	}
+	if len(avatarURL) > 2048 {
+		return fmt.Errorf("avatar_url is too long (max 2048 characters)")
+	}
+
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("avatar_url must be an absolute http or https URL")
+	}
+
+	return nil
+}
+
+// ValidateUsername validates username format
+func ValidateUsername(username string) error {
+	if username == "" {
+		return nil // Username is optional
+	}
+
+	if !usernameRegex.MatchString(username) {
+		return fmt.Errorf("username must be 3-64 characters of letters, digits, '.', '_' or '-' and start with a letter or digit")
+	}
+
+	return nil
+}
+
+// ValidateDocumentNumber validates an identity document number (national ID, passport, ...)
+func ValidateDocumentNumber(documentNumber string) error {
+	if documentNumber == "" {
+		return nil // Document number is optional
+	}
+
+	if !documentNumberRegex.MatchString(documentNumber) {
+		return fmt.Errorf("document_number must be 4-32 characters of letters, digits or '-'")
+	}
+
+	return nil
+}
+
+// ValidatePassword validates password strength
+// bcrypt only uses the first 72 bytes, so longer passwords are rejected
+func ValidatePassword(password string) error {
+	if password == "" {
+		return nil // Password is optional (e.g. SSO-only users)
+	}
+	if len(password) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+	if len(password) > 72 {
+		return fmt.Errorf("password is too long (max 72 bytes)")
+	}
+
+	return nil
+}
+
+// ValidateRoles validates a list of role names
+func ValidateRoles(roles []string) error {
+	if len(roles) > 20 {
+		return fmt.Errorf("too many roles (max 20)")
+	}
+	for _, role := range roles {
+		if !roleRegex.MatchString(role) {
+			return fmt.Errorf("invalid role %q", role)
+		}
+	}
+
+	return nil
+}
+
+// ValidateRoleKey validates the key a custom role is assigned by
+func ValidateRoleKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("role key is required")
+	}
+	if !roleRegex.MatchString(key) {
+		return fmt.Errorf("role key may only contain letters, digits, ':', '_' and '-' (max 64)")
+	}
+
+	return nil
+}
+
+// ValidateRoleDisplayName validates a role's human readable name, e.g. "Kế toán"
+func ValidateRoleDisplayName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("display_name is required")
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return fmt.Errorf("display_name must be between 1 and 100 characters")
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) || r == '<' || r == '>' {
+			return fmt.Errorf("display_name contains invalid characters")
+		}
+	}
+
+	return nil
+}
+
+// ValidateRoleDescription validates a role's description
+func ValidateRoleDescription(description string) error {
+	if utf8.RuneCountInString(description) > 500 {
+		return fmt.Errorf("description is too long (max 500 characters)")
+	}
+
+	return nil
+}
+
+// ValidateStatusReason validates the reason given for a membership status change
+func ValidateStatusReason(reason string) error {
+	if utf8.RuneCountInString(reason) > 500 {
+		return fmt.Errorf("reason is too long (max 500 characters)")
+	}
+
+	return nil
+}
+
+// ValidateRetentionDays validates a tenant's retention window for removed
+// memberships; zero falls back to the service default
+func ValidateRetentionDays(days int) error {
+	if days < 0 || days > domain.MaxRetentionDays {
+		return fmt.Errorf("retention_days must be between 0 and %d", domain.MaxRetentionDays)
+	}
+
+	return nil
+}
+
+// ValidateInactivityDays validates a tenant's inactivity thresholds; zero
+// disables a step, and members must be warned before they are suspended
+func ValidateInactivityDays(warnDays, suspendDays int) error {
+	if warnDays < 0 || warnDays > domain.MaxInactivityDays {
+		return fmt.Errorf("inactivity_warn_days must be between 0 and %d", domain.MaxInactivityDays)
+	}
+	if suspendDays < 0 || suspendDays > domain.MaxInactivityDays {
+		return fmt.Errorf("inactivity_suspend_days must be between 0 and %d", domain.MaxInactivityDays)
+	}
+	if warnDays > 0 && suspendDays > 0 && warnDays >= suspendDays {
+		return fmt.Errorf("inactivity_warn_days must be less than inactivity_suspend_days")
+	}
+
+	return nil
+}
+
+// ValidateExportFormat validates the format of a user data export
+func ValidateExportFormat(format string) error {
+	if format != domain.ExportFormatJSON && format != domain.ExportFormatZIP {
+		return fmt.Errorf("format must be %s or %s", domain.ExportFormatJSON, domain.ExportFormatZIP)
+	}
+
+	return nil
+}
+
+// ParseExpiresAt parses a membership expiry, an RFC 3339 timestamp after now.
+// An empty value is no expiry and returns nil
+func ParseExpiresAt(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("expires_at must be an RFC 3339 timestamp")
+	}
+	if !t.After(now) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// ValidateExpiringDays validates how many days ahead expiring memberships are listed
+func ValidateExpiringDays(days int) error {
+	if days < 1 || days > domain.MaxExpiringDays {
+		return fmt.Errorf("days must be between 1 and %d", domain.MaxExpiringDays)
+	}
+
+	return nil
+}
+
+// ValidateSegmentKey validates the key a segment is addressed by
+func ValidateSegmentKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("segment key is required")
+	}
+	if !segmentKeyRegex.MatchString(key) {
+		return fmt.Errorf("segment key may only contain letters, digits, '_' and '-' (max 64)")
+	}
+
+	return nil
+}
+
+// NormalizeTags lower-cases, validates and de-duplicates membership tags,
+// keeping their order. Tags start with a letter or digit and may contain
+// ':', '.', '_' and '-', e.g. "vip" or "team:backend"
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("at least one tag is required")
+	}
+	if len(tags) > 20 {
+		return nil, fmt.Errorf("too many tags (max 20)")
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagRegex.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: use letters, digits, ':', '.', '_' and '-' (max 64)", tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// ValidateSegmentName validates a segment's human readable name
+func ValidateSegmentName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return fmt.Errorf("name must be between 1 and 100 characters")
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) || r == '<' || r == '>' {
+			return fmt.Errorf("name contains invalid characters")
+		}
+	}
+
+	return nil
+}
+
+// Supported UI themes
+const (
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+	ThemeSystem = "system"
+)
+
+// maxSettingsSize is the largest encoded size accepted for preference settings
+const maxSettingsSize = 16 * 1024
+
+// ValidateLanguage validates a BCP 47 language tag and returns its canonical form
+// (e.g. "vi-vn" becomes "vi-VN")
+func ValidateLanguage(tag string) (string, error) {
+	parsed, err := language.Parse(tag)
+	if err != nil || parsed == language.Und {
+		return "", fmt.Errorf("language %q is not a valid BCP 47 tag", tag)
+	}
+
+	return parsed.String(), nil
+}
+
+// ValidateTimezone validates an IANA time zone name such as "Asia/Ho_Chi_Minh"
+func ValidateTimezone(tz string) error {
+	if tz == "" || tz == "Local" {
+		return fmt.Errorf("timezone %q is not a valid IANA time zone", tz)
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("timezone %q is not a valid IANA time zone", tz)
+	}
+
+	return nil
+}
+
+// ValidateTheme validates a UI theme
+func ValidateTheme(theme string) error {
+	switch theme {
+	case ThemeLight, ThemeDark, ThemeSystem:
+		return nil
+	}
+	return fmt.Errorf("theme must be one of %s, %s or %s", ThemeLight, ThemeDark, ThemeSystem)
+}
+
+// ValidateSettings validates free-form preference settings
+// Keys must be storable in MongoDB and the encoded document is limited to 16 KiB
+func ValidateSettings(settings map[string]interface{}) error {
+	if err := validateSettingKeys(settings, ""); err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("settings must be valid JSON")
+	}
+	if len(encoded) > maxSettingsSize {
+		return fmt.Errorf("settings are too large (max %d bytes)", maxSettingsSize)
+	}
+
+	return nil
+}
+
+// ValidateSettingsNamespace validates the name of a top-level settings namespace
+func ValidateSettingsNamespace(namespace string) error {
+	if namespace == "" {
+		return fmt.Errorf("namespace is required")
+	}
+	if !namespaceRegex.MatchString(namespace) {
+		return fmt.Errorf("namespace may only contain letters, digits, '_' and '-' (max 64)")
+	}
+
+	return nil
+}
+
+// ValidatePreferencePath validates a path naming a preference, as used for
+// tenant locks: "language", "timezone", "theme" or "settings.<key>[.<key>...]"
+func ValidatePreferencePath(path string) error {
+	switch path {
+	case "language", "timezone", "theme":
+		return nil
+	}
+	invalid := fmt.Errorf("preference path %q must be language, timezone, theme or settings.<key>", path)
+	keys, ok := strings.CutPrefix(path, "settings.")
+	if !ok {
+		return invalid
+	}
+	for _, key := range strings.Split(keys, ".") {
+		if key == "" || strings.HasPrefix(key, "$") {
+			return invalid
+		}
+	}
+	return nil
+}
+
+func validateSettingKeys(settings map[string]interface{}, path string) error {
+	for key, value := range settings {
+		if key == "" || strings.HasPrefix(key, "$") || strings.Contains(key, ".") {
+			return fmt.Errorf("settings key %q at %q is invalid", key, "/"+path)
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if err := validateSettingKeys(nested, path+key+"/"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateIdentifierType validates the identifier type used for user lookups
+// An empty type is allowed and means the type is detected from the identifier
+func ValidateIdentifierType(identifierType string) error {
+	switch identifierType {
+	case "", IdentifierEmail, IdentifierUsername, IdentifierPhone, IdentifierDocumentNumber:
+		return nil
+	}
+	return fmt.Errorf("identifier_type must be one of email, username, phone, document_number")
+}
+
 // SanitizeString removes potentially dangerous characters
 func SanitizeString(input string) string {
 	// Trim whitespace
@@ -154,3 +530,35 @@ func ValidateSearchQuery(query string) error {
 
 	return nil
 }
+
+// Autocomplete limits: suggestions returned by default and at most
+const (
+	DefaultAutocompleteLimit = 8
+	MaxAutocompleteLimit     = 20
+)
+
+// ValidateAutocompleteQuery validates an autocomplete prefix; a single character is enough
+func ValidateAutocompleteQuery(query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return fmt.Errorf("autocomplete query is required")
+	}
+
+	if len(query) > 100 {
+		return fmt.Errorf("autocomplete query is too long (max 100 characters)")
+	}
+
+	return nil
+}
+
+// ValidateAutocompleteLimit returns the number of suggestions to return,
+// defaulting when unset and capped at MaxAutocompleteLimit
+func ValidateAutocompleteLimit(limit int) int {
+	if limit < 1 {
+		return DefaultAutocompleteLimit
+	}
+	if limit > MaxAutocompleteLimit {
+		return MaxAutocompleteLimit
+	}
+	return limit
+}
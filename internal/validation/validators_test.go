@@ -3,6 +3,7 @@ package validation
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidateEmail(t *testing.T) {
@@ -386,6 +387,105 @@ func TestValidateSearchQuery(t *testing.T) {
 	}
 }
 
+func TestValidateAutocompleteQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "single character", query: "a"},
+		{name: "email prefix", query: "an@"},
+		{name: "empty query", query: "  ", wantErr: true},
+		{name: "query too long", query: strings.Repeat("a", 150), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAutocompleteQuery(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAutocompleteQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAutocompleteLimit(t *testing.T) {
+	for limit, want := range map[int]int{0: DefaultAutocompleteLimit, -1: DefaultAutocompleteLimit, 5: 5, 500: MaxAutocompleteLimit} {
+		if got := ValidateAutocompleteLimit(limit); got != want {
+			t.Errorf("ValidateAutocompleteLimit(%d) = %d, want %d", limit, got, want)
+		}
+	}
+}
+
+func TestParseExpiresAt(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "no expiry", value: ""},
+		{name: "future timestamp", value: "2026-03-31T17:00:00+07:00", want: "2026-03-31T10:00:00Z"},
+		{name: "now", value: "2026-03-01T12:00:00Z", wantErr: true},
+		{name: "past timestamp", value: "2025-12-31T00:00:00Z", wantErr: true},
+		{name: "date only", value: "2026-04-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpiresAt(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExpiresAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want == "" {
+				if got != nil && !tt.wantErr {
+					t.Errorf("ParseExpiresAt() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Format(time.RFC3339) != tt.want {
+				t.Errorf("ParseExpiresAt() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateInactivityDays(t *testing.T) {
+	tests := []struct {
+		name        string
+		warnDays    int
+		suspendDays int
+		wantErr     bool
+	}{
+		{name: "disabled"},
+		{name: "warn only", warnDays: 30},
+		{name: "suspend only", suspendDays: 90},
+		{name: "warn before suspend", warnDays: 60, suspendDays: 90},
+		{name: "warn with suspend", warnDays: 90, suspendDays: 90, wantErr: true},
+		{name: "warn after suspend", warnDays: 120, suspendDays: 90, wantErr: true},
+		{name: "negative", warnDays: -1, wantErr: true},
+		{name: "too long", suspendDays: 10000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateInactivityDays(tt.warnDays, tt.suspendDays)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateInactivityDays() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateExportFormat(t *testing.T) {
+	for format, wantErr := range map[string]bool{"json": false, "zip": false, "": true, "csv": true, "JSON": true} {
+		if err := ValidateExportFormat(format); (err != nil) != wantErr {
+			t.Errorf("ValidateExportFormat(%q) error = %v, wantErr %v", format, err, wantErr)
+		}
+	}
+}
+
 func TestSanitizeString(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -461,3 +561,349 @@ func TestSanitizeName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantErr  bool
+	}{
+		{
+			name:     "valid username",
+			username: "john.doe",
+			wantErr:  false,
+		},
+		{
+			name:     "empty username (optional)",
+			username: "",
+			wantErr:  false,
+		},
+		{
+			name:     "too short",
+			username: "jd",
+			wantErr:  true,
+		},
+		{
+			name:     "starts with dot",
+			username: ".john",
+			wantErr:  true,
+		},
+		{
+			name:     "contains space",
+			username: "john doe",
+			wantErr:  true,
+		},
+		{
+			name:     "too long",
+			username: strings.Repeat("a", 65),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUsername(tt.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUsername() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "valid password",
+			password: "s3cret-pass",
+			wantErr:  false,
+		},
+		{
+			name:     "empty password (optional)",
+			password: "",
+			wantErr:  false,
+		},
+		{
+			name:     "too short",
+			password: "short",
+			wantErr:  true,
+		},
+		{
+			name:     "too long for bcrypt",
+			password: strings.Repeat("a", 73),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePassword(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []string
+		wantErr bool
+	}{
+		{
+			name:    "valid roles",
+			roles:   []string{"admin", "user"},
+			wantErr: false,
+		},
+		{
+			name:    "no roles",
+			roles:   nil,
+			wantErr: false,
+		},
+		{
+			name:    "empty role",
+			roles:   []string{""},
+			wantErr: true,
+		},
+		{
+			name:    "role with space",
+			roles:   []string{"super admin"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRoles(tt.roles)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRoles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "lower-cases and de-duplicates",
+			tags: []string{" VIP ", "team:backend", "vip"},
+			want: []string{"vip", "team:backend"},
+		},
+		{
+			name:    "no tags",
+			tags:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "tag with space",
+			tags:    []string{"night shift"},
+			wantErr: true,
+		},
+		{
+			name:    "leading separator",
+			tags:    []string{":backend"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeTags(tt.tags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("NormalizeTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRoleDisplayName(t *testing.T) {
+	tests := []struct {
+		name        string
+		displayName string
+		wantErr     bool
+	}{
+		{
+			name:        "vietnamese name",
+			displayName: "Kế toán",
+			wantErr:     false,
+		},
+		{
+			name:        "english name",
+			displayName: "Warehouse Lead",
+			wantErr:     false,
+		},
+		{
+			name:        "empty name",
+			displayName: "   ",
+			wantErr:     true,
+		},
+		{
+			name:        "markup",
+			displayName: "<script>",
+			wantErr:     true,
+		},
+		{
+			name:        "too long",
+			displayName: strings.Repeat("ệ", 101),
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRoleDisplayName(tt.displayName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRoleDisplayName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAvatarURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		avatarURL string
+		wantErr   bool
+	}{
+		{
+			name:      "https url",
+			avatarURL: "https://cdn.example.com/avatar.png",
+			wantErr:   false,
+		},
+		{
+			name:      "empty url",
+			avatarURL: "",
+			wantErr:   false,
+		},
+		{
+			name:      "javascript url",
+			avatarURL: "javascript:alert(1)",
+			wantErr:   true,
+		},
+		{
+			name:      "relative url",
+			avatarURL: "/avatar.png",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAvatarURL(tt.avatarURL)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAvatarURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLanguage(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{name: "language only", tag: "vi", want: "vi"},
+		{name: "language and region", tag: "vi-vn", want: "vi-VN"},
+		{name: "script subtag", tag: "zh-hant-tw", want: "zh-Hant-TW"},
+		{name: "empty tag", tag: "", wantErr: true},
+		{name: "undetermined", tag: "und", wantErr: true},
+		{name: "malformed", tag: "english please", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateLanguage(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLanguage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ValidateLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTimezone(t *testing.T) {
+	tests := []struct {
+		name    string
+		tz      string
+		wantErr bool
+	}{
+		{name: "utc", tz: "UTC", wantErr: false},
+		{name: "ho chi minh", tz: "Asia/Ho_Chi_Minh", wantErr: false},
+		{name: "empty", tz: "", wantErr: true},
+		{name: "local", tz: "Local", wantErr: true},
+		{name: "unknown zone", tz: "Mars/Olympus", wantErr: true},
+		{name: "offset", tz: "+07:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTimezone(tt.tz)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTimezone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		wantErr  bool
+	}{
+		{name: "nested settings", settings: map[string]interface{}{"notifications": map[string]interface{}{"email": true}}},
+		{name: "operator key", settings: map[string]interface{}{"$set": 1}, wantErr: true},
+		{name: "dotted nested key", settings: map[string]interface{}{"a": map[string]interface{}{"b.c": 1}}, wantErr: true},
+		{name: "too large", settings: map[string]interface{}{"blob": strings.Repeat("x", maxSettingsSize)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSettings(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePreferencePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "field", path: "language", wantErr: false},
+		{name: "settings key", path: "settings.notifications", wantErr: false},
+		{name: "nested settings key", path: "settings.notifications.email", wantErr: false},
+		{name: "whole settings", path: "settings", wantErr: true},
+		{name: "empty segment", path: "settings..email", wantErr: true},
+		{name: "operator segment", path: "settings.$where", wantErr: true},
+		{name: "unknown field", path: "avatar_url", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePreferencePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePreferencePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}